@@ -5,33 +5,50 @@ import (
 	"embed"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/fakovacic/editor/internal/app"
 	"github.com/fakovacic/editor/internal/app/editor"
+	"github.com/fakovacic/editor/internal/app/editor/crdt"
 	ioType "github.com/fakovacic/editor/internal/app/editor/io"
 	ioFile "github.com/fakovacic/editor/internal/app/editor/io/file"
+	ioGit "github.com/fakovacic/editor/internal/app/editor/io/git"
 	ioHttp "github.com/fakovacic/editor/internal/app/editor/io/http"
 	ioMiddleware "github.com/fakovacic/editor/internal/app/editor/io/middleware"
+	ioS3 "github.com/fakovacic/editor/internal/app/editor/io/s3"
+	"github.com/fakovacic/editor/internal/app/editor/journal"
 	editorMiddleware "github.com/fakovacic/editor/internal/app/editor/middleware"
 	"github.com/fakovacic/editor/internal/app/hub"
 	"github.com/fakovacic/editor/internal/app/hub/colors"
 	hubMiddleware "github.com/fakovacic/editor/internal/app/hub/middleware"
 	versioningType "github.com/fakovacic/editor/internal/app/versioning"
 	versioningFile "github.com/fakovacic/editor/internal/app/versioning/file"
+	versioningGit "github.com/fakovacic/editor/internal/app/versioning/git"
 	versioningHTTP "github.com/fakovacic/editor/internal/app/versioning/http"
 	versioningMiddleware "github.com/fakovacic/editor/internal/app/versioning/middleware"
+	versioningS3 "github.com/fakovacic/editor/internal/app/versioning/s3"
 	"github.com/fakovacic/editor/internal/app/web"
 	"github.com/fakovacic/editor/internal/app/web/handler"
 	handlerMiddleware "github.com/fakovacic/editor/internal/app/web/handler/middleware"
 	webMiddleware "github.com/fakovacic/editor/internal/app/web/middleware"
+	"github.com/fakovacic/editor/internal/app/workspace"
+	workspaceMiddleware "github.com/fakovacic/editor/internal/app/workspace/middleware"
 	"github.com/fakovacic/editor/internal/app/write/validator"
 	writeValidatorMiddleware "github.com/fakovacic/editor/internal/app/write/validator/middleware"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/fakovacic/editor/internal/git"
 	"github.com/fakovacic/editor/internal/health"
+	"github.com/fakovacic/editor/internal/httpclient"
 	"github.com/fakovacic/editor/internal/log"
+	"github.com/fakovacic/editor/internal/s3"
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/filesystem"
@@ -39,7 +56,19 @@ import (
 	jsoniter "github.com/json-iterator/go"
 )
 
-const errorChan int = 10
+const (
+	errorChan int = 10
+
+	defaultFilesPollInterval = 5 * time.Second
+	defaultFileWatchInterval = time.Second
+	defaultPruneInterval     = time.Hour
+	defaultShutdownTimeout   = 10 * time.Second
+	defaultJournalCheckpoint = 100
+
+	defaultHTTPTimeout = 30 * time.Second
+	defaultHTTPRetries = 2
+	defaultHTTPBackoff = 200 * time.Millisecond
+)
 
 //go:embed templates/*
 var content embed.FS
@@ -51,8 +80,6 @@ func main() {
 	ctx := context.Background()
 
 	// io
-	var editorIO editor.IO
-
 	filePath := os.Getenv("FILE_PATH")
 	if filePath == "" {
 		log.Fatal(ctx, "FILE_PATH environment variable not set")
@@ -70,18 +97,12 @@ func main() {
 		log.Fatal(ctx, "FILE_IO environment variable not valid")
 	}
 
-	switch ioFileType {
-	case ioType.File:
-		editorIO = ioFile.New(filePath)
-		editorIO = ioMiddleware.NewLogMiddleware(editorIO, ioType.File)
-	case ioType.HTTP:
-		editorIO = ioHttp.New(filePath, http.DefaultClient)
-		editorIO = ioMiddleware.NewLogMiddleware(editorIO, ioType.HTTP)
-	default:
-		log.Fatal(ctx, "FILE_IO environment variable not valid")
-	}
+	// versioning, same contents are saved once and old versions are pruned
+	var (
+		versioning     app.Versioning
+		versionsPolicy versioningType.Versioning
+	)
 
-	// versioning
 	versioningIO := os.Getenv("VERSIONS_IO")
 	if versioningIO != "" {
 		versionPath := os.Getenv("VERSIONS_PATH")
@@ -89,8 +110,6 @@ func main() {
 			log.Fatal(ctx, "VERSIONS_PATH environment variable not set")
 		}
 
-		var versioning app.Versioning
-
 		var versioningFileType versioningType.Type
 
 		err = versioningFileType.Parse(versioningIO)
@@ -103,13 +122,191 @@ func main() {
 			versioning = versioningFile.New(versionPath, time.Now)
 			versioning = versioningMiddleware.NewLogMiddleware(versioning, versioningType.File)
 		case versioningType.HTTP:
-			versioning = versioningHTTP.New(versionPath, http.DefaultClient, time.Now)
+			client, clientErr := newHTTPClient("VERSIONS_HTTP")
+			if clientErr != nil {
+				log.Fatal(ctx, "VERSIONS_HTTP environment variables not valid:", log.Err(clientErr))
+			}
+
+			versioning = versioningHTTP.New(versionPath, client, time.Now)
 			versioning = versioningMiddleware.NewLogMiddleware(versioning, versioningType.HTTP)
+		case versioningType.S3:
+			client, prefix, clientErr := newS3Client(versionPath)
+			if clientErr != nil {
+				log.Fatal(ctx, "VERSIONS_PATH or S3 environment variables not valid:", log.Err(clientErr))
+			}
+
+			versioning = versioningS3.New(client, prefix, time.Now)
+			versioning = versioningMiddleware.NewLogMiddleware(versioning, versioningType.S3)
+		case versioningType.Git:
+			repo, repoErr := openGitRepo(ctx, "VERSIONS_GIT", versionPath)
+			if repoErr != nil {
+				log.Fatal(ctx, "VERSIONS_PATH or VERSIONS_GIT environment variables not valid:", log.Err(repoErr))
+			}
+
+			versioning = versioningGit.New(repo, versionPath, time.Now)
+			versioning = versioningMiddleware.NewLogMiddleware(versioning, versioningType.Git)
 		default:
 			log.Fatal(ctx, "VERSIONS_IO environment variable not set")
 		}
 
-		editorIO = ioMiddleware.NewVersioningMiddleware(editorIO, versioning)
+		retention, retentionErr := newRetention()
+		if retentionErr != nil {
+			log.Fatal(ctx, "VERSIONS_ retention environment variables not valid:", log.Err(retentionErr))
+		}
+
+		if retention.Enabled() && versioningFileType == versioningType.Git {
+			log.Fatal(ctx, "VERSIONS_ retention environment variables not supported for git versions")
+		}
+
+		versionsPolicy = versioningType.NewPolicy(versioning, retention, time.Now)
+		versioning = versionsPolicy
+	}
+
+	// workspace mode when FILE_PATH is directory
+	var workspaceMode bool
+
+	switch ioFileType {
+	case ioType.File, ioType.Git:
+		info, statErr := os.Stat(filePath)
+		if statErr != nil {
+			log.Fatal(ctx, "FILE_PATH environment variable not valid")
+		}
+
+		workspaceMode = info.IsDir()
+	case ioType.HTTP, ioType.S3:
+		workspaceMode = strings.HasSuffix(filePath, "/")
+	}
+
+	// http requests config, FILE_HTTP_HEADERS is comma separated list
+	// of headers as "Authorization: Bearer token"
+	var (
+		httpConfig ioHttp.Config
+		httpClient httpclient.Client
+	)
+
+	if ioFileType == ioType.HTTP {
+		httpClient, err = newHTTPClient("FILE_HTTP")
+		if err != nil {
+			log.Fatal(ctx, "FILE_HTTP environment variables not valid:", log.Err(err))
+		}
+
+		httpMethod := os.Getenv("FILE_HTTP_METHOD")
+		if httpMethod != "" {
+			err = httpConfig.Method.Parse(httpMethod)
+			if err != nil {
+				log.Fatal(ctx, "FILE_HTTP_METHOD environment variable not valid")
+			}
+		}
+
+		httpConfig.Header, err = ioHttp.ParseHeader(splitEnv("FILE_HTTP_HEADERS"))
+		if err != nil {
+			log.Fatal(ctx, "FILE_HTTP_HEADERS environment variable not valid")
+		}
+	}
+
+	// s3 bucket and key of FILE_PATH as s3://bucket/key,
+	// key is prefix of files in workspace mode
+	var (
+		s3Client s3.Client
+		s3Key    string
+	)
+
+	if ioFileType == ioType.S3 {
+		s3Client, s3Key, err = newS3Client(filePath)
+		if err != nil {
+			log.Fatal(ctx, "FILE_PATH or S3 environment variables not valid:", log.Err(err))
+		}
+	}
+
+	// git working tree of FILE_PATH, every write is committed
+	var repo *git.Repo
+
+	if ioFileType == ioType.Git {
+		repoDir := filePath
+
+		if !workspaceMode {
+			repoDir = filepath.Dir(filePath)
+		}
+
+		repo, err = openGitRepo(ctx, "FILE_GIT", repoDir)
+		if err != nil {
+			log.Fatal(ctx, "FILE_PATH or FILE_GIT environment variables not valid:", log.Err(err))
+		}
+	}
+
+	// root, files in workspace dir are sandboxed
+	var root *ioFile.Root
+
+	if workspaceMode && (ioFileType == ioType.File || ioFileType == ioType.Git) {
+		root, err = ioFile.NewRoot(filePath, splitEnv("FILE_INCLUDE"), splitEnv("FILE_EXCLUDE"))
+		if err != nil {
+			log.Fatal(ctx, "FILE_INCLUDE or FILE_EXCLUDE environment variable not valid")
+		}
+	}
+
+	// newIO returns io of file, name is relative to FILE_PATH in workspace mode
+	newIO := func(name string) (editor.IO, error) {
+		var editorIO editor.IO
+
+		switch ioFileType {
+		case ioType.File, ioType.Git:
+			editorIO = ioFile.New(filePath)
+
+			if root != nil {
+				fileIO, fileErr := ioFile.NewFile(root, name)
+				if fileErr != nil {
+					return nil, fileErr
+				}
+
+				editorIO = fileIO
+			}
+
+			if repo != nil {
+				path := filePath
+
+				if root != nil {
+					rootPath, pathErr := root.Path(name)
+					if pathErr != nil {
+						return nil, pathErr
+					}
+
+					path = rootPath
+				}
+
+				editorIO = ioGit.New(repo, editorIO, path)
+			}
+
+			editorIO = ioMiddleware.NewLogMiddleware(editorIO, ioFileType)
+		case ioType.HTTP:
+			fileURL := filePath
+
+			if workspaceMode {
+				urlPath, urlErr := url.JoinPath(filePath, name)
+				if urlErr != nil {
+					return nil, errors.BadRequest("file url: %v", urlErr)
+				}
+
+				fileURL = urlPath
+			}
+
+			editorIO = ioHttp.New(fileURL, httpClient, httpConfig)
+			editorIO = ioMiddleware.NewLogMiddleware(editorIO, ioType.HTTP)
+		case ioType.S3:
+			key := s3Key
+
+			if workspaceMode {
+				key += name
+			}
+
+			editorIO = ioS3.New(s3Client, key)
+			editorIO = ioMiddleware.NewLogMiddleware(editorIO, ioType.S3)
+		}
+
+		if versioning != nil {
+			editorIO = ioMiddleware.NewVersioningMiddleware(editorIO, versioning, name)
+		}
+
+		return editorIO, nil
 	}
 
 	// ttl
@@ -125,21 +322,241 @@ func main() {
 		connTTL = &ttl
 	}
 
+	// open files are saved and connections closed within timeout on shutdown
+	shutdownTimeout := defaultShutdownTimeout
+
+	shutdownTimeoutStr := os.Getenv("SHUTDOWN_TIMEOUT")
+	if shutdownTimeoutStr != "" {
+		timeout, err := time.ParseDuration(shutdownTimeoutStr)
+		if err != nil || timeout <= 0 {
+			log.Fatal(ctx, "SHUTDOWN_TIMEOUT environment variable not valid")
+		}
+
+		shutdownTimeout = timeout
+	}
+
 	// editor
-	editor := editor.New(editorIO)
-	editor = editorMiddleware.NewLogMiddleware(editor)
+	editorMode := app.ModeDelta
+
+	editorModeStr := os.Getenv("EDITOR_MODE")
+	if editorModeStr != "" {
+		err = editorMode.Parse(editorModeStr)
+		if err != nil {
+			log.Fatal(ctx, "EDITOR_MODE environment variable not valid")
+		}
+	}
+
+	// autosave of files edited by clients, as versions by default
+	autosave := app.Autosave{
+		Target: app.AutosaveDraft,
+	}
+
+	autosaveIntervalStr := os.Getenv("AUTOSAVE_INTERVAL")
+	if autosaveIntervalStr != "" {
+		autosave.Interval, err = time.ParseDuration(autosaveIntervalStr)
+		if err != nil || autosave.Interval < 0 {
+			log.Fatal(ctx, "AUTOSAVE_INTERVAL environment variable not valid")
+		}
+	}
+
+	autosaveChangesStr := os.Getenv("AUTOSAVE_CHANGES")
+	if autosaveChangesStr != "" {
+		autosave.Changes, err = strconv.Atoi(autosaveChangesStr)
+		if err != nil || autosave.Changes < 0 {
+			log.Fatal(ctx, "AUTOSAVE_CHANGES environment variable not valid")
+		}
+	}
+
+	autosaveTargetStr := os.Getenv("AUTOSAVE_TARGET")
+	if autosaveTargetStr != "" {
+		err = autosave.Target.Parse(autosaveTargetStr)
+		if err != nil {
+			log.Fatal(ctx, "AUTOSAVE_TARGET environment variable not valid")
+		}
+	}
+
+	if autosave.Enabled() && autosave.Target == app.AutosaveDraft && versioning == nil {
+		log.Fatal(ctx, "AUTOSAVE_TARGET draft needs VERSIONS_IO environment variable set")
+	}
+
+	// approval of save by ready votes of clients editing file
+	approval := app.Approval{
+		Policy: app.ApprovalAll,
+		Owners: splitEnv("SAVE_OWNERS"),
+	}
 
-	// write validator
-	writeValidator := validator.New()
-	writeValidator = writeValidatorMiddleware.NewLogMiddleware(writeValidator)
+	approvalPolicyStr := os.Getenv("SAVE_APPROVAL")
+	if approvalPolicyStr != "" {
+		err = approval.Policy.Parse(approvalPolicyStr)
+		if err != nil {
+			log.Fatal(ctx, "SAVE_APPROVAL environment variable not valid")
+		}
+	}
 
-	// hub
-	colors := colors.New()
-	hb := hub.New(colors)
-	hb = hubMiddleware.NewLogMiddleware(hb)
+	approvalsStr := os.Getenv("SAVE_APPROVALS")
+	if approvalsStr != "" {
+		approval.Approvals, err = strconv.Atoi(approvalsStr)
+		if err != nil || approval.Approvals <= 0 {
+			log.Fatal(ctx, "SAVE_APPROVALS environment variable not valid")
+		}
+	}
+
+	approvalExpireStr := os.Getenv("SAVE_READY_EXPIRE")
+	if approvalExpireStr != "" {
+		approval.Expire, err = time.ParseDuration(approvalExpireStr)
+		if err != nil || approval.Expire < 0 {
+			log.Fatal(ctx, "SAVE_READY_EXPIRE environment variable not valid")
+		}
+	}
+
+	if approval.Policy == app.ApprovalApprovals && approval.Approvals == 0 {
+		log.Fatal(ctx, "SAVE_APPROVAL approvals needs SAVE_APPROVALS environment variable set")
+	}
+
+	if approval.Policy == app.ApprovalOwner && len(approval.Owners) == 0 {
+		log.Fatal(ctx, "SAVE_APPROVAL owner needs SAVE_OWNERS environment variable set")
+	}
+
+	if approval.Policy == app.ApprovalOwner {
+		log.Warn(ctx, "SAVE_APPROVAL owner is advisory, usernames are not authenticated and anyone can log in as owner")
+	}
+
+	approvalPolicy := validator.NewPolicy(approval)
+
+	// journal of changes not written yet, replayed after crash
+	journalPath := os.Getenv("JOURNAL_PATH")
+	journalCheckpoint := defaultJournalCheckpoint
+
+	if journalPath != "" {
+		err = os.MkdirAll(journalPath, 0o700)
+		if err != nil {
+			log.Fatal(ctx, "JOURNAL_PATH environment variable not valid:", log.Err(err))
+		}
+
+		journalCheckpointStr := os.Getenv("JOURNAL_CHECKPOINT")
+		if journalCheckpointStr != "" {
+			journalCheckpoint, err = strconv.Atoi(journalCheckpointStr)
+			if err != nil || journalCheckpoint <= 0 {
+				log.Fatal(ctx, "JOURNAL_CHECKPOINT environment variable not valid")
+			}
+		}
+	}
+
+	// workspace, every open file has own editor, hub and write validator
+	var defaultFile string
+
+	if !workspaceMode {
+		defaultFile = path.Base(filePath)
+	}
+
+	factory := func(name string) (*app.Session, error) {
+		var fileType app.FileType
+
+		parseErr := fileType.Parse(path.Ext(name))
+		if parseErr != nil {
+			return nil, errors.Wrap(parseErr, "file type")
+		}
+
+		if !workspaceMode && name != defaultFile {
+			return nil, errors.NotFound("file '%s' not found", name)
+		}
+
+		fileIO, ioErr := newIO(name)
+		if ioErr != nil {
+			return nil, errors.Wrap(ioErr, "file io")
+		}
+
+		var (
+			fileEditor app.Editor
+			replay     journal.Replay
+		)
+
+		switch editorMode {
+		case app.ModeDelta:
+			fileEditor = editor.New(fileIO)
+			replay = editor.Replay
+		case app.ModeCRDT:
+			fileEditor = crdt.New(fileIO)
+			replay = crdt.Replay
+		}
+
+		if journalPath != "" {
+			fileJournal := journal.NewFile(filepath.Join(journalPath, journal.Name(name)))
+			fileEditor = journal.NewEditor(fileEditor, fileJournal, replay, journalCheckpoint)
+		}
+
+		fileEditor = editorMiddleware.NewLogMiddleware(fileEditor)
+
+		// write validator
+		writeValidator := validator.New(approvalPolicy, approval.Expire, time.Now)
+		writeValidator = writeValidatorMiddleware.NewLogMiddleware(writeValidator)
+
+		// hub
+		hb := hub.New(colors.New(), fileEditor)
+		hb = hubMiddleware.NewLogMiddleware(hb)
+
+		return &app.Session{
+			Editor:         fileEditor,
+			Hub:            hb,
+			WriteValidator: writeValidator,
+		}, nil
+	}
+
+	// dir, files are listed and managed only in workspace mode
+	var dir editor.Dir
+
+	if workspaceMode {
+		switch ioFileType {
+		case ioType.File:
+			dir = ioFile.NewDir(root)
+			dir = ioMiddleware.NewDirLogMiddleware(dir, ioType.File)
+		case ioType.Git:
+			dir = ioGit.NewDir(repo, ioFile.NewDir(root), root.Path)
+			dir = ioMiddleware.NewDirLogMiddleware(dir, ioType.Git)
+		case ioType.HTTP:
+			dir = ioHttp.NewDir(filePath, httpClient, httpConfig)
+			dir = ioMiddleware.NewDirLogMiddleware(dir, ioType.HTTP)
+		case ioType.S3:
+			dir = ioS3.NewDir(s3Client, s3Key)
+			dir = ioMiddleware.NewDirLogMiddleware(dir, ioType.S3)
+		}
+	}
+
+	filesPollInterval := defaultFilesPollInterval
+
+	filesPollIntervalStr := os.Getenv("FILES_POLL_INTERVAL")
+	if filesPollIntervalStr != "" {
+		filesPollInterval, err = time.ParseDuration(filesPollIntervalStr)
+		if err != nil || filesPollInterval <= 0 {
+			log.Fatal(ctx, "FILES_POLL_INTERVAL environment variable not valid")
+		}
+	}
+
+	// open files are watched by default only on file system,
+	// for http every interval is request for each open file
+	var fileWatchInterval time.Duration
+
+	if ioFileType == ioType.File || ioFileType == ioType.Git {
+		fileWatchInterval = defaultFileWatchInterval
+	}
+
+	fileWatchIntervalStr := os.Getenv("FILE_WATCH_INTERVAL")
+	if fileWatchIntervalStr != "" {
+		fileWatchInterval, err = time.ParseDuration(fileWatchIntervalStr)
+		if err != nil || fileWatchInterval < 0 {
+			log.Fatal(ctx, "FILE_WATCH_INTERVAL environment variable not valid")
+		}
+	}
+
+	fileWorkspace := workspace.New(factory, dir, defaultFile)
+	fileWorkspace = workspaceMiddleware.NewLogMiddleware(fileWorkspace)
+
+	// users
+	users := hub.New(colors.New(), nil)
+	users = hubMiddleware.NewLogMiddleware(users)
 
 	// web service
-	service := web.New(editor, hb, writeValidator, connTTL)
+	service := web.New(users, fileWorkspace, versioning, connTTL)
 	service = webMiddleware.NewLogMiddleware(service)
 
 	// handler
@@ -148,7 +565,9 @@ func main() {
 	engine := html.NewFileSystem(http.FS(content), ".html")
 	json := jsoniter.ConfigCompatibleWithStandardLibrary
 
+	// values from request are kept after handler returns, as usernames
 	app := fiber.New(fiber.Config{
+		Immutable:   true,
 		Views:       engine,
 		JSONEncoder: json.Marshal,
 		JSONDecoder: json.Unmarshal,
@@ -160,6 +579,11 @@ func main() {
 	app.Get("/login", h.LoginForm())
 	app.Post("/login", h.Login())
 	app.Get("/", h.Index())
+	app.Get("/api/files", h.Files())
+	app.Get("/api/versions", h.Versions())
+	app.Get("/api/versions/:version", h.Version())
+	app.Get("/api/versions/:version/diff", h.VersionDiff())
+	app.Post("/api/versions/:version/restore", h.RestoreVersion())
 
 	// app.Static("/", "./static")
 
@@ -191,6 +615,40 @@ func main() {
 
 	errChan := make(chan error, errorChan)
 
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	defer stopWatch()
+
+	if workspaceMode {
+		go service.WatchFiles(watchCtx, filesPollInterval)
+	}
+
+	if fileWatchInterval > 0 {
+		go service.WatchContents(watchCtx, fileWatchInterval)
+	}
+
+	if versionsPolicy != nil {
+		// versions of files saved before start are pruned too
+		files, filesErr := fileWorkspace.Files(ctx)
+		if filesErr != nil {
+			log.Error(ctx, "workspace files:", log.Err(filesErr))
+		}
+
+		filenames := make([]string, 0, len(files))
+		for _, file := range files {
+			filenames = append(filenames, file.Name)
+		}
+
+		go versionsPolicy.Prune(watchCtx, filenames)
+	}
+
+	if autosave.Enabled() {
+		go service.Autosave(watchCtx, autosave)
+	}
+
+	if approval.Expire > 0 {
+		go service.ExpireVotes(watchCtx)
+	}
+
 	go func() {
 		log.Info(ctx, fmt.Sprintf("Health service listening on %s", healthAddr))
 		errChan <- healthServer.Listen(healthAddr)
@@ -212,10 +670,26 @@ func main() {
 
 			return
 		case s := <-signalChan:
-			log.Info(ctx, "Captured %v. Exiting...", s)
+			log.Info(ctx, fmt.Sprintf("Captured %v. Exiting...", s))
 			health.SetHealthStatus(http.StatusServiceUnavailable)
 
-			err := app.Shutdown()
+			// watchers stop before files are saved, so no reload
+			// or prune runs while open files are written
+			stopWatch()
+
+			shutdownCtx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+
+			// new connections are rejected, clients are told and
+			// open files are written through versioning
+			err := service.Shutdown(shutdownCtx)
+			if err != nil {
+				log.Error(ctx, "shutdown service:", log.Err(err))
+			}
+
+			err = app.ShutdownWithContext(shutdownCtx)
+
+			cancel()
+
 			if err != nil {
 				log.Fatal(ctx, err.Error())
 			}
@@ -224,3 +698,146 @@ func main() {
 		}
 	}
 }
+
+// splitEnv returns comma separated values of environment variable
+func splitEnv(key string) []string {
+	values := make([]string, 0)
+
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// newHTTPClient returns client configured by environment variables with
+// prefix as FILE_HTTP_TOKEN
+func newHTTPClient(prefix string) (httpclient.Client, error) {
+	config := httpclient.Config{
+		Token:    os.Getenv(prefix + "_TOKEN"),
+		Username: os.Getenv(prefix + "_USERNAME"),
+		Password: os.Getenv(prefix + "_PASSWORD"),
+		CertFile: os.Getenv(prefix + "_CERT"),
+		KeyFile:  os.Getenv(prefix + "_KEY"),
+		CAFile:   os.Getenv(prefix + "_CA"),
+		Timeout:  defaultHTTPTimeout,
+		Retries:  defaultHTTPRetries,
+		Backoff:  defaultHTTPBackoff,
+	}
+
+	timeout := os.Getenv(prefix + "_TIMEOUT")
+	if timeout != "" {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil || duration < 0 {
+			return nil, errors.BadRequest("%s_TIMEOUT not valid", prefix)
+		}
+
+		config.Timeout = duration
+	}
+
+	retries := os.Getenv(prefix + "_RETRIES")
+	if retries != "" {
+		count, err := strconv.Atoi(retries)
+		if err != nil || count < 0 {
+			return nil, errors.BadRequest("%s_RETRIES not valid", prefix)
+		}
+
+		config.Retries = count
+	}
+
+	return httpclient.New(config)
+}
+
+// newRetention returns retention of versions set by VERSIONS_ environment
+// variables, versions are pruned every hour unless set otherwise
+func newRetention() (versioningType.Retention, error) {
+	retention := versioningType.Retention{
+		Interval: defaultPruneInterval,
+	}
+
+	last := os.Getenv("VERSIONS_KEEP_LAST")
+	if last != "" {
+		count, err := strconv.Atoi(last)
+		if err != nil || count < 0 {
+			return retention, errors.BadRequest("VERSIONS_KEEP_LAST not valid")
+		}
+
+		retention.Last = count
+	}
+
+	durations := []struct {
+		name  string
+		value *time.Duration
+	}{
+		{"VERSIONS_KEEP_EVERY", &retention.Every},
+		{"VERSIONS_MAX_AGE", &retention.MaxAge},
+		{"VERSIONS_PRUNE_INTERVAL", &retention.Interval},
+	}
+
+	for _, d := range durations {
+		value := os.Getenv(d.name)
+		if value == "" {
+			continue
+		}
+
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration < 0 {
+			return retention, errors.BadRequest("%s not valid", d.name)
+		}
+
+		*d.value = duration
+	}
+
+	maxSize := os.Getenv("VERSIONS_MAX_SIZE")
+	if maxSize != "" {
+		size, err := strconv.ParseInt(maxSize, 10, 64)
+		if err != nil || size < 0 {
+			return retention, errors.BadRequest("VERSIONS_MAX_SIZE not valid")
+		}
+
+		retention.MaxSize = size
+	}
+
+	return retention, nil
+}
+
+// newS3Client returns client of bucket and key of url as s3://bucket/key,
+// endpoint and credentials are set by S3_ environment variables
+func newS3Client(rawURL string) (s3.Client, string, error) {
+	bucket, key, err := s3.ParseURL(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	client, err := newHTTPClient("S3")
+	if err != nil {
+		return nil, "", err
+	}
+
+	s3Client, err := s3.New(s3.Config{
+		Endpoint:     os.Getenv("S3_ENDPOINT"),
+		Region:       os.Getenv("S3_REGION"),
+		Bucket:       bucket,
+		AccessKey:    os.Getenv("S3_ACCESS_KEY"),
+		SecretKey:    os.Getenv("S3_SECRET_KEY"),
+		SessionToken: os.Getenv("S3_SESSION_TOKEN"),
+	}, client, time.Now)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return s3Client, key, nil
+}
+
+// openGitRepo returns repository of working tree dir is in, configured
+// by environment variables with prefix as FILE_GIT_REMOTE
+func openGitRepo(ctx context.Context, prefix, dir string) (*git.Repo, error) {
+	return git.Open(ctx, dir, git.Config{
+		Remote:    os.Getenv(prefix + "_REMOTE"),
+		Committer: os.Getenv(prefix + "_COMMITTER"),
+		Domain:    os.Getenv(prefix + "_EMAIL_DOMAIN"),
+	})
+}
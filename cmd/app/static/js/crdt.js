@@ -0,0 +1,286 @@
+// Replica mirrors crdt.Document on server, keep them in sync.
+//
+// Every char has unique id and is inserted after its origin, removed chars
+// are kept as tombstones so ops can be merged in any order they arrive.
+// Ops not acknowledged by server are kept and sent again after reconnect.
+function Replica(session, send) {
+    this.session = session;
+    this.send = send;
+
+    this.site = Math.random().toString(36).slice(2, 10);
+    this.clock = 0;
+    this.elements = [];
+    this.index = {};
+    this.removed = {};
+    this.pending = [];
+    this.applying = false;
+}
+
+function idKey(id) {
+    return id.site + ":" + id.clock;
+}
+
+// isAfter reports if id a is ordered before b when both
+// are inserted after same origin, higher clock wins, site breaks ties
+function isAfter(a, b) {
+    if (a.clock != b.clock) {
+        return a.clock > b.clock;
+    }
+
+    return a.site > b.site;
+}
+
+// load replaces replica with server state, ops made while offline
+// are merged into it and sent again
+Replica.prototype.load = function (runs) {
+    this.elements = [];
+    this.index = {};
+    this.removed = {};
+
+    for (var i = 0; i < runs.length; i++) {
+        var chars = Array.from(runs[i].value);
+
+        for (var j = 0; j < chars.length; j++) {
+            var el = {
+                id: { site: runs[i].site, clock: runs[i].clock + j },
+                value: chars[j],
+                deleted: runs[i].deleted === true,
+            };
+
+            this.elements.push(el);
+            this.index[idKey(el.id)] = el;
+            this.clock = Math.max(this.clock, el.id.clock);
+        }
+    }
+
+    // ops based on contents server does not have anymore are dropped
+    var pending = [];
+
+    for (var k = 0; k < this.pending.length; k++) {
+        var op = this.pending[k];
+
+        if (op.action == "insert" && op.origin && !this.index[idKey(op.origin)]) {
+            continue;
+        }
+
+        this.integrate(op);
+        pending.push(op);
+    }
+
+    this.pending = pending;
+
+    var cursor = this.session.selection.getCursor();
+
+    this.applying = true;
+    this.session.doc.setValue(this.text());
+    this.applying = false;
+
+    this.session.selection.moveTo(cursor.row, cursor.column);
+
+    if (this.pending.length > 0) {
+        this.send(this.pending);
+    }
+};
+
+Replica.prototype.text = function () {
+    var text = "";
+
+    for (var i = 0; i < this.elements.length; i++) {
+        if (!this.elements[i].deleted) {
+            text += this.elements[i].value;
+        }
+    }
+
+    return text;
+};
+
+// local converts Ace delta to ops, offsets are counted in UTF-16 units as in Ace
+Replica.prototype.local = function (delta) {
+    if (this.applying) {
+        return;
+    }
+
+    var doc = this.session.doc;
+    var offset = doc.positionToIndex(delta.start);
+    var text = delta.lines.join(doc.getNewLineCharacter());
+    var ops = [];
+
+    switch (delta.action) {
+        case "insert":
+            var origin = this.originAt(offset);
+            var chars = Array.from(text);
+
+            for (var i = 0; i < chars.length; i++) {
+                this.clock++;
+
+                var op = {
+                    action: "insert",
+                    id: { site: this.site, clock: this.clock },
+                    origin: origin,
+                    value: chars[i],
+                };
+
+                this.integrate(op);
+                ops.push(op);
+
+                origin = op.id;
+            }
+            break;
+        case "remove":
+            var count = 0;
+
+            for (var j = 0; j < this.elements.length; j++) {
+                var el = this.elements[j];
+
+                if (el.deleted) {
+                    continue;
+                }
+
+                if (count >= offset + text.length) {
+                    break;
+                }
+
+                if (count >= offset) {
+                    el.deleted = true;
+                    ops.push({ action: "remove", id: el.id });
+                }
+
+                count += el.value.length;
+            }
+            break;
+    }
+
+    if (ops.length == 0) {
+        return;
+    }
+
+    this.pending = this.pending.concat(ops);
+    this.send(ops);
+};
+
+// originAt returns id of last visible char before offset
+Replica.prototype.originAt = function (offset) {
+    var count = 0;
+    var origin = null;
+
+    for (var i = 0; i < this.elements.length && count < offset; i++) {
+        if (this.elements[i].deleted) {
+            continue;
+        }
+
+        count += this.elements[i].value.length;
+        origin = this.elements[i].id;
+    }
+
+    return origin;
+};
+
+Replica.prototype.offsetOf = function (el) {
+    var count = 0;
+
+    for (var i = 0; i < this.elements.length && this.elements[i] !== el; i++) {
+        if (!this.elements[i].deleted) {
+            count += this.elements[i].value.length;
+        }
+    }
+
+    return count;
+};
+
+// integrate merges op into replica, returns changed element or null
+Replica.prototype.integrate = function (op) {
+    var key = idKey(op.id);
+    var el = this.index[key];
+
+    if (op.action == "remove") {
+        if (!el) {
+            this.removed[key] = true;
+
+            return null;
+        }
+
+        if (el.deleted) {
+            return null;
+        }
+
+        el.deleted = true;
+
+        return el;
+    }
+
+    if (el) {
+        return null;
+    }
+
+    var i = 0;
+
+    if (op.origin) {
+        var origin = this.index[idKey(op.origin)];
+        if (!origin) {
+            return null;
+        }
+
+        i = this.elements.indexOf(origin) + 1;
+    }
+
+    // concurrent inserts after same origin are ordered by id
+    while (i < this.elements.length && isAfter(this.elements[i].id, op.id)) {
+        i++;
+    }
+
+    el = {
+        id: op.id,
+        value: op.value,
+        deleted: this.removed[key] === true,
+    };
+
+    delete this.removed[key];
+
+    this.elements.splice(i, 0, el);
+    this.index[key] = el;
+    this.clock = Math.max(this.clock, op.id.clock);
+
+    return el;
+};
+
+Replica.prototype.ack = function (ops) {
+    var acked = {};
+
+    for (var i = 0; i < ops.length; i++) {
+        acked[ops[i].action + idKey(ops[i].id)] = true;
+    }
+
+    this.pending = this.pending.filter(function (op) {
+        return !acked[op.action + idKey(op.id)];
+    });
+};
+
+Replica.prototype.remote = function (ops) {
+    var doc = this.session.doc;
+
+    this.applying = true;
+
+    for (var i = 0; i < ops.length; i++) {
+        var op = ops[i];
+        var el = this.integrate(op);
+
+        if (el === null) {
+            continue;
+        }
+
+        var offset = this.offsetOf(el);
+
+        switch (op.action) {
+            case "insert":
+                if (!el.deleted) {
+                    doc.insert(doc.indexToPosition(offset), el.value);
+                }
+                break;
+            case "remove":
+                doc.remove(Range.fromPoints(doc.indexToPosition(offset), doc.indexToPosition(offset + el.value.length)));
+                break;
+        }
+    }
+
+    this.applying = false;
+};
@@ -1,17 +1,34 @@
 window.onload = function () {
     const urlParams = new URLSearchParams(window.location.search);
-    const id = urlParams.get('id');
+    var id = urlParams.get('id');
+    var file = urlParams.get('file') || "";
 
     var conn;
     var contentReady = false;
     var readyState = false;
     var editorChange = false;
+    var closing = false;
+    var shuttingDown = false;
+    var mode = "delta";
+    var username = "";
 
     var clientContainer = document.getElementById("clients");
     var btnSave = document.getElementById("save");
     var btnReady = document.getElementById("ready");
     var btnUnready = document.getElementById("unready");
     var btnDisconnect = document.getElementById("disconnect");
+    var formOpenFile = document.getElementById("open-file");
+    var btnCreateFile = document.getElementById("create-file");
+    var inputFileName = document.getElementById("file-name");
+    var fileContainer = document.getElementById("files");
+    var files = [];
+    var btnVersions = document.getElementById("versions");
+    var versionContainer = document.getElementById("versions-list");
+    var versionView = document.getElementById("version-view");
+    var saveState = document.getElementById("save-state");
+    var versions = [];
+
+    inputFileName.value = file;
 
     var editor = ace.edit("editor");
     editor.setTheme("ace/theme/GitHub");
@@ -34,7 +51,9 @@ window.onload = function () {
         var disconnect = {
             "type": "conn-disconnect",
         };
-        
+
+        closing = true;
+
         conn.send(JSON.stringify(disconnect));
     });
 
@@ -75,6 +94,164 @@ window.onload = function () {
     });
 
 
+    btnVersions.addEventListener("click", () => {
+        versionMsg("conn-versions");
+    });
+
+    var versionActions = {
+        view: function (v) {
+            versionMsg("conn-version", v.id);
+        },
+        diff: function (v) {
+            versionMsg("conn-version-diff", v.id, versions[0].id);
+        },
+        restore: function (v) {
+            if (!confirm("Restore version of " + new Date(v.time).toLocaleString() + "?")) {
+                return;
+            }
+
+            versionMsg("conn-version-restore", v.id);
+        },
+    };
+
+    // versions are of open file, replies are sent only to this client
+    function versionMsg(type, versionID, to) {
+        var msg = {
+            "type": type,
+            "data": {
+                "id": versionID || "",
+                "to": to
+            }
+        };
+
+        conn.send(JSON.stringify(msg));
+    }
+
+    var doc = new Document(editor.session, function (change) {
+        var msg = {
+            "type": "conn-text-change",
+            "data": change
+        };
+
+        conn.send(JSON.stringify(msg));
+    });
+
+    // ops made while offline stay pending and are sent after reconnect
+    function newReplica() {
+        return new Replica(editor.session, function (ops) {
+            if (conn.readyState != WebSocket.OPEN) {
+                return;
+            }
+
+            var msg = {
+                "type": "conn-text-change",
+                "data": {
+                    "ops": ops
+                }
+            };
+
+            conn.send(JSON.stringify(msg));
+        });
+    }
+
+    var replica = newReplica();
+
+    formOpenFile.addEventListener("submit", (e) => {
+        e.preventDefault();
+
+        openFile(inputFileName.value);
+    });
+
+    btnCreateFile.addEventListener("click", () => {
+        if (inputFileName.value == "") {
+            return;
+        }
+
+        fileOp("conn-create-file", inputFileName.value);
+    });
+
+    var fileActions = {
+        open: openFile,
+        rename: function (name) {
+            var newName = prompt("Rename " + name + " to", name);
+            if (newName === null || newName == "" || newName == name) {
+                return;
+            }
+
+            fileOp("conn-rename-file", name, newName);
+        },
+        delete: function (name) {
+            if (!confirm("Delete " + name + "?")) {
+                return;
+            }
+
+            fileOp("conn-delete-file", name);
+        },
+    };
+
+    // file open by other clients is not renamed or deleted, server
+    // moves client to created or renamed file
+    function fileOp(type, name, newName) {
+        if (type == "conn-create-file" || name == file) {
+            contentReady = false;
+            replica = newReplica();
+        }
+
+        var msg = {
+            "type": type,
+            "data": {
+                "name": name,
+                "newName": newName
+            }
+        };
+
+        conn.send(JSON.stringify(msg));
+    }
+
+    function openFile(name) {
+        file = name;
+        inputFileName.value = name;
+        replica = newReplica();
+
+        contentReady = false;
+        readyState = false;
+        editor.setReadOnly(true);
+
+        var open = {
+            "type": "conn-open-file",
+            "data": {
+                "name": file
+            }
+        };
+
+        conn.send(JSON.stringify(open));
+    }
+
+    function loadFiles() {
+        fetch("/api/files?id=" + id).then(function (res) {
+            if (!res.ok) {
+                return [];
+            }
+
+            return res.json();
+        }).then(function (list) {
+            files = list;
+            refreshFiles(fileContainer, files, file, fileActions);
+        }).catch(function () {
+            showAlert("Files not loaded!", "danger");
+        });
+    }
+
+    function pageURL() {
+        var url = "/?id=" + id;
+
+        if (file != "") {
+            url += "&file=" + encodeURIComponent(file);
+        }
+
+        return url;
+    }
+
     editor.on('change', function(delta) {
         if (contentReady == false){
             return;
@@ -84,21 +261,66 @@ window.onload = function () {
             return;
         }
 
-        var change = {
-            "type": "conn-text-change",
-            "data": delta
-        };
+        if (mode == "crdt") {
+            replica.local(delta);
 
-        conn.send(JSON.stringify(change));
+            return;
+        }
+
+        doc.local(delta);
     });
 
-    if (window["WebSocket"]) {
-        conn = new WebSocket("ws://" + document.location.host + "/ws?id=" + id);
+    // login again with same username, server removes client on disconnect
+    function reconnect() {
+        setTimeout(function () {
+            fetch("/login", {
+                method: "POST",
+                body: new URLSearchParams({ "username": username }),
+            }).then(function (res) {
+                var newID = new URL(res.url).searchParams.get("id");
+                if (newID === null) {
+                    reconnect();
+
+                    return;
+                }
+
+                id = newID;
+                window.history.replaceState(null, "", pageURL());
+
+                connect();
+            }).catch(reconnect);
+        }, 1000);
+    }
+
+    function connect() {
+        conn = new WebSocket("ws://" + document.location.host + "/ws?id=" + id + "&file=" + encodeURIComponent(file));
+
+        conn.onopen = function () {
+            loadFiles();
+        };
 
         conn.onclose = function () {
-            editor.setReadOnly(true);
             clientContainer.innerHTML = "";
-            
+
+            // server saved open files before closing connection
+            if (shuttingDown) {
+                editor.setReadOnly(true);
+
+                showAlert("Server shut down, reload page when it is back", "warning");
+
+                return;
+            }
+
+            if (mode == "crdt" && !closing) {
+                showAlert("Connection lost, reconnecting", "warning");
+
+                reconnect();
+
+                return;
+            }
+
+            editor.setReadOnly(true);
+
             location.reload();
         };
 
@@ -109,13 +331,66 @@ window.onload = function () {
 
             switch (update.type) {
                 case "conn-connected":
-                    editor.setReadOnly(true);
-                    editor.setValue(update.data);
+                    mode = update.fileMeta.mode;
+                    username = update.client;
+                    file = update.fileMeta.path;
+                    inputFileName.value = file;
+
+                    window.history.replaceState(null, "", pageURL());
+                    hideUnreadyButton(btnUnready, editor);
+                    refreshFiles(fileContainer, files, file, fileActions);
+
+                    // versions are of previous file
+                    versions = [];
+                    refreshVersions(versionContainer, versions, versionActions);
+                    versionView.style.display = "none";
 
                     editor.session.setMode("ace/mode/" + update.fileMeta.extension);
+                    setFormat(editor, update.fileMeta.format);
+
+                    // in crdt mode contents are set from state
+                    if (mode == "crdt") {
+                        break;
+                    }
+
+                    editor.setReadOnly(true);
+
+                    editorChange = true;
+                    editor.setValue(update.data);
+                    editorChange = false;
 
                     editor.setReadOnly(false);
                     contentReady = true;
+
+                    doc.reset(update.fileMeta.revision);
+                    break;
+                case "server-resync":
+                    showAlert("Change rejected, contents reloaded", "warning");
+
+                    // rejected ops are not sent again, state follows
+                    if (mode == "crdt") {
+                        replica.pending = [];
+
+                        break;
+                    }
+
+                    var cursor = editor.getCursorPosition();
+
+                    editorChange = true;
+                    editor.setValue(update.data, -1);
+                    editorChange = false;
+
+                    editor.moveCursorToPosition(cursor);
+
+                    doc.reset(update.fileMeta.revision);
+                    break;
+                case "server-crdt-state":
+                    clearAlerts();
+
+                    replica.load(JSON.parse(update.data));
+
+                    editor.setReadOnly(readyState);
+                    contentReady = true;
                     break;
                 case "conn-disconnected":
                     console.log("connection closed");
@@ -123,9 +398,24 @@ window.onload = function () {
                 case "clients-text-change":
                     var updateContents = JSON.parse(update.data);
 
-                    editorChange = true;
-                    editor.session.doc.applyDelta(updateContents.data);
-                    editorChange = false;
+                    if (mode == "crdt") {
+                        replica.remote(updateContents.data.ops);
+
+                        break;
+                    }
+
+                    doc.remote(updateContents.data);
+                    break;
+                case "server-text-change-ack":
+                    var ackContents = JSON.parse(update.data);
+
+                    if (mode == "crdt") {
+                        replica.ack(ackContents.data.ops);
+
+                        break;
+                    }
+
+                    doc.ack(ackContents.data);
                     break;
                 case "clients-connected":
                     showAlert(update.client + " connected", "success");
@@ -133,6 +423,15 @@ window.onload = function () {
                 case "clients-disconnected":
                     showAlert(update.client + " disconnected", "warning");
                     break;
+                case "clients-unready":
+                    // ready vote of client expired on server
+                    if (update.client == username && readyState) {
+                        readyState = false;
+
+                        hideUnreadyButton(btnUnready, editor);
+                        showAlert("Ready vote expired, vote again when ready", "warning");
+                    }
+                    break;
                 case "server-file-saved":
                     clearAlerts();
 
@@ -141,11 +440,100 @@ window.onload = function () {
 
                     hideUnreadyButton(btnUnready, editor);
                     break;
+                case "server-file-autosaved":
+                    // sent to all clients of file, votes are kept
+                    showAlert(update.data == "file" ? "File autosaved" : "Draft autosaved as version", "info");
+                    break;
+                case "server-file-merged":
+                    clearAlerts();
+
+                    // source changes arrive as text changes before this
+                    showAlert("File changed on source, changes merged and saved!", "success");
+                    readyState = false;
+
+                    hideUnreadyButton(btnUnready, editor);
+                    break;
+                case "server-file-reloaded":
+                    // source changes arrive as text changes before this
+                    showAlert("File changed on source, changes merged", "info");
+                    break;
+                case "server-file-conflict":
+                    showAlert("File can not be saved, " + update.data, "danger");
+                    break;
                 case "server-file-not-ready":
                     showAlert("File not ready!", "danger");
                     break;
                 case "server-file-not-saved":
-                    showAlert("File not saved!", "danger");
+                    showAlert(update.data ? "File not saved: " + update.data : "File not saved!", "danger");
+                    break;
+                case "server-file-not-opened":
+                    // sent from outside of file session, clients are not of file
+                    contentReady = false;
+                    editor.setReadOnly(true);
+
+                    if (update.data) {
+                        showAlert("File " + update.data + " not opened!", "danger");
+                    } else {
+                        showAlert("Open file to start editing", "warning");
+                    }
+
+                    // file deleted or not chosen yet
+                    if (!update.data && file != "") {
+                        file = "";
+                        inputFileName.value = "";
+                        window.history.replaceState(null, "", pageURL());
+
+                        editorChange = true;
+                        editor.setValue("");
+                        editorChange = false;
+
+                        clientContainer.innerHTML = "";
+                        refreshFiles(fileContainer, files, file, fileActions);
+                    }
+
+                    return;
+                case "server-file-not-created":
+                    showAlert("File not created: " + update.data, "danger");
+                    return;
+                case "server-file-not-renamed":
+                    showAlert("File not renamed: " + update.data, "danger");
+                    return;
+                case "server-file-not-deleted":
+                    showAlert("File not deleted: " + update.data, "danger");
+                    return;
+                case "server-shutdown":
+                    // sent to all logged in clients, open file is saved next
+                    shuttingDown = true;
+                    editor.setReadOnly(true);
+
+                    showAlert("Server shutting down, saving file", "warning");
+
+                    return;
+                case "server-files":
+                    // sent to all logged in clients, clients are not of file
+                    files = JSON.parse(update.data);
+                    refreshFiles(fileContainer, files, file, fileActions);
+
+                    return;
+                case "server-versions":
+                    versions = JSON.parse(update.data);
+                    refreshVersions(versionContainer, versions, versionActions);
+                    break;
+                case "server-version":
+                case "server-version-diff":
+                    versionView.innerText = update.data || "No changes";
+                    versionView.style.display = "block";
+                    break;
+                case "server-version-restored":
+                    // restored contents arrive as text changes before this
+                    showAlert(update.client + " restored version", "info");
+
+                    if (versions.length > 0) {
+                        versionMsg("conn-versions");
+                    }
+                    break;
+                case "server-version-failed":
+                    showAlert("Version: " + update.data, "danger");
                     break;
                 case "conn-not-ready":
                     showAlert("Connection not ready!", "danger");
@@ -156,12 +544,28 @@ window.onload = function () {
             }
 
             refreshClients(clientContainer, btnSave, btnReady, btnUnready, readyState, update.clients);
+
+            // changes and saves of file tell if it has unsaved changes
+            if (update.saved) {
+                refreshSaveState(saveState, update.saved);
+            }
         };
+    }
+
+    if (window["WebSocket"]) {
+        connect();
     } else {
         console.log("Your browser does not support WebSockets", "red");
     }
 };
 
+// contents are sent with LF line endings, server writes file in its format
+function setFormat(editor, format) {
+    editor.session.setNewLineMode("unix");
+    editor.session.setUseSoftTabs(!format.indentTabs);
+    editor.session.setTabSize(format.indentSize);
+}
+
 function refreshClients(clientContainer, btnSave, btnReady, btnUnready, readyState, clients) {
     clientContainer.innerHTML = "";
 
@@ -195,6 +599,109 @@ function refreshClients(clientContainer, btnSave, btnReady, btnUnready, readySta
     });
 }
 
+// files are listed with rename and delete actions, current file is active
+function refreshFiles(fileContainer, files, current, actions) {
+    fileContainer.innerHTML = "";
+
+    files.forEach(function (f) {
+        var item = document.createElement("div");
+
+        item.className = "list-group-item list-group-item-action d-flex align-items-center py-1";
+        item.title = f.size + " bytes, modified " + new Date(f.modTime).toLocaleString();
+
+        if (f.name == current) {
+            item.className += " active";
+        }
+
+        var name = document.createElement("span");
+
+        name.innerText = f.name;
+        name.className = "flex-grow-1 text-truncate";
+        name.style.cursor = "pointer";
+        name.addEventListener("click", function () {
+            actions.open(f.name);
+        });
+
+        item.appendChild(name);
+
+        [["rename", "bi-pencil"], ["delete", "bi-trash"]].forEach(function (action) {
+            var btn = document.createElement("button");
+
+            btn.type = "button";
+            btn.title = action[0];
+            btn.className = "btn btn-sm btn-link p-0 ms-1 text-reset";
+            btn.innerHTML = '<i class="bi ' + action[1] + '"></i>';
+            btn.addEventListener("click", function () {
+                actions[action[0]](f.name);
+            });
+
+            item.appendChild(btn);
+        });
+
+        fileContainer.appendChild(item);
+    });
+}
+
+// versions are listed newest first with view, diff to newest and restore actions,
+// who saved version and why is shown on hover
+function refreshVersions(versionContainer, versions, actions) {
+    versionContainer.innerHTML = "";
+
+    versions.forEach(function (v, i) {
+        var item = document.createElement("div");
+
+        item.className = "list-group-item d-flex align-items-center py-1";
+        item.title = [
+            v.size + " bytes",
+            v.authors ? "by " + v.authors.join(", ") : "",
+            v.ready ? "ready " + v.ready.join(", ") : "",
+            v.message || "",
+        ].filter(Boolean).join("\n");
+
+        var name = document.createElement("span");
+
+        name.innerText = new Date(v.time).toLocaleString() + (v.trigger ? " (" + v.trigger + ")" : "") + (v.draft ? " draft" : "");
+        name.className = "flex-grow-1 text-truncate";
+
+        item.appendChild(name);
+
+        [["view", "bi-eye"], ["diff", "bi-file-diff"], ["restore", "bi-arrow-counterclockwise"]].forEach(function (action) {
+            // newest version has nothing to diff to
+            if (action[0] == "diff" && i == 0) {
+                return;
+            }
+
+            var btn = document.createElement("button");
+
+            btn.type = "button";
+            btn.title = action[0];
+            btn.className = "btn btn-sm btn-link p-0 ms-1 text-reset";
+            btn.innerHTML = '<i class="bi ' + action[1] + '"></i>';
+            btn.addEventListener("click", function () {
+                actions[action[0]](v);
+            });
+
+            item.appendChild(btn);
+        });
+
+        versionContainer.appendChild(item);
+    });
+}
+
+function refreshSaveState(saveState, saved) {
+    saveState.style.display = "inline-block";
+
+    if (saved.dirty) {
+        saveState.className = "badge m-1 text-bg-warning";
+        saveState.textContent = "Unsaved changes";
+
+        return;
+    }
+
+    saveState.className = "badge m-1 text-bg-success";
+    saveState.textContent = "Saved";
+}
+
 function hideReadyButtons(btnReady, btnUnready){
     btnReady.style.display = "none";
     btnReady.disabled = "disabled";
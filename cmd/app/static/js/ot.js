@@ -0,0 +1,255 @@
+var Range = ace.require("ace/range").Range;
+
+// Transform mirrors editor.Transform on server, keep them in sync.
+//
+// change is adjusted to be applied after concurrent applied change,
+// first decides which text goes first when both insert at same position.
+// Remove is split around insert inside it, next part is applied after it.
+function transform(change, applied, first) {
+    for (var part = applied; part; part = part.next) {
+        change = transformParts(change, part, first);
+    }
+
+    return change;
+}
+
+function transformParts(change, applied, first) {
+    var transformed = transformPart(change, applied, first);
+
+    if (change.next) {
+        var last = transformed;
+        while (last.next) {
+            last = last.next;
+        }
+
+        last.next = transform(change.next, transformPart(applied, change, !first), first);
+    }
+
+    return transformed;
+}
+
+function transformPart(change, applied, first) {
+    var transformed = {
+        action: change.action,
+        lines: change.lines,
+        revision: change.revision,
+    };
+
+    switch (change.action) {
+        case "insert":
+            transformed.start = transformPoint(change.start, applied, !first);
+            transformed.end = insertEnd(transformed.start, transformed.lines);
+            break;
+        case "remove":
+            if (applied.action == "insert" && isBefore(change.start, applied.start) && isBefore(applied.start, change.end)) {
+                return splitRemove(change, applied);
+            }
+
+            transformed.start = transformPoint(change.start, applied, true);
+            transformed.end = transformPoint(change.end, applied, false);
+
+            if (isBefore(transformed.end, transformed.start)) {
+                transformed.end = transformed.start;
+            }
+            break;
+        default:
+            transformed.start = change.start;
+            transformed.end = change.end;
+    }
+
+    return transformed;
+}
+
+function splitRemove(change, applied) {
+    var before = {
+        action: "remove",
+        start: change.start,
+        end: applied.start,
+        lines: change.lines,
+        revision: change.revision,
+    };
+
+    before.next = {
+        action: "remove",
+        start: transformPoint(insertEnd(applied.start, applied.lines), before, true),
+        end: transformPoint(transformPoint(change.end, applied, false), before, false),
+        lines: change.lines,
+        revision: change.revision,
+    };
+
+    return before;
+}
+
+function transformPoint(point, applied, after) {
+    switch (applied.action) {
+        case "insert":
+            if (isBefore(point, applied.start) || (isEqual(point, applied.start) && !after)) {
+                return point;
+            }
+
+            var end = insertEnd(applied.start, applied.lines);
+
+            if (point.row == applied.start.row) {
+                return { row: end.row, column: end.column + point.column - applied.start.column };
+            }
+
+            return { row: point.row + end.row - applied.start.row, column: point.column };
+        case "remove":
+            if (!isBefore(applied.start, point)) {
+                return point;
+            }
+
+            if (!isBefore(applied.end, point)) {
+                return applied.start;
+            }
+
+            if (point.row == applied.end.row) {
+                return { row: applied.start.row, column: applied.start.column + point.column - applied.end.column };
+            }
+
+            return { row: point.row - (applied.end.row - applied.start.row), column: point.column };
+    }
+
+    return point;
+}
+
+function insertEnd(start, lines) {
+    if (lines.length <= 1) {
+        var column = start.column;
+
+        if (lines.length == 1) {
+            column += lines[0].length;
+        }
+
+        return { row: start.row, column: column };
+    }
+
+    return { row: start.row + lines.length - 1, column: lines[lines.length - 1].length };
+}
+
+function isBefore(a, b) {
+    return a.row < b.row || (a.row == b.row && a.column < b.column);
+}
+
+function isEqual(a, b) {
+    return a.row == b.row && a.column == b.column;
+}
+
+// Document keeps client side of revision protocol, one change is sent
+// at a time, others wait in buffer until server acknowledges it.
+function Document(session, send) {
+    this.session = session;
+    this.send = send;
+
+    this.revision = 0;
+    this.outstanding = null;
+    this.buffer = [];
+    this.received = {};
+    this.applying = false;
+}
+
+Document.prototype.reset = function (revision) {
+    this.revision = revision;
+    this.outstanding = null;
+    this.buffer = [];
+
+    for (var rev in this.received) {
+        if (rev <= revision) {
+            delete this.received[rev];
+        }
+    }
+
+    this.process();
+};
+
+Document.prototype.local = function (delta) {
+    if (this.applying) {
+        return;
+    }
+
+    var change = {
+        action: delta.action,
+        start: delta.start,
+        end: delta.end,
+        lines: delta.lines,
+    };
+
+    if (this.outstanding === null) {
+        this.sendChange(change);
+
+        return;
+    }
+
+    this.buffer.push(change);
+};
+
+Document.prototype.sendChange = function (change) {
+    this.outstanding = change;
+
+    this.send(Object.assign({ revision: this.revision }, change));
+};
+
+// ack and remote changes can arrive out of order, apply them by revision
+Document.prototype.ack = function (change) {
+    this.received[change.revision] = { ack: true };
+    this.process();
+};
+
+Document.prototype.remote = function (change) {
+    this.received[change.revision] = { change: change };
+    this.process();
+};
+
+Document.prototype.process = function () {
+    while (this.received[this.revision + 1]) {
+        var next = this.received[this.revision + 1];
+        delete this.received[this.revision + 1];
+
+        this.revision++;
+
+        if (next.ack) {
+            this.outstanding = null;
+
+            if (this.buffer.length > 0) {
+                this.sendChange(this.buffer.shift());
+            }
+
+            continue;
+        }
+
+        this.applyRemote(next.change);
+    }
+};
+
+Document.prototype.applyRemote = function (change) {
+    var pending = this.outstanding === null ? this.buffer : [this.outstanding].concat(this.buffer);
+
+    for (var i = 0; i < pending.length; i++) {
+        var local = pending[i];
+
+        pending[i] = transform(local, change, false);
+        change = transform(change, local, true);
+    }
+
+    if (this.outstanding !== null) {
+        this.outstanding = pending.shift();
+    }
+
+    this.buffer = pending;
+
+    this.applying = true;
+
+    for (var part = change; part; part = part.next) {
+        if (part.action == "remove") {
+            if (isEqual(part.start, part.end)) {
+                continue;
+            }
+
+            part.lines = this.session.doc.getLinesForRange(Range.fromPoints(part.start, part.end));
+        }
+
+        this.session.doc.applyDelta(part);
+    }
+
+    this.applying = false;
+};
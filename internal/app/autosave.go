@@ -0,0 +1,55 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	"github.com/fakovacic/editor/internal/errors"
+)
+
+// Autosave tells when files edited by clients are saved without clients
+// asking for it, file is saved if it changed since last save
+type Autosave struct {
+	Interval time.Duration // saved once interval passed since last save
+	Changes  int           // saved once number of changes were made since last save
+	Target   AutosaveTarget
+}
+
+// Enabled reports if files are autosaved
+func (a Autosave) Enabled() bool {
+	return a.Interval > 0 || a.Changes > 0
+}
+
+// Due reports if file with changes made since last save elapsed time ago is saved
+func (a Autosave) Due(changes int, elapsed time.Duration) bool {
+	if changes <= 0 {
+		return false
+	}
+
+	return (a.Changes > 0 && changes >= a.Changes) || (a.Interval > 0 && elapsed >= a.Interval)
+}
+
+type AutosaveTarget string
+
+const (
+	AutosaveDraft AutosaveTarget = "draft" // contents saved only as version
+	AutosaveFile  AutosaveTarget = "file"  // file written, version saved as on every write
+)
+
+func (t AutosaveTarget) String() string {
+	return string(t)
+}
+
+func (t *AutosaveTarget) Parse(s string) error {
+	s = strings.Trim(s, "\"")
+	switch s {
+	case "draft":
+		*t = AutosaveDraft
+	case "file":
+		*t = AutosaveFile
+	default:
+		return errors.BadRequest("invalid autosave target '%s'", s)
+	}
+
+	return nil
+}
@@ -4,6 +4,12 @@ import "context"
 
 const (
 	RequestID Key = "reqID"
+
+	// Save is SaveInfo of contents written
+	Save Key = "save"
+
+	// Sync is set for reads of source compared with loaded contents
+	Sync Key = "sync"
 )
 
 type Key string
@@ -24,3 +30,26 @@ func GetStringValue(ctx context.Context, key Key) string {
 
 	return ""
 }
+
+// WithSaveInfo returns context of write described by info
+func WithSaveInfo(ctx context.Context, info SaveInfo) context.Context {
+	return context.WithValue(ctx, Save, info)
+}
+
+func GetSaveInfo(ctx context.Context) SaveInfo {
+	info, _ := ctx.Value(Save).(SaveInfo)
+
+	return info
+}
+
+// WithSync returns context of source read to compare it with loaded
+// contents, such reads are not versions of file
+func WithSync(ctx context.Context) context.Context {
+	return context.WithValue(ctx, Sync, true)
+}
+
+func IsSync(ctx context.Context) bool {
+	sync, _ := ctx.Value(Sync).(bool)
+
+	return sync
+}
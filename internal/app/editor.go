@@ -2,6 +2,9 @@ package app
 
 import (
 	"context"
+	"strings"
+
+	"github.com/fakovacic/editor/internal/errors"
 )
 
 //go:generate moq -out ./mocks/editor.go -pkg mocks  . Editor
@@ -11,8 +14,50 @@ type Editor interface {
 	Load(context.Context) error
 	Unload(context.Context) error
 
-	Write(context.Context) error
-	Read(context.Context) (string, error)
+	// Write saves contents, source changed since load is merged first
+	// and returned changes bring clients to merged contents
+	Write(context.Context) ([]*ChangeMsg, error)
+
+	// Reload merges source changed since load into contents,
+	// returned changes bring clients to merged contents
+	Reload(context.Context) ([]*ChangeMsg, error)
+	Read(context.Context) (string, *FileMeta, error)
+
+	// Encoded returns contents as they are written to source, in its
+	// line endings and BOM
+	Encoded(context.Context) (string, error)
+
+	Change(context.Context, *ChangeMsg) (*ChangeMsg, error)
+
+	// Replace sets contents, returned changes bring clients to them
+	Replace(context.Context, string) ([]*ChangeMsg, error)
+
+	// State returns replication state clients need to join session,
+	// empty if editor mode works with contents only
+	State(context.Context) (string, error)
+}
+
+type EditorMode string
+
+const (
+	ModeDelta EditorMode = "delta" // Ace deltas transformed on server
+	ModeCRDT  EditorMode = "crdt"  // crdt ops merged without server ordering
+)
+
+func (t EditorMode) String() string {
+	return string(t)
+}
+
+func (t *EditorMode) Parse(s string) error {
+	s = strings.Trim(s, "\"")
+	switch s {
+	case "delta":
+		*t = ModeDelta
+	case "crdt":
+		*t = ModeCRDT
+	default:
+		return errors.BadRequest("invalid editor mode '%s'", s)
+	}
 
-	Change(context.Context, *ChangeMsg) error
+	return nil
 }
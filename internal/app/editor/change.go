@@ -1,155 +1,215 @@
 package editor
 
 import (
-	"bufio"
 	"context"
 	"strings"
-	"unicode/utf8"
 
 	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor/rope"
+	"github.com/fakovacic/editor/internal/errors"
 )
 
-func (s *editor) Change(_ context.Context, msg *app.ChangeMsg) error {
+// maxHistory is number of applied changes kept for transforming
+// changes based on older revisions
+const maxHistory = 1000
+
+// Change transforms change against changes applied since its revision,
+// applies it and returns applied change with new revision
+func (s *editor) Change(_ context.Context, msg *app.ChangeMsg) (*app.ChangeMsg, error) {
 	s.file.Lock()
 	defer s.file.Unlock()
 
-	switch msg.Action {
-	case OpInsert:
-		s.file.Contents = s.Insert(s.file.Contents, msg)
-	case OpRemove:
-		s.file.Contents = s.Remove(s.file.Contents, msg)
+	if s.file.Contents == nil {
+		return nil, errors.New("contents not loaded")
 	}
 
-	return nil
-}
+	err := validate(msg)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *editor) Insert(content string, msg *app.ChangeMsg) string {
-	contentScanner := bufio.NewScanner(strings.NewReader(content))
-	contentScanner.Split(bufio.ScanLines)
+	behind := s.file.Revision - msg.Revision
+	if behind < 0 || behind > len(s.file.History) {
+		return nil, errors.BadRequest("revision %d not available", msg.Revision)
+	}
 
-	var (
-		newValue strings.Builder
-		row      int
-	)
+	change := copyParts(msg)
 
-	totalRows := linesStringCount(s.file.Contents)
+	for _, applied := range s.file.History[len(s.file.History)-behind:] {
+		change = Transform(change, applied, false)
+	}
 
-	lineSuffix := "\n"
+	// lines of split remove are ones of whole remove, they are not checked
+	err = s.apply(change, behind == 0 && msg.Next == nil)
+	if err != nil {
+		return nil, err
+	}
 
-	for contentScanner.Scan() {
-		// last line should not have new line
-		if row == totalRows {
-			lineSuffix = ""
-		}
+	return change, nil
+}
 
-		line := contentScanner.Text()
+// copyParts copies change with its next parts without revision
+func copyParts(msg *app.ChangeMsg) *app.ChangeMsg {
+	if msg == nil {
+		return nil
+	}
 
-		switch {
-		case row == msg.Start.Row:
-			textBefore := line[0:msg.Start.Column]
-			textAfter := line[msg.Start.Column:]
+	return &app.ChangeMsg{
+		Action: msg.Action,
+		Start:  msg.Start,
+		End:    msg.End,
+		Lines:  msg.Lines,
+		Next:   copyParts(msg.Next),
+	}
+}
 
-			// insert content between text
-			line = textBefore + strings.Join(msg.Lines, "\n") + textAfter
+// apply applies change with its next parts to contents and adds it to
+// history with new revision, contents are kept if any part fails
+func (s *editor) apply(change *app.ChangeMsg, current bool) error {
+	var (
+		contents string
+		changed  bool
+	)
 
-			newValue.WriteString(line + lineSuffix)
+	if change.Next != nil {
+		contents = s.file.Contents.String()
+	}
 
-			if len(msg.Lines) > 1 {
-				row += (len(msg.Lines) - 1)
+	for part := change; part != nil; part = part.Next {
+		var err error
+
+		// empty insert or remove leaves contents as they are
+		switch part.Action {
+		case OpInsert:
+			changed = changed || part.Start != insertEnd(part.Start, part.Lines)
+			err = s.insert(part)
+		case OpRemove:
+			changed = changed || part.Start != part.End
+			err = s.remove(part, current)
+		}
 
-				continue
+		if err != nil {
+			if change.Next != nil {
+				s.file.Contents = rope.New(contents)
 			}
 
-			row++
-		default:
-			newValue.WriteString(line + lineSuffix)
-
-			row++
+			return err
 		}
 	}
 
-	// write additional lines
-	if msg.End.Row > totalRows {
-		newValue.WriteString(strings.Join(msg.Lines, "\n"))
+	s.file.Revision++
+	s.file.Saved.Dirty = s.file.Saved.Dirty || changed
+	change.Revision = s.file.Revision
+
+	s.file.History = append(s.file.History, change)
+	if len(s.file.History) > maxHistory {
+		s.file.History = s.file.History[len(s.file.History)-maxHistory:]
 	}
 
-	return newValue.String()
+	return nil
 }
 
-func (s *editor) Remove(content string, msg *app.ChangeMsg) string {
-	contentScanner := bufio.NewScanner(strings.NewReader(content))
-	contentScanner.Split(bufio.ScanLines)
+// validate checks change and its next parts are well formed, positions
+// are checked against contents when change is applied
+func validate(msg *app.ChangeMsg) error {
+	for part := msg; part != nil; part = part.Next {
+		err := validatePart(part)
+		if err != nil {
+			return err
+		}
+	}
 
-	var (
-		newValue strings.Builder
-		row      int
-		linesRow int
-	)
+	return nil
+}
 
-	totalRows := linesStringCount(s.file.Contents)
+func validatePart(msg *app.ChangeMsg) error {
+	switch msg.Action {
+	case OpInsert, OpRemove:
+	default:
+		return errors.BadRequest("invalid change action '%s'", msg.Action)
+	}
 
-	lineSuffix := "\n"
+	if len(msg.Lines) == 0 {
+		return errors.BadRequest("change lines empty")
+	}
 
-	for contentScanner.Scan() {
-		// last line should not have new line
-		if row == totalRows {
-			lineSuffix = ""
+	for _, line := range msg.Lines {
+		if strings.Contains(line, "\n") {
+			return errors.BadRequest("change line contains new line")
 		}
+	}
 
-		line := contentScanner.Text()
+	end := insertEnd(msg.Start, msg.Lines)
+	if end != msg.End {
+		return errors.BadRequest("change end %d:%d does not match lines, expected %d:%d", msg.End.Row, msg.End.Column, end.Row, end.Column)
+	}
 
-		switch {
-		case row < msg.Start.Row:
-			newValue.WriteString(line + lineSuffix)
+	return nil
+}
 
-			row++
-		case row > msg.End.Row:
-			newValue.WriteString(line + lineSuffix)
+func (s *editor) insert(msg *app.ChangeMsg) error {
+	offset, err := s.offset(msg.Start)
+	if err != nil {
+		return errors.Wrap(err, "insert start")
+	}
 
-			row++
-		case msg.End.Row == msg.Start.Row:
-			contentCount := utf8.RuneCountInString(msg.Lines[linesRow])
+	err = s.file.Contents.Insert(offset, strings.Join(msg.Lines, "\n"))
+	if err != nil {
+		return errors.Wrap(err, "insert")
+	}
 
-			textBefore := line[0:msg.Start.Column]
-			textAfter := line[(msg.Start.Column + contentCount):]
+	return nil
+}
 
-			line = textBefore + textAfter
+// remove deletes range, current reports change is based on current
+// revision so its lines must match removed text
+func (s *editor) remove(msg *app.ChangeMsg, current bool) error {
+	start, err := s.offset(msg.Start)
+	if err != nil {
+		return errors.Wrap(err, "remove start")
+	}
+
+	end, err := s.offset(msg.End)
+	if err != nil {
+		return errors.Wrap(err, "remove end")
+	}
 
-			newValue.WriteString(line + lineSuffix)
+	// transformed range can differ from client lines, send what is removed
+	removed, err := s.file.Contents.Substring(start, end)
+	if err != nil {
+		return errors.Wrap(err, "remove range")
+	}
 
-			row++
-			linesRow++
-		case row == msg.Start.Row:
-			newValue.WriteString(line[0:msg.Start.Column])
+	if current && removed != strings.Join(msg.Lines, "\n") {
+		return errors.BadRequest("remove lines do not match contents")
+	}
 
-			row++
-			linesRow++
-		case row == msg.End.Row:
-			pointer := msg.End.Column
-			if msg.End.Column != 0 {
-				pointer = msg.End.Column - 1
-			}
+	err = s.file.Contents.Remove(start, end-start)
+	if err != nil {
+		return errors.Wrap(err, "remove")
+	}
 
-			line = line[pointer:]
+	msg.Lines = strings.Split(removed, "\n")
 
-			newValue.WriteString(line + lineSuffix)
+	return nil
+}
 
-			row++
-			linesRow++
-		default:
-			row++
-			linesRow++
-		}
+// offset converts row and UTF-16 column to byte offset in contents
+func (s *editor) offset(pos app.ChangeRow) (int, error) {
+	lineStart, err := s.file.Contents.LineStart(pos.Row)
+	if err != nil {
+		return 0, err
 	}
 
-	return newValue.String()
-}
+	line, err := s.file.Contents.Line(pos.Row)
+	if err != nil {
+		return 0, err
+	}
 
-func linesStringCount(s string) int {
-	n := strings.Count(s, "\n")
-	if len(s) > 0 && !strings.HasSuffix(s, "\n") {
-		n++
+	column, err := columnOffset(line, pos.Column)
+	if err != nil {
+		return 0, err
 	}
 
-	return n
+	return lineStart + column, nil
 }
@@ -2,12 +2,17 @@ package editor_test
 
 import (
 	"context"
+	"math/rand"
+	"os"
 	"strings"
 	"testing"
+	"unicode/utf16"
+	"unicode/utf8"
 
 	"github.com/fakovacic/editor/internal/app"
 	"github.com/fakovacic/editor/internal/app/editor"
 	"github.com/fakovacic/editor/internal/app/editor/mocks"
+	"github.com/fakovacic/editor/internal/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,11 +43,11 @@ code.hljs {
 					Action: "remove",
 					Start: app.ChangeRow{
 						Row:    3,
-						Column: 13,
+						Column: 10,
 					},
 					End: app.ChangeRow{
 						Row:    3,
-						Column: 16,
+						Column: 13,
 					},
 					Lines: []string{"1em"},
 				},
@@ -50,37 +55,40 @@ code.hljs {
 					Action: "insert",
 					Start: app.ChangeRow{
 						Row:    3,
-						Column: 13,
+						Column: 10,
 					},
 					End: app.ChangeRow{
 						Row:    3,
-						Column: 14,
+						Column: 11,
 					},
-					Lines: []string{"2"},
+					Lines:    []string{"2"},
+					Revision: 1,
 				},
 				{
 					Action: "insert",
 					Start: app.ChangeRow{
 						Row:    3,
-						Column: 14,
+						Column: 11,
 					},
 					End: app.ChangeRow{
 						Row:    3,
-						Column: 15,
+						Column: 12,
 					},
-					Lines: []string{"e"},
+					Lines:    []string{"e"},
+					Revision: 2,
 				},
 				{
 					Action: "insert",
 					Start: app.ChangeRow{
 						Row:    3,
-						Column: 15,
+						Column: 12,
 					},
 					End: app.ChangeRow{
 						Row:    3,
-						Column: 16,
+						Column: 13,
 					},
-					Lines: []string{"m"},
+					Lines:    []string{"m"},
+					Revision: 3,
 				},
 			},
 
@@ -108,7 +116,7 @@ code.hljs {
 						Row:    9,
 						Column: 0,
 					},
-					Lines: []string{"", "code.hljs {", "    padding: 3px 5px;", "}", ""},
+					Lines: []string{"", "code.hljs {", "\tpadding: 3px 5px;", "}", ""},
 				},
 			},
 
@@ -163,18 +171,18 @@ code.hljs {
 			}
 
 			for _, req := range tc.req {
-				err = editor.Change(context.Background(), req)
+				_, err = editor.Change(context.Background(), req)
 				if err != nil {
 					assert.Equal(t, err.Error(), tc.expectedError)
 				}
 			}
 
-			res, err := editor.Read(context.Background())
+			res, _, err := editor.Read(context.Background())
 			if err != nil {
 				assert.Equal(t, err.Error(), tc.expectedError)
 			}
 
-			assert.Equal(t, strings.ReplaceAll(tc.expectedResponse, "\t", "    "), res)
+			assert.Equal(t, tc.expectedResponse, res)
 		})
 	}
 }
@@ -188,7 +196,8 @@ func TestChangesNewLine(t *testing.T) {
 
 code.hljs {
 	padding: 3px 5px;
-}`
+}
+`
 
 	cases := []struct {
 		it string
@@ -234,7 +243,7 @@ code.hljs {
 					Action: "insert",
 					Start: app.ChangeRow{
 						Row:    2,
-						Column: 21,
+						Column: 18,
 					},
 					End: app.ChangeRow{
 						Row:    3,
@@ -274,18 +283,18 @@ code.hljs {
 			}
 
 			for _, req := range tc.req {
-				err = editor.Change(context.Background(), req)
+				_, err = editor.Change(context.Background(), req)
 				if err != nil {
 					assert.Equal(t, err.Error(), tc.expectedError)
 				}
 			}
 
-			res, err := editor.Read(context.Background())
+			res, _, err := editor.Read(context.Background())
 			if err != nil {
 				assert.Equal(t, err.Error(), tc.expectedError)
 			}
 
-			assert.Equal(t, strings.ReplaceAll(tc.expectedResponse, "\t", "    "), res)
+			assert.Equal(t, tc.expectedResponse, res)
 		})
 	}
 }
@@ -349,7 +358,7 @@ code.hljs {
 					Action: "remove",
 					Start: app.ChangeRow{
 						Row:    9,
-						Column: 25,
+						Column: 19,
 					},
 					End: app.ChangeRow{
 						Row:    10,
@@ -390,18 +399,508 @@ code.hljs {
 			}
 
 			for _, req := range tc.req {
-				err = editor.Change(context.Background(), req)
+				_, err = editor.Change(context.Background(), req)
 				if err != nil {
 					assert.Equal(t, err.Error(), tc.expectedError)
 				}
 			}
 
-			res, err := editor.Read(context.Background())
+			res, _, err := editor.Read(context.Background())
 			if err != nil {
 				assert.Equal(t, err.Error(), tc.expectedError)
 			}
 
-			assert.Equal(t, strings.ReplaceAll(tc.expectedResponse, "\t", "    "), res)
+			assert.Equal(t, tc.expectedResponse, res)
+		})
+	}
+}
+
+func TestChangesUnicode(t *testing.T) {
+	const cssExample = `a::before {
+  content: "😀 café";
+}
+`
+
+	cases := []struct {
+		it string
+
+		req []*app.ChangeMsg
+
+		expectedResponse string
+		expectedError    string
+	}{
+		{
+			it: "insert after emoji",
+			req: []*app.ChangeMsg{
+				{
+					Action: "insert",
+					Start: app.ChangeRow{
+						Row:    1,
+						Column: 14,
+					},
+					End: app.ChangeRow{
+						Row:    1,
+						Column: 15,
+					},
+					Lines: []string{"!"},
+				},
+			},
+
+			expectedResponse: `a::before {
+  content: "😀! café";
+}
+`,
+		},
+		{
+			it: "remove accented char",
+			req: []*app.ChangeMsg{
+				{
+					Action: "remove",
+					Start: app.ChangeRow{
+						Row:    1,
+						Column: 18,
+					},
+					End: app.ChangeRow{
+						Row:    1,
+						Column: 19,
+					},
+					Lines: []string{"é"},
+				},
+			},
+
+			expectedResponse: `a::before {
+  content: "😀 caf";
+}
+`,
+		},
+		{
+			it: "remove emoji",
+			req: []*app.ChangeMsg{
+				{
+					Action: "remove",
+					Start: app.ChangeRow{
+						Row:    1,
+						Column: 12,
+					},
+					End: app.ChangeRow{
+						Row:    1,
+						Column: 14,
+					},
+					Lines: []string{"😀"},
+				},
+				{
+					Action: "insert",
+					Start: app.ChangeRow{
+						Row:    1,
+						Column: 12,
+					},
+					End: app.ChangeRow{
+						Row:    1,
+						Column: 14,
+					},
+					Lines:    []string{"🎉"},
+					Revision: 1,
+				},
+			},
+
+			expectedResponse: `a::before {
+  content: "🎉 café";
+}
+`,
+		},
+		{
+			it: "insert inside emoji",
+			req: []*app.ChangeMsg{
+				{
+					Action: "insert",
+					Start: app.ChangeRow{
+						Row:    1,
+						Column: 13,
+					},
+					End: app.ChangeRow{
+						Row:    1,
+						Column: 14,
+					},
+					Lines: []string{"!"},
+				},
+			},
+
+			expectedResponse: cssExample,
+			expectedError:    "insert start: column 13 splits character",
+		},
+		{
+			it: "insert after line end",
+			req: []*app.ChangeMsg{
+				{
+					Action: "insert",
+					Start: app.ChangeRow{
+						Row:    1,
+						Column: 22,
+					},
+					End: app.ChangeRow{
+						Row:    1,
+						Column: 23,
+					},
+					Lines: []string{"!"},
+				},
+			},
+
+			expectedResponse: cssExample,
+			expectedError:    "insert start: column 22 out of range",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			io := &mocks.IOMock{
+				ReadFunc: func(ctx context.Context) (string, *app.FileMeta, error) {
+					return cssExample, nil, nil
+				},
+			}
+
+			editor := editor.New(io)
+
+			err := editor.Load(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, req := range tc.req {
+				_, err = editor.Change(context.Background(), req)
+				if err != nil {
+					assert.Equal(t, tc.expectedError, err.Error())
+				}
+			}
+
+			res, _, err := editor.Read(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.expectedResponse, res)
+		})
+	}
+}
+
+func TestChangesInvalid(t *testing.T) {
+	const cssExample = `a {
+  color: red;
+}`
+
+	cases := []struct {
+		it string
+
+		req *app.ChangeMsg
+
+		expectedError string
+	}{
+		{
+			it: "unknown action",
+			req: &app.ChangeMsg{
+				Action: "replace",
+				Lines:  []string{""},
+			},
+			expectedError: "invalid change action 'replace'",
+		},
+		{
+			it: "empty lines",
+			req: &app.ChangeMsg{
+				Action: "insert",
+			},
+			expectedError: "change lines empty",
+		},
+		{
+			it: "line with new line",
+			req: &app.ChangeMsg{
+				Action: "insert",
+				End:    app.ChangeRow{Row: 0, Column: 3},
+				Lines:  []string{"a\nb"},
+			},
+			expectedError: "change line contains new line",
+		},
+		{
+			it: "end not matching lines",
+			req: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 1, Column: 2},
+				End:    app.ChangeRow{Row: 1, Column: 2},
+				Lines:  []string{"b"},
+			},
+			expectedError: "change end 1:2 does not match lines, expected 1:3",
+		},
+		{
+			it: "row out of range",
+			req: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 5, Column: 0},
+				End:    app.ChangeRow{Row: 5, Column: 1},
+				Lines:  []string{"b"},
+			},
+			expectedError: "insert start: row 5 out of range",
+		},
+		{
+			it: "negative column",
+			req: &app.ChangeMsg{
+				Action: "remove",
+				Start:  app.ChangeRow{Row: 1, Column: -1},
+				End:    app.ChangeRow{Row: 1, Column: 0},
+				Lines:  []string{"b"},
+			},
+			expectedError: "remove start: column -1 out of range",
+		},
+		{
+			it: "remove end out of range",
+			req: &app.ChangeMsg{
+				Action: "remove",
+				Start:  app.ChangeRow{Row: 2, Column: 0},
+				End:    app.ChangeRow{Row: 3, Column: 0},
+				Lines:  []string{"}", ""},
+			},
+			expectedError: "remove end: row 3 out of range",
+		},
+		{
+			it: "remove lines not matching contents",
+			req: &app.ChangeMsg{
+				Action: "remove",
+				Start:  app.ChangeRow{Row: 1, Column: 9},
+				End:    app.ChangeRow{Row: 1, Column: 12},
+				Lines:  []string{"blu"},
+			},
+			expectedError: "remove lines do not match contents",
+		},
+		{
+			it: "revision ahead",
+			req: &app.ChangeMsg{
+				Action:   "insert",
+				End:      app.ChangeRow{Row: 0, Column: 1},
+				Lines:    []string{"b"},
+				Revision: 1,
+			},
+			expectedError: "revision 1 not available",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			io := &mocks.IOMock{
+				ReadFunc: func(ctx context.Context) (string, *app.FileMeta, error) {
+					return cssExample, &app.FileMeta{}, nil
+				},
+			}
+
+			editor := editor.New(io)
+
+			err := editor.Load(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = editor.Change(context.Background(), tc.req)
+			if assert.NotNil(t, err) {
+				assert.Equal(t, tc.expectedError, err.Error())
+				assert.True(t, errors.IsBadRequest(err))
+			}
+
+			res, meta, err := editor.Read(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, cssExample, res)
+			assert.Equal(t, 0, meta.Revision)
+		})
+	}
+}
+
+// TestChangesRandom applies random changes on multi-byte text,
+// contents must match reference applying same changes
+func TestChangesRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 100; i++ {
+		contents := randomText(rnd, 40)
+
+		io := &mocks.IOMock{
+			ReadFunc: func(ctx context.Context) (string, *app.FileMeta, error) {
+				return contents, nil, nil
+			},
+		}
+
+		editor := editor.New(io)
+
+		err := editor.Load(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for rev := 0; rev < 50; rev++ {
+			change := randomChange(rnd, contents)
+			change.Revision = rev
+
+			_, err = editor.Change(context.Background(), change)
+			if err != nil {
+				t.Fatalf("contents %q change %+v: %s", contents, change, err)
+			}
+
+			contents = applyChange(contents, change)
+		}
+
+		res, _, err := editor.Read(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, contents, res)
+	}
+}
+
+// FuzzChange checks change never panics, valid change must match
+// reference and invalid change must leave contents as they were
+func FuzzChange(f *testing.F) {
+	f.Add("a {\n  content: \"😀\";\n}", 1, 12, 1, 14, "")
+	f.Add("a {\n  content: \"😀\";\n}", 1, 13, 1, 13, "é")
+	f.Add("é\n😀😀", 1, 2, 0, 0, "a\nb")
+	f.Add("", 0, 0, 0, 0, "🎉")
+
+	f.Fuzz(func(t *testing.T, contents string, row, column, endRow, endColumn int, text string) {
+		if !utf8.ValidString(contents) || !utf8.ValidString(text) {
+			t.Skip()
+		}
+
+		io := &mocks.IOMock{
+			ReadFunc: func(ctx context.Context) (string, *app.FileMeta, error) {
+				return contents, nil, nil
+			},
+		}
+
+		editor := editor.New(io)
+
+		err := editor.Load(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		loaded, _, err := editor.Read(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		change := &app.ChangeMsg{
+			Action: "remove",
+			Start:  app.ChangeRow{Row: row, Column: column},
+			End:    app.ChangeRow{Row: endRow, Column: endColumn},
+			Lines:  []string{""},
+		}
+
+		if text != "" {
+			change.Action = "insert"
+			change.Lines = strings.Split(text, "\n")
+		}
+
+		valid := validPosition(loaded, change.Start)
+		if change.Action == "remove" {
+			valid = valid && validPosition(loaded, change.End) &&
+				offset(loaded, change.Start) <= offset(loaded, change.End)
+		}
+
+		// well formed delta as Ace sends it
+		if valid {
+			start := offset(loaded, change.Start)
+
+			switch change.Action {
+			case "insert":
+				change.End = position(loaded[:start]+text, start+len(text))
+			case "remove":
+				change.Lines = strings.Split(loaded[start:offset(loaded, change.End)], "\n")
+			}
+		}
+
+		expected := loaded
+		if valid {
+			expected = applyChange(loaded, change)
+		}
+
+		_, err = editor.Change(context.Background(), change)
+		if valid && err != nil {
+			t.Fatalf("valid change %+v: %s", change, err)
+		}
+
+		if !valid && err == nil {
+			t.Fatalf("invalid change %+v applied", change)
+		}
+
+		res, _, err := editor.Read(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, expected, res)
+	})
+}
+
+// validPosition reports if row exists and column is at char boundary
+func validPosition(contents string, pos app.ChangeRow) bool {
+	lines := strings.Split(contents, "\n")
+	if pos.Row < 0 || pos.Row >= len(lines) {
+		return false
+	}
+
+	line := utf16.Encode([]rune(lines[pos.Row]))
+	if pos.Column < 0 || pos.Column > len(line) {
+		return false
+	}
+
+	// low surrogate is second half of char
+	return pos.Column == len(line) || line[pos.Column] < 0xDC00 || line[pos.Column] > 0xDFFF
+}
+
+func BenchmarkChange(b *testing.B) {
+	contents, err := os.ReadFile("../../../examples/assets/big.css")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	io := &mocks.IOMock{
+		ReadFunc: func(ctx context.Context) (string, *app.FileMeta, error) {
+			return string(contents), nil, nil
+		},
+	}
+
+	editor := editor.New(io)
+
+	err = editor.Load(context.Background())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	rows := strings.Count(string(contents), "\n")
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// type a char and remove it, spread over whole file
+		row := (i * 7919) % rows
+
+		_, err = editor.Change(context.Background(), &app.ChangeMsg{
+			Action:   "insert",
+			Start:    app.ChangeRow{Row: row, Column: 0},
+			End:      app.ChangeRow{Row: row, Column: 1},
+			Lines:    []string{"a"},
+			Revision: 2 * i,
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		_, err = editor.Change(context.Background(), &app.ChangeMsg{
+			Action:   "remove",
+			Start:    app.ChangeRow{Row: row, Column: 0},
+			End:      app.ChangeRow{Row: row, Column: 1},
+			Lines:    []string{"a"},
+			Revision: 2*i + 1,
 		})
+		if err != nil {
+			b.Fatal(err)
+		}
 	}
 }
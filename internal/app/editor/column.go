@@ -0,0 +1,57 @@
+package editor
+
+import (
+	"unicode/utf8"
+
+	"github.com/fakovacic/editor/internal/errors"
+)
+
+// Ace counts columns in UTF-16 code units, contents are kept as UTF-8
+
+// columnLen returns length of text in UTF-16 code units
+func columnLen(s string) int {
+	n := 0
+
+	for _, r := range s {
+		n += runeColumns(r)
+	}
+
+	return n
+}
+
+// columnOffset converts UTF-16 column to byte offset in line
+func columnOffset(line string, column int) (int, error) {
+	if column < 0 {
+		return 0, errors.BadRequest("column %d out of range", column)
+	}
+
+	n := 0
+
+	for i, r := range line {
+		if n == column {
+			return i, nil
+		}
+
+		n += runeColumns(r)
+
+		if n > column {
+			return 0, errors.BadRequest("column %d splits character", column)
+		}
+	}
+
+	if n != column {
+		return 0, errors.BadRequest("column %d out of range", column)
+	}
+
+	return len(line), nil
+}
+
+// runeColumns returns number of UTF-16 code units for rune,
+// chars outside basic plane are encoded as surrogate pair
+func runeColumns(r rune) int {
+	if r > 0xFFFF && r <= utf8.MaxRune {
+		return 2
+	}
+
+	return 1
+}
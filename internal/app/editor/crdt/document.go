@@ -0,0 +1,356 @@
+package crdt
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor"
+	"github.com/fakovacic/editor/internal/app/editor/diff"
+	"github.com/fakovacic/editor/internal/errors"
+)
+
+// initialSite is site of chars loaded from io
+const initialSite = ""
+
+// serverSite is site of ops made on server, client sites are random ids
+const serverSite = "server"
+
+// Document is replicated growable array (RGA), every char has unique id
+// and is inserted after its origin, removed chars are kept as tombstones
+// so ops from any client can be merged in any order they arrive
+type Document struct {
+	head     *element
+	elements map[app.CRDTID]*element
+	removed  map[app.CRDTID]bool // removes which arrived before insert
+	clock    int
+}
+
+type element struct {
+	id      app.CRDTID
+	value   string
+	deleted bool
+	next    *element
+}
+
+func NewDocument(contents string) *Document {
+	d := &Document{
+		head:     &element{},
+		elements: make(map[app.CRDTID]*element),
+		removed:  make(map[app.CRDTID]bool),
+	}
+
+	last := d.head
+
+	for _, r := range contents {
+		d.clock++
+
+		el := &element{
+			id: app.CRDTID{
+				Site:  initialSite,
+				Clock: d.clock,
+			},
+			value: string(r),
+		}
+
+		last.next = el
+		last = el
+
+		d.elements[el.id] = el
+	}
+
+	return d
+}
+
+// NewDocumentFromRuns returns document with elements of runs, as Runs
+// returns them, so ops made on document can be merged again
+func NewDocumentFromRuns(runs []Run) *Document {
+	d := &Document{
+		head:     &element{},
+		elements: make(map[app.CRDTID]*element),
+		removed:  make(map[app.CRDTID]bool),
+	}
+
+	last := d.head
+
+	for _, run := range runs {
+		clock := run.Clock
+
+		for _, r := range run.Value {
+			el := &element{
+				id: app.CRDTID{
+					Site:  run.Site,
+					Clock: clock,
+				},
+				value:   string(r),
+				deleted: run.Deleted,
+			}
+
+			last.next = el
+			last = el
+
+			d.elements[el.id] = el
+			d.clock = max(d.clock, clock)
+
+			clock++
+		}
+	}
+
+	return d
+}
+
+func (d *Document) String() string {
+	var b strings.Builder
+
+	for el := d.head.next; el != nil; el = el.next {
+		if !el.deleted {
+			b.WriteString(el.value)
+		}
+	}
+
+	return b.String()
+}
+
+// Apply merges op into document, applying same op again does nothing
+func (d *Document) Apply(op app.CRDTOp) error {
+	switch op.Action {
+	case editor.OpInsert:
+		return d.insert(op)
+	case editor.OpRemove:
+		d.remove(op.ID)
+
+		return nil
+	default:
+		return errors.BadRequest("invalid crdt op '%s'", op.Action)
+	}
+}
+
+// Changes reports if op changes visible contents, ops applied before
+// and removes of chars which are not visible do not
+func (d *Document) Changes(op app.CRDTOp) bool {
+	switch op.Action {
+	case editor.OpInsert:
+		_, ok := d.elements[op.ID]
+
+		return !ok && !d.removed[op.ID]
+	case editor.OpRemove:
+		el, ok := d.elements[op.ID]
+
+		return ok && !el.deleted
+	default:
+		return false
+	}
+}
+
+// Validate checks ops before any of them is applied,
+// origins can be inserted by earlier ops in same change
+func (d *Document) Validate(ops []app.CRDTOp) error {
+	inserted := make(map[app.CRDTID]bool)
+
+	for _, op := range ops {
+		switch op.Action {
+		case editor.OpInsert:
+			// runs of state are split to chars with consecutive clocks
+			if utf8.RuneCountInString(op.Value) != 1 {
+				return errors.BadRequest("crdt insert %s:%d must be single char", op.ID.Site, op.ID.Clock)
+			}
+
+			if op.Origin != nil && d.elements[*op.Origin] == nil && !inserted[*op.Origin] {
+				return errors.BadRequest("crdt origin %s:%d not found", op.Origin.Site, op.Origin.Clock)
+			}
+
+			inserted[op.ID] = true
+		case editor.OpRemove:
+		default:
+			return errors.BadRequest("invalid crdt op '%s'", op.Action)
+		}
+	}
+
+	return nil
+}
+
+func (d *Document) insert(op app.CRDTOp) error {
+	if _, ok := d.elements[op.ID]; ok {
+		return nil
+	}
+
+	if utf8.RuneCountInString(op.Value) != 1 {
+		return errors.BadRequest("crdt insert %s:%d must be single char", op.ID.Site, op.ID.Clock)
+	}
+
+	prev := d.head
+
+	if op.Origin != nil {
+		origin, ok := d.elements[*op.Origin]
+		if !ok {
+			return errors.BadRequest("crdt origin %s:%d not found", op.Origin.Site, op.Origin.Clock)
+		}
+
+		prev = origin
+	}
+
+	// concurrent inserts after same origin are ordered by id,
+	// newer inserts and everything inserted after them go first
+	for prev.next != nil && isAfter(prev.next.id, op.ID) {
+		prev = prev.next
+	}
+
+	el := &element{
+		id:      op.ID,
+		value:   op.Value,
+		deleted: d.removed[op.ID],
+		next:    prev.next,
+	}
+
+	prev.next = el
+
+	d.elements[el.id] = el
+	delete(d.removed, el.id)
+
+	d.clock = max(d.clock, op.ID.Clock)
+
+	return nil
+}
+
+func (d *Document) remove(id app.CRDTID) {
+	el, ok := d.elements[id]
+	if !ok {
+		d.removed[id] = true
+
+		return
+	}
+
+	el.deleted = true
+}
+
+// Replace applies changed lines between document and contents,
+// returns ops made so clients can merge them
+func (d *Document) Replace(contents string) ([]app.CRDTOp, error) {
+	visible := make([]*element, 0)
+	lineStarts := []int{0}
+
+	for el := d.head.next; el != nil; el = el.next {
+		if el.deleted {
+			continue
+		}
+
+		visible = append(visible, el)
+
+		if strings.HasSuffix(el.value, "\n") {
+			lineStarts = append(lineStarts, len(visible))
+		}
+	}
+
+	// last line without new line ends with document
+	if lineStarts[len(lineStarts)-1] != len(visible) {
+		lineStarts = append(lineStarts, len(visible))
+	}
+
+	hunks := diff.Lines(diff.SplitLines(d.String()), diff.SplitLines(contents))
+
+	ops := make([]app.CRDTOp, 0)
+
+	for _, h := range hunks {
+		for _, el := range visible[lineStarts[h.Start]:lineStarts[h.End]] {
+			ops = append(ops, app.CRDTOp{
+				Action: editor.OpRemove,
+				ID:     el.id,
+			})
+		}
+
+		var origin *app.CRDTID
+
+		if lineStarts[h.Start] > 0 {
+			id := visible[lineStarts[h.Start]-1].id
+			origin = &id
+		}
+
+		for _, r := range strings.Join(h.Lines, "") {
+			d.clock++
+
+			id := app.CRDTID{
+				Site:  serverSite,
+				Clock: d.clock,
+			}
+
+			ops = append(ops, app.CRDTOp{
+				Action: editor.OpInsert,
+				ID:     id,
+				Origin: origin,
+				Value:  string(r),
+			})
+
+			origin = &id
+		}
+	}
+
+	for _, op := range ops {
+		err := d.Apply(op)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ops, nil
+}
+
+// isAfter reports if id a is ordered before b in document when both
+// are inserted after same origin, higher clock wins, site breaks ties
+func isAfter(a, b app.CRDTID) bool {
+	if a.Clock != b.Clock {
+		return a.Clock > b.Clock
+	}
+
+	return a.Site > b.Site
+}
+
+// Run is sequence of chars from same site with consecutive clocks
+type Run struct {
+	Site    string `json:"site"`
+	Clock   int    `json:"clock"`
+	Value   string `json:"value"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+// Runs returns document elements in order, joined into runs
+func (d *Document) Runs() []Run {
+	runs := make([]Run, 0)
+
+	var (
+		b    strings.Builder
+		last *Run
+		next int
+	)
+
+	for el := d.head.next; el != nil; el = el.next {
+		if last != nil && last.Site == el.id.Site && next == el.id.Clock && last.Deleted == el.deleted {
+			b.WriteString(el.value)
+
+			next++
+
+			continue
+		}
+
+		if last != nil {
+			last.Value = b.String()
+			runs = append(runs, *last)
+		}
+
+		b.Reset()
+		b.WriteString(el.value)
+
+		last = &Run{
+			Site:    el.id.Site,
+			Clock:   el.id.Clock,
+			Deleted: el.deleted,
+		}
+		next = el.id.Clock + 1
+	}
+
+	if last != nil {
+		last.Value = b.String()
+		runs = append(runs, *last)
+	}
+
+	return runs
+}
@@ -0,0 +1,386 @@
+package crdt_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor/crdt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocument(t *testing.T) {
+	cases := []struct {
+		it string
+
+		contents string
+		ops      []app.CRDTOp
+
+		expectedResponse string
+		expectedError    string
+	}{
+		{
+			it:       "insert at start",
+			contents: "ab",
+			ops: []app.CRDTOp{
+				insertOp("x", 1, nil, "c"),
+			},
+			expectedResponse: "cab",
+		},
+		{
+			it:       "insert after origin",
+			contents: "ab",
+			ops: []app.CRDTOp{
+				insertOp("x", 3, &app.CRDTID{Clock: 1}, "c"),
+				insertOp("x", 4, &app.CRDTID{Site: "x", Clock: 3}, "d"),
+			},
+			expectedResponse: "acdb",
+		},
+		{
+			it:       "concurrent inserts after same origin",
+			contents: "ab",
+			ops: []app.CRDTOp{
+				insertOp("x", 3, &app.CRDTID{Clock: 1}, "c"),
+				insertOp("y", 3, &app.CRDTID{Clock: 1}, "d"),
+			},
+			expectedResponse: "adcb",
+		},
+		{
+			it:       "remove",
+			contents: "ab",
+			ops: []app.CRDTOp{
+				removeOp("", 1),
+			},
+			expectedResponse: "b",
+		},
+		{
+			it:       "remove before insert",
+			contents: "ab",
+			ops: []app.CRDTOp{
+				removeOp("x", 3),
+				insertOp("x", 3, &app.CRDTID{Clock: 1}, "c"),
+			},
+			expectedResponse: "ab",
+		},
+		{
+			it:       "insert twice",
+			contents: "ab",
+			ops: []app.CRDTOp{
+				insertOp("x", 3, &app.CRDTID{Clock: 2}, "c"),
+				insertOp("x", 3, &app.CRDTID{Clock: 2}, "c"),
+			},
+			expectedResponse: "abc",
+		},
+		{
+			it:       "origin not found",
+			contents: "ab",
+			ops: []app.CRDTOp{
+				insertOp("x", 3, &app.CRDTID{Site: "y", Clock: 1}, "c"),
+			},
+			expectedResponse: "ab",
+			expectedError:    "crdt origin y:1 not found",
+		},
+		{
+			it:       "insert multibyte char",
+			contents: "ab",
+			ops: []app.CRDTOp{
+				insertOp("x", 3, &app.CRDTID{Clock: 1}, "é"),
+			},
+			expectedResponse: "aéb",
+		},
+		{
+			it:       "insert multiple chars",
+			contents: "ab",
+			ops: []app.CRDTOp{
+				insertOp("x", 3, &app.CRDTID{Clock: 1}, "cd"),
+			},
+			expectedResponse: "ab",
+			expectedError:    "crdt insert x:3 must be single char",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			doc := crdt.NewDocument(tc.contents)
+
+			for _, op := range tc.ops {
+				err := doc.Apply(op)
+				if err != nil {
+					assert.Equal(t, tc.expectedError, err.Error())
+				}
+			}
+
+			assert.Equal(t, tc.expectedResponse, doc.String())
+		})
+	}
+}
+
+// TestDocumentMerge applies same ops from multiple sites in different
+// orders, every replica must end with same contents
+func TestDocumentValidate(t *testing.T) {
+	cases := []struct {
+		it string
+
+		ops []app.CRDTOp
+
+		expectedError string
+	}{
+		{
+			it: "origin inserted by earlier op",
+			ops: []app.CRDTOp{
+				insertOp("x", 3, &app.CRDTID{Clock: 1}, "c"),
+				insertOp("x", 4, &app.CRDTID{Site: "x", Clock: 3}, "d"),
+			},
+		},
+		{
+			it: "origin not found",
+			ops: []app.CRDTOp{
+				insertOp("x", 3, &app.CRDTID{Site: "y", Clock: 1}, "c"),
+			},
+			expectedError: "crdt origin y:1 not found",
+		},
+		{
+			it: "insert empty",
+			ops: []app.CRDTOp{
+				insertOp("x", 3, &app.CRDTID{Clock: 1}, ""),
+			},
+			expectedError: "crdt insert x:3 must be single char",
+		},
+		{
+			it: "insert multiple chars",
+			ops: []app.CRDTOp{
+				insertOp("x", 3, &app.CRDTID{Clock: 1}, "c"),
+				insertOp("x", 4, &app.CRDTID{Site: "x", Clock: 3}, "dé"),
+			},
+			expectedError: "crdt insert x:4 must be single char",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			doc := crdt.NewDocument("ab")
+
+			err := doc.Validate(tc.ops)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.EqualError(t, err, tc.expectedError)
+
+			// nothing is applied
+			assert.Equal(t, "ab", doc.String())
+		})
+	}
+}
+
+func TestDocumentMerge(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 200; i++ {
+		base := crdt.NewDocument("body {}")
+
+		var ops []app.CRDTOp
+
+		// every site edits its own replica without seeing others
+		for _, site := range []string{"a", "b", "c"} {
+			replica := crdt.NewDocument("body {}")
+			ids := []app.CRDTID{{Clock: 1}, {Clock: 2}, {Clock: 3}, {Clock: 4}, {Clock: 5}, {Clock: 6}, {Clock: 7}}
+			clock := 7
+
+			for j := 0; j < 5; j++ {
+				var op app.CRDTOp
+
+				if rnd.Intn(3) == 0 {
+					op = removeOp(ids[rnd.Intn(len(ids))].Site, ids[rnd.Intn(len(ids))].Clock)
+				} else {
+					clock++
+
+					origin := ids[rnd.Intn(len(ids))]
+					op = insertOp(site, clock, &origin, string(rune('a'+rnd.Intn(26))))
+
+					ids = append(ids, op.ID)
+				}
+
+				err := replica.Apply(op)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				ops = append(ops, op)
+			}
+		}
+
+		for _, op := range ops {
+			err := base.Apply(op)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// ops of every site keep their order, sites are interleaved
+		shuffled := crdt.NewDocument("body {}")
+		next := map[string]int{"a": 0, "b": 5, "c": 10}
+
+		for len(next) > 0 {
+			sites := make([]string, 0, len(next))
+			for site := range next {
+				sites = append(sites, site)
+			}
+
+			site := sites[rnd.Intn(len(sites))]
+
+			err := shuffled.Apply(ops[next[site]])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			next[site]++
+			if next[site]%5 == 0 {
+				delete(next, site)
+			}
+		}
+
+		assert.Equal(t, base.String(), shuffled.String())
+	}
+}
+
+func TestDocumentReplace(t *testing.T) {
+	cases := []struct {
+		it string
+
+		contents string
+		replace  string
+	}{
+		{
+			it:       "append line",
+			contents: "a {}\n",
+			replace:  "a {}\nb {}\n",
+		},
+		{
+			it:       "change middle line",
+			contents: "a {\n  color: red;\n}\n",
+			replace:  "a {\n  color: blue;\n}\n",
+		},
+		{
+			it:       "last line without new line",
+			contents: "a {\n}",
+			replace:  "a {\n}\nb {\n}",
+		},
+		{
+			it:       "first line",
+			contents: "a\nb",
+			replace:  "\u00e4\nb",
+		},
+		{
+			it:       "from empty",
+			contents: "",
+			replace:  "a {}",
+		},
+		{
+			it:       "to empty",
+			contents: "a {}\nb {}",
+			replace:  "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			doc := crdt.NewDocument(tc.contents)
+
+			ops, err := doc.Replace(tc.replace)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.replace, doc.String())
+
+			// replica of same contents gets same result from ops
+			replica := crdt.NewDocument(tc.contents)
+
+			for _, op := range ops {
+				err = replica.Apply(op)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			assert.Equal(t, tc.replace, replica.String())
+		})
+	}
+}
+
+func TestDocumentRuns(t *testing.T) {
+	doc := crdt.NewDocument("ab")
+
+	err := doc.Apply(insertOp("x", 3, &app.CRDTID{Clock: 2}, "c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = doc.Apply(removeOp("", 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []crdt.Run{
+		{Site: "", Clock: 1, Value: "a"},
+		{Site: "", Clock: 2, Value: "b", Deleted: true},
+		{Site: "x", Clock: 3, Value: "c"},
+	}, doc.Runs())
+}
+
+func TestDocumentFromRuns(t *testing.T) {
+	doc := crdt.NewDocumentFromRuns([]crdt.Run{
+		{Site: "", Clock: 1, Value: "a"},
+		{Site: "", Clock: 2, Value: "b", Deleted: true},
+		{Site: "x", Clock: 3, Value: "cd"},
+	})
+
+	assert.Equal(t, "acd", doc.String())
+
+	// ops based on runs merge as in document runs came from
+	err := doc.Apply(insertOp("y", 5, &app.CRDTID{Site: "x", Clock: 3}, "e"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "aced", doc.String())
+
+	// server ops continue after clocks of runs
+	ops, err := doc.Replace("aced!")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, op := range ops {
+		if op.Action == "insert" {
+			assert.Equal(t, 6, op.ID.Clock)
+
+			break
+		}
+	}
+}
+
+func insertOp(site string, clock int, origin *app.CRDTID, value string) app.CRDTOp {
+	return app.CRDTOp{
+		Action: "insert",
+		ID: app.CRDTID{
+			Site:  site,
+			Clock: clock,
+		},
+		Origin: origin,
+		Value:  value,
+	}
+}
+
+func removeOp(site string, clock int) app.CRDTOp {
+	return app.CRDTOp{
+		Action: "remove",
+		ID: app.CRDTID{
+			Site:  site,
+			Clock: clock,
+		},
+	}
+}
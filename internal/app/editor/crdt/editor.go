@@ -0,0 +1,309 @@
+package crdt
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/fakovacic/editor/internal/log"
+)
+
+func New(io editor.IO) app.Editor {
+	return &crdtEditor{
+		io: io,
+	}
+}
+
+type crdtEditor struct {
+	io   editor.IO
+	file crdtFile
+}
+
+type crdtFile struct {
+	Meta     *app.FileMeta
+	Format   app.FileFormat
+	Original string // io contents, written back as they are if not edited
+	Document *Document
+	Loaded   bool
+	Revision int
+	Saved    app.SaveState // contents changed since written, revision written
+	sync.Mutex
+}
+
+func (s *crdtEditor) FileMeta(_ context.Context) *app.FileMeta {
+	s.file.Lock()
+	defer s.file.Unlock()
+
+	return s.file.meta()
+}
+
+func (f *crdtFile) meta() *app.FileMeta {
+	if f.Meta == nil {
+		return nil
+	}
+
+	meta := *f.Meta
+	meta.Revision = f.Revision
+	meta.Mode = app.ModeCRDT
+	meta.Format = f.Format
+	meta.SaveState = f.Saved
+
+	return &meta
+}
+
+func (s *crdtEditor) Load(ctx context.Context) error {
+	s.file.Lock()
+	defer s.file.Unlock()
+
+	if s.file.Loaded {
+		return nil
+	}
+
+	contents, meta, err := s.io.Read(ctx)
+	if err != nil {
+		return errors.Wrap(err, "io read")
+	}
+
+	decoded, format := editor.Decode(contents)
+
+	// document from previous session is kept while file is unchanged,
+	// so clients reconnecting with offline ops can still merge them
+	if s.file.Document == nil || s.file.Document.String() != decoded {
+		s.file.Document = NewDocument(decoded)
+	}
+
+	s.file.Format = format
+	s.file.Original = contents
+	s.file.Meta = meta
+	s.file.Loaded = true
+
+	// revision goes on with kept document
+	s.file.Saved = app.SaveState{
+		SavedRevision: s.file.Revision,
+	}
+
+	return nil
+}
+
+func (s *crdtEditor) Unload(_ context.Context) error {
+	s.file.Lock()
+	defer s.file.Unlock()
+
+	s.file.Loaded = false
+
+	return nil
+}
+
+func (s *crdtEditor) Read(_ context.Context) (string, *app.FileMeta, error) {
+	s.file.Lock()
+	defer s.file.Unlock()
+
+	if !s.file.Loaded {
+		return "", s.file.meta(), nil
+	}
+
+	return s.file.Document.String(), s.file.meta(), nil
+}
+
+func (s *crdtEditor) Encoded(_ context.Context) (string, error) {
+	s.file.Lock()
+	defer s.file.Unlock()
+
+	if !s.file.Loaded {
+		return "", errors.New("contents not loaded")
+	}
+
+	return editor.Restore(s.file.Document.String(), s.file.Original), nil
+}
+
+func (s *crdtEditor) Write(ctx context.Context) ([]*app.ChangeMsg, error) {
+	s.file.Lock()
+	defer s.file.Unlock()
+
+	if !s.file.Loaded {
+		log.Error(ctx, "contents empty")
+
+		return nil, nil
+	}
+
+	if s.file.Document.String() == "" {
+		log.Error(ctx, "contents empty")
+
+		return nil, nil
+	}
+
+	// contents are same as written, no version is saved again
+	if !s.file.Saved.Dirty {
+		return nil, nil
+	}
+
+	changes, err := s.sync(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := editor.Restore(s.file.Document.String(), s.file.Original)
+
+	err = s.io.Write(ctx, s.file.Meta.Name, contents)
+	if err != nil {
+		return nil, errors.Wrap(err, "io write")
+	}
+
+	s.file.Original = contents
+	s.file.Meta.Fingerprint = editor.Fingerprint(ctx, s.io)
+	s.file.Saved = app.SaveState{
+		SavedRevision: s.file.Revision,
+	}
+
+	return changes, nil
+}
+
+func (s *crdtEditor) Reload(ctx context.Context) ([]*app.ChangeMsg, error) {
+	s.file.Lock()
+	defer s.file.Unlock()
+
+	if !s.file.Loaded {
+		return nil, nil
+	}
+
+	return s.sync(ctx)
+}
+
+// sync merges source changed since load into document,
+// returns change with ops made to bring document to merged contents
+func (s *crdtEditor) sync(ctx context.Context) ([]*app.ChangeMsg, error) {
+	source, fingerprint, changed, err := editor.Changed(ctx, s.io, s.file.Meta.Fingerprint, s.file.Original)
+	if err != nil {
+		return nil, err
+	}
+
+	if !changed {
+		s.file.Meta.Fingerprint = fingerprint
+
+		return nil, nil
+	}
+
+	merged, err := editor.Merge(s.file.Meta.Name, s.file.Original, s.file.Document.String(), source)
+	if err != nil {
+		return nil, err
+	}
+
+	ops, err := s.file.Document.Replace(merged)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply merge")
+	}
+
+	decoded, format := editor.Decode(source)
+
+	s.file.Original = source
+	s.file.Format = format
+	s.file.Meta.Fingerprint = fingerprint
+
+	if len(ops) == 0 {
+		return nil, nil
+	}
+
+	s.file.Revision++
+	s.file.Saved.Dirty = true
+
+	// contents without edits are same as source
+	if merged == decoded {
+		s.file.Saved = app.SaveState{
+			SavedRevision: s.file.Revision,
+		}
+	}
+
+	return []*app.ChangeMsg{
+		{
+			Ops:      ops,
+			Revision: s.file.Revision,
+		},
+	}, nil
+}
+
+// Change merges crdt ops, revision only counts merged changes
+func (s *crdtEditor) Change(_ context.Context, msg *app.ChangeMsg) (*app.ChangeMsg, error) {
+	s.file.Lock()
+	defer s.file.Unlock()
+
+	if !s.file.Loaded {
+		return nil, errors.New("contents not loaded")
+	}
+
+	if len(msg.Ops) == 0 {
+		return nil, errors.BadRequest("crdt ops empty")
+	}
+
+	err := s.file.Document.Validate(msg.Ops)
+	if err != nil {
+		return nil, errors.Wrap(err, "validate ops")
+	}
+
+	var changed bool
+
+	for _, op := range msg.Ops {
+		changed = changed || s.file.Document.Changes(op)
+
+		err = s.file.Document.Apply(op)
+		if err != nil {
+			return nil, errors.Wrap(err, "apply op")
+		}
+	}
+
+	s.file.Revision++
+	s.file.Saved.Dirty = s.file.Saved.Dirty || changed
+
+	return &app.ChangeMsg{
+		Ops:      msg.Ops,
+		Revision: s.file.Revision,
+	}, nil
+}
+
+func (s *crdtEditor) Replace(_ context.Context, contents string) ([]*app.ChangeMsg, error) {
+	s.file.Lock()
+	defer s.file.Unlock()
+
+	if !s.file.Loaded {
+		return nil, errors.New("contents not loaded")
+	}
+
+	decoded, _ := editor.Decode(contents)
+
+	ops, err := s.file.Document.Replace(decoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply replace")
+	}
+
+	if len(ops) == 0 {
+		return nil, nil
+	}
+
+	s.file.Revision++
+	s.file.Saved.Dirty = true
+
+	return []*app.ChangeMsg{
+		{
+			Ops:      ops,
+			Revision: s.file.Revision,
+		},
+	}, nil
+}
+
+func (s *crdtEditor) State(_ context.Context) (string, error) {
+	s.file.Lock()
+	defer s.file.Unlock()
+
+	if !s.file.Loaded {
+		return "", nil
+	}
+
+	state, err := json.Marshal(s.file.Document.Runs())
+	if err != nil {
+		return "", errors.Wrap(err, "marshal state")
+	}
+
+	return string(state), nil
+}
@@ -0,0 +1,239 @@
+package crdt_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor/crdt"
+	"github.com/fakovacic/editor/internal/app/editor/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditor(t *testing.T) {
+	ctx := context.Background()
+	ioContent := "ab"
+
+	io := &mocks.IOMock{
+		ReadFunc: func(ctx context.Context) (string, *app.FileMeta, error) {
+			return ioContent, &app.FileMeta{
+				Name:      "mock-name",
+				Extension: "mock-extension",
+			}, nil
+		},
+		WriteFunc: func(ctx context.Context, name string, contents string) error {
+			ioContent = contents
+
+			return nil
+		},
+		StatFunc: func(ctx context.Context) (string, error) {
+			return "", nil
+		},
+	}
+
+	editor := crdt.New(io)
+
+	err := editor.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meta := editor.FileMeta(ctx)
+	assert.Equal(t, app.ModeCRDT, meta.Mode)
+
+	_, err = editor.Change(ctx, &app.ChangeMsg{})
+	assert.NotNil(t, err)
+
+	change, err := editor.Change(ctx, &app.ChangeMsg{
+		Ops: []app.CRDTOp{
+			insertOp("x", 3, &app.CRDTID{Clock: 2}, "c"),
+			insertOp("x", 4, &app.CRDTID{Site: "x", Clock: 3}, "d"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, change.Revision)
+
+	// invalid op in change, nothing is applied
+	_, err = editor.Change(ctx, &app.ChangeMsg{
+		Ops: []app.CRDTOp{
+			removeOp("", 1),
+			insertOp("x", 5, &app.CRDTID{Site: "y", Clock: 1}, "e"),
+		},
+	})
+	assert.NotNil(t, err)
+
+	content, _, err := editor.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "abcd", content)
+
+	state, err := editor.State(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, `[{"site":"","clock":1,"value":"ab"},{"site":"x","clock":3,"value":"cd"}]`, state)
+	assert.Equal(t, app.SaveState{Dirty: true}, editor.FileMeta(ctx).SaveState)
+
+	_, err = editor.Write(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, app.SaveState{SavedRevision: 1}, editor.FileMeta(ctx).SaveState)
+
+	// clean contents are not written again
+	_, err = editor.Write(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, io.WriteCalls(), 1)
+
+	// ops sent again after reconnect leave contents as written
+	_, err = editor.Change(ctx, &app.ChangeMsg{
+		Ops: []app.CRDTOp{
+			insertOp("x", 3, &app.CRDTID{Clock: 2}, "c"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, app.SaveState{SavedRevision: 1}, editor.FileMeta(ctx).SaveState)
+
+	err = editor.Unload(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, _, err = editor.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "", content)
+
+	// file unchanged, ids from previous session are kept
+	err = editor.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err = editor.State(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, `[{"site":"","clock":1,"value":"ab"},{"site":"x","clock":3,"value":"cd"}]`, state)
+
+	// replaced contents are merged as one change
+	changes, err := editor.Replace(ctx, "abd")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, changes, 1)
+	assert.Equal(t, 3, changes[0].Revision)
+
+	content, _, err = editor.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "abd", content)
+	assert.Equal(t, app.SaveState{Dirty: true, SavedRevision: 2}, editor.FileMeta(ctx).SaveState)
+}
+
+func TestEditorSourceChanged(t *testing.T) {
+	cases := []struct {
+		it string
+
+		source string
+
+		expectedContents string
+		expectedWritten  string
+		expectedChanges  int
+		expectedError    string
+	}{
+		{
+			it:               "source unchanged",
+			source:           "ab\ncd\n",
+			expectedContents: "axb\ncd\n",
+			expectedWritten:  "axb\ncd\n",
+		},
+		{
+			it:               "source changed other lines",
+			source:           "ab\ncd\nef\n",
+			expectedContents: "axb\ncd\nef\n",
+			expectedWritten:  "axb\ncd\nef\n",
+			expectedChanges:  1,
+		},
+		{
+			it:               "source changed same line",
+			source:           "ab!\ncd\n",
+			expectedContents: "axb\ncd\n",
+			expectedError:    "file 'mock-name' changed on source, edits conflict",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			ctx := context.Background()
+			source, written := "ab\ncd\n", ""
+
+			io := &mocks.IOMock{
+				ReadFunc: func(ctx context.Context) (string, *app.FileMeta, error) {
+					return source, &app.FileMeta{
+						Name: "mock-name",
+					}, nil
+				},
+				WriteFunc: func(ctx context.Context, name string, contents string) error {
+					written = contents
+
+					return nil
+				},
+				StatFunc: func(ctx context.Context) (string, error) {
+					return "", nil
+				},
+			}
+
+			editor := crdt.New(io)
+
+			err := editor.Load(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = editor.Change(ctx, &app.ChangeMsg{
+				Ops: []app.CRDTOp{
+					insertOp("x", 7, &app.CRDTID{Clock: 1}, "x"),
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			source = tc.source
+
+			changes, err := editor.Write(ctx)
+			if err != nil {
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+
+			contents, _, err := editor.Read(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.expectedContents, contents)
+			assert.Equal(t, tc.expectedWritten, written)
+			assert.Len(t, changes, tc.expectedChanges)
+		})
+	}
+}
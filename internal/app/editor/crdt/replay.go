@@ -0,0 +1,36 @@
+package crdt
+
+import (
+	"encoding/json"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/errors"
+)
+
+// Replay merges ops of changes into document of state, as State returns
+// it, document of contents is used if state is empty
+func Replay(contents, state string, changes []*app.ChangeMsg) (string, error) {
+	document := NewDocument(contents)
+
+	if state != "" {
+		var runs []Run
+
+		err := json.Unmarshal([]byte(state), &runs)
+		if err != nil {
+			return "", errors.Wrap(err, "unmarshal state")
+		}
+
+		document = NewDocumentFromRuns(runs)
+	}
+
+	for _, change := range changes {
+		for _, op := range change.Ops {
+			err := document.Apply(op)
+			if err != nil {
+				return "", errors.Wrap(err, "apply op")
+			}
+		}
+	}
+
+	return document.String(), nil
+}
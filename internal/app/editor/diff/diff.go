@@ -0,0 +1,223 @@
+package diff
+
+import (
+	"strings"
+)
+
+// Hunk replaces lines from Start to End of old text with Lines,
+// lines keep their line endings
+type Hunk struct {
+	Start int
+	End   int
+	Lines []string
+}
+
+// SplitLines splits text after each new line, last line has no new line
+// if text does not end with it
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// Lines returns hunks changing lines of a to lines of b
+func Lines(a, b []string) []Hunk {
+	hunks := make([]Hunk, 0)
+
+	var current *Hunk
+
+	i, j := 0, 0
+
+	for _, op := range myers(a, b) {
+		switch op {
+		case opEqual:
+			if current != nil {
+				hunks = append(hunks, *current)
+				current = nil
+			}
+
+			i++
+			j++
+
+			continue
+		case opDelete:
+			if current == nil {
+				current = &Hunk{Start: i, End: i}
+			}
+
+			current.End++
+			i++
+		case opInsert:
+			if current == nil {
+				current = &Hunk{Start: i, End: i}
+			}
+
+			current.Lines = append(current.Lines, b[j])
+			j++
+		}
+	}
+
+	if current != nil {
+		hunks = append(hunks, *current)
+	}
+
+	return hunks
+}
+
+type op int
+
+const (
+	opEqual op = iota
+	opDelete
+	opInsert
+)
+
+// myers returns shortest edit script from a to b, memory stays linear
+// as lines are split at middle snake of each edit path
+func myers(a, b []string) []op {
+	return appendOps(make([]op, 0, len(a)+len(b)), a, b)
+}
+
+// appendOps appends edit script of a to b, common prefix and suffix
+// are kept before searching for middle snake
+func appendOps(ops []op, a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	a, b = a[prefix:], b[prefix:]
+
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	ops = appendOp(ops, opEqual, prefix)
+
+	x, y, ok := middleSnake(a, b)
+
+	switch {
+	case len(a) == 0 || len(b) == 0 || !ok:
+		// nothing in common, lines are replaced
+		ops = appendOp(ops, opDelete, len(a))
+		ops = appendOp(ops, opInsert, len(b))
+	default:
+		ops = appendOps(ops, a[:x], b[:y])
+		ops = appendOps(ops, a[x:], b[y:])
+	}
+
+	return appendOp(ops, opEqual, suffix)
+}
+
+func appendOp(ops []op, o op, count int) []op {
+	for i := 0; i < count; i++ {
+		ops = append(ops, o)
+	}
+
+	return ops
+}
+
+// middleSnake searches furthest reaching paths from start and from end
+// at same time, where they overlap a and b are split, false is returned
+// if a and b have no line in common
+func middleSnake(a, b []string) (int, int, bool) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+
+	for i := range forward {
+		forward[i] = -1
+		backward[i] = -1
+	}
+
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+
+	delta := n - m
+
+	// paths meet while searching from start when delta is odd
+	odd := delta%2 != 0
+
+	// diagonals running out of a or b are no longer searched
+	var forwardStart, forwardEnd, backwardStart, backwardEnd int
+
+	for d := 0; d < maxD; d++ {
+		for k := -d + forwardStart; k <= d-forwardEnd; k += 2 {
+			var x int
+
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+
+			y := x - k
+
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			forward[offset+k] = x
+
+			switch {
+			case x > n:
+				forwardEnd += 2
+			case y > m:
+				forwardStart += 2
+			case odd:
+				i := offset + delta - k
+				if i >= 0 && i < len(backward) && backward[i] != -1 && x >= n-backward[i] {
+					return x, y, true
+				}
+			}
+		}
+
+		for k := -d + backwardStart; k <= d-backwardEnd; k += 2 {
+			var x int
+
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+
+			y := x - k
+
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+
+			backward[offset+k] = x
+
+			switch {
+			case x > n:
+				backwardEnd += 2
+			case y > m:
+				backwardStart += 2
+			case !odd:
+				i := offset + delta - k
+				if i >= 0 && i < len(forward) && forward[i] != -1 && forward[i] >= n-x {
+					return forward[i], forward[i] - (delta - k), true
+				}
+			}
+		}
+	}
+
+	return 0, 0, false
+}
@@ -0,0 +1,218 @@
+package diff_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/fakovacic/editor/internal/app/editor/diff"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLines(t *testing.T) {
+	cases := []struct {
+		it string
+
+		a string
+		b string
+
+		expectedResponse []diff.Hunk
+	}{
+		{
+			it:               "equal",
+			a:                "a\nb\n",
+			b:                "a\nb\n",
+			expectedResponse: []diff.Hunk{},
+		},
+		{
+			it: "insert",
+			a:  "a\nc\n",
+			b:  "a\nb\nc\n",
+			expectedResponse: []diff.Hunk{
+				{Start: 1, End: 1, Lines: []string{"b\n"}},
+			},
+		},
+		{
+			it: "delete",
+			a:  "a\nb\nc\n",
+			b:  "a\nc\n",
+			expectedResponse: []diff.Hunk{
+				{Start: 1, End: 2},
+			},
+		},
+		{
+			it: "replace and append",
+			a:  "a\nb\nc",
+			b:  "a\nB\nc\nd",
+			expectedResponse: []diff.Hunk{
+				{Start: 1, End: 3, Lines: []string{"B\n", "c\n", "d"}},
+			},
+		},
+		{
+			it: "from empty",
+			a:  "",
+			b:  "a\n",
+			expectedResponse: []diff.Hunk{
+				{Start: 0, End: 0, Lines: []string{"a\n"}},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			hunks := diff.Lines(diff.SplitLines(tc.a), diff.SplitLines(tc.b))
+
+			assert.Equal(t, tc.expectedResponse, hunks)
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	cases := []struct {
+		it string
+
+		base   string
+		ours   string
+		theirs string
+
+		expectedResponse string
+		expectedConflict bool
+	}{
+		{
+			it:               "only ours",
+			base:             "a\nb\nc\n",
+			ours:             "a\nB\nc\n",
+			theirs:           "a\nb\nc\n",
+			expectedResponse: "a\nB\nc\n",
+		},
+		{
+			it:               "only theirs",
+			base:             "a\nb\nc\n",
+			ours:             "a\nb\nc\n",
+			theirs:           "a\nb\nc\nd\n",
+			expectedResponse: "a\nb\nc\nd\n",
+		},
+		{
+			it:               "separate lines",
+			base:             "a\nb\nc\nd\ne\n",
+			ours:             "A\nb\nc\nd\ne\n",
+			theirs:           "a\nb\nc\nd\nE\n",
+			expectedResponse: "A\nb\nc\nd\nE\n",
+		},
+		{
+			it:               "same change",
+			base:             "a\nb\nc\n",
+			ours:             "a\nB\nc\n",
+			theirs:           "a\nB\nc\n",
+			expectedResponse: "a\nB\nc\n",
+		},
+		{
+			it:               "ours insert theirs delete",
+			base:             "a\nb\nc\nd\ne\n",
+			ours:             "a\nab\nb\nc\nd\ne\n",
+			theirs:           "a\nb\nc\ne\n",
+			expectedResponse: "a\nab\nb\nc\ne\n",
+		},
+		{
+			it:               "same line changed",
+			base:             "a\nb\nc\n",
+			ours:             "a\nours\nc\n",
+			theirs:           "a\ntheirs\nc\n",
+			expectedConflict: true,
+		},
+		{
+			it:               "adjacent lines changed",
+			base:             "a\nb\nc\n",
+			ours:             "a\nB\nc\n",
+			theirs:           "a\nb\nC\n",
+			expectedConflict: true,
+		},
+		{
+			it:               "deleted and changed",
+			base:             "a\nb\nc\n",
+			ours:             "a\nc\n",
+			theirs:           "a\nB\nc\n",
+			expectedConflict: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			merged, ok := diff.Merge(tc.base, tc.ours, tc.theirs)
+
+			assert.Equal(t, tc.expectedConflict, !ok)
+			assert.Equal(t, tc.expectedResponse, merged)
+		})
+	}
+}
+
+func TestUnified(t *testing.T) {
+	cases := []struct {
+		it string
+
+		a string
+		b string
+
+		expectedResponse string
+	}{
+		{
+			it: "equal",
+			a:  "a\nb\n",
+			b:  "a\nb\n",
+
+			expectedResponse: "",
+		},
+		{
+			it: "separate hunks",
+			a:  "a\nb\nc\nd\ne\nf\ng\nh\n",
+			b:  "a\nB\nc\nd\ne\nf\ng\nh\ni\n",
+
+			expectedResponse: "--- a\n+++ b\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n@@ -8 +8,2 @@\n h\n+i\n",
+		},
+		{
+			it: "near changes share hunk",
+			a:  "a\nb\nc\nd\ne\n",
+			b:  "a\nc\nd\nE\n",
+
+			expectedResponse: "--- a\n+++ b\n@@ -1,5 +1,4 @@\n a\n-b\n c\n d\n-e\n+E\n",
+		},
+		{
+			it: "no new line at end",
+			a:  "a\nb",
+			b:  "a\nc",
+
+			expectedResponse: "--- a\n+++ b\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+		{
+			it: "empty",
+			a:  "",
+			b:  "x\n",
+
+			expectedResponse: "--- a\n+++ b\n@@ -0,0 +1 @@\n+x\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			assert.Equal(t, tc.expectedResponse, diff.Unified("a", "b", tc.a, tc.b, 1))
+		})
+	}
+}
+
+// BenchmarkLinesChanged diffs big file against its fully changed copy
+func BenchmarkLinesChanged(b *testing.B) {
+	contents, err := os.ReadFile("../../../../examples/assets/big.css")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	lines := diff.SplitLines(string(contents))
+	changed := diff.SplitLines(strings.ToUpper(string(contents)))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		diff.Lines(lines, changed)
+	}
+}
@@ -0,0 +1,116 @@
+package diff
+
+import (
+	"sort"
+	"strings"
+)
+
+type sideHunk struct {
+	Hunk
+	theirs bool
+}
+
+// Merge merges changes of ours and theirs made to base line by line,
+// merge fails when both change same or adjacent lines differently
+func Merge(base, ours, theirs string) (string, bool) {
+	baseLines := SplitLines(base)
+
+	hunks := make([]sideHunk, 0)
+
+	for _, h := range Lines(baseLines, SplitLines(ours)) {
+		hunks = append(hunks, sideHunk{Hunk: h})
+	}
+
+	for _, h := range Lines(baseLines, SplitLines(theirs)) {
+		hunks = append(hunks, sideHunk{Hunk: h, theirs: true})
+	}
+
+	sort.SliceStable(hunks, func(i, j int) bool {
+		return hunks[i].Start < hunks[j].Start
+	})
+
+	var merged strings.Builder
+
+	pos := 0
+
+	for i := 0; i < len(hunks); {
+		start, end := hunks[i].Start, hunks[i].End
+
+		j := i + 1
+
+		for ; j < len(hunks) && hunks[j].Start <= end; j++ {
+			if hunks[j].End > end {
+				end = hunks[j].End
+			}
+		}
+
+		region, ok := mergeRegion(baseLines, start, end, hunks[i:j])
+		if !ok {
+			return "", false
+		}
+
+		writeLines(&merged, baseLines[pos:start])
+		writeLines(&merged, region)
+
+		pos = end
+		i = j
+	}
+
+	writeLines(&merged, baseLines[pos:])
+
+	return merged.String(), true
+}
+
+// mergeRegion returns lines of base region with overlapping hunks applied,
+// region changed by both sides merges only if both made same change
+func mergeRegion(base []string, start, end int, hunks []sideHunk) ([]string, bool) {
+	ours, theirs := make([]Hunk, 0), make([]Hunk, 0)
+
+	for _, h := range hunks {
+		if h.theirs {
+			theirs = append(theirs, h.Hunk)
+
+			continue
+		}
+
+		ours = append(ours, h.Hunk)
+	}
+
+	oursLines := applyHunks(base, start, end, ours)
+
+	if len(theirs) == 0 {
+		return oursLines, true
+	}
+
+	theirsLines := applyHunks(base, start, end, theirs)
+
+	if len(ours) == 0 {
+		return theirsLines, true
+	}
+
+	if strings.Join(oursLines, "") != strings.Join(theirsLines, "") {
+		return nil, false
+	}
+
+	return oursLines, true
+}
+
+func applyHunks(base []string, start, end int, hunks []Hunk) []string {
+	lines := make([]string, 0)
+
+	pos := start
+
+	for _, h := range hunks {
+		lines = append(lines, base[pos:h.Start]...)
+		lines = append(lines, h.Lines...)
+		pos = h.End
+	}
+
+	return append(lines, base[pos:end]...)
+}
+
+func writeLines(b *strings.Builder, lines []string) {
+	for _, line := range lines {
+		b.WriteString(line)
+	}
+}
@@ -0,0 +1,94 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Unified returns unified diff of text a named from and text b named to,
+// changes closer than twice context lines share hunk, empty if equal
+func Unified(from, to, a, b string, context int) string {
+	linesA := SplitLines(a)
+	linesB := SplitLines(b)
+
+	hunks := Lines(linesA, linesB)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+
+	out.WriteString("--- " + from + "\n")
+	out.WriteString("+++ " + to + "\n")
+
+	// offset is difference of line numbers in b and a before hunk
+	offset := 0
+
+	for i := 0; i < len(hunks); {
+		j := i
+		for j+1 < len(hunks) && hunks[j+1].Start-hunks[j].End <= 2*context {
+			j++
+		}
+
+		startA := max(hunks[i].Start-context, 0)
+		endA := min(hunks[j].End+context, len(linesA))
+		startB := startA + offset
+
+		var body strings.Builder
+
+		lenB := 0
+		pos := startA
+
+		for _, hunk := range hunks[i : j+1] {
+			for ; pos < hunk.Start; pos++ {
+				writeLine(&body, " ", linesA[pos])
+				lenB++
+			}
+
+			for ; pos < hunk.End; pos++ {
+				writeLine(&body, "-", linesA[pos])
+			}
+
+			for _, line := range hunk.Lines {
+				writeLine(&body, "+", line)
+				lenB++
+			}
+
+			offset += len(hunk.Lines) - (hunk.End - hunk.Start)
+		}
+
+		for ; pos < endA; pos++ {
+			writeLine(&body, " ", linesA[pos])
+			lenB++
+		}
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(startA, endA-startA), hunkRange(startB, lenB))
+		out.WriteString(body.String())
+
+		i = j + 1
+	}
+
+	return out.String()
+}
+
+// hunkRange returns start and length of hunk, start is line before
+// hunk if it is empty
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+func writeLine(b *strings.Builder, prefix, line string) {
+	b.WriteString(prefix + line)
+
+	if !strings.HasSuffix(line, "\n") {
+		b.WriteString("\n\\ No newline at end of file\n")
+	}
+}
@@ -2,10 +2,10 @@ package editor
 
 import (
 	"context"
-	"strings"
 	"sync"
 
 	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor/rope"
 	"github.com/fakovacic/editor/internal/errors"
 	"github.com/fakovacic/editor/internal/log"
 )
@@ -14,6 +14,20 @@ import (
 type IO interface {
 	Read(context.Context) (string, *app.FileMeta, error)
 	Write(context.Context, string, string) error
+
+	// Stat returns fingerprint of current source version, same as read
+	// sets in file meta, empty if io can not tell without reading
+	Stat(context.Context) (string, error)
+}
+
+// Dir manages supported files in directory, names are relative slash paths
+//
+//go:generate moq -out ./mocks/dir.go -pkg mocks  . Dir
+type Dir interface {
+	List(context.Context) ([]app.FileInfo, error)
+	Create(context.Context, string) error
+	Rename(context.Context, string, string) error
+	Delete(context.Context, string) error
 }
 
 func New(io IO) app.Editor {
@@ -29,7 +43,12 @@ type editor struct {
 
 type editorFile struct {
 	Meta     *app.FileMeta
-	Contents string
+	Format   app.FileFormat
+	Original string // io contents, written back as they are if not edited
+	Contents *rope.Rope
+	Revision int
+	History  []*app.ChangeMsg // last applied changes, newest last
+	Saved    app.SaveState    // contents changed since written, revision written
 	sync.Mutex
 }
 
@@ -37,24 +56,46 @@ func (s *editor) FileMeta(_ context.Context) *app.FileMeta {
 	s.file.Lock()
 	defer s.file.Unlock()
 
-	return s.file.Meta
+	return s.file.meta()
 }
 
-func (s *editor) Load(ctx context.Context) error {
-	if s.file.Contents != "" {
+// meta returns copy of file meta with current revision
+func (f *editorFile) meta() *app.FileMeta {
+	if f.Meta == nil {
 		return nil
 	}
 
+	meta := *f.Meta
+	meta.Revision = f.Revision
+	meta.Mode = app.ModeDelta
+	meta.Format = f.Format
+	meta.SaveState = f.Saved
+
+	return &meta
+}
+
+func (s *editor) Load(ctx context.Context) error {
 	s.file.Lock()
 	defer s.file.Unlock()
 
+	if s.file.Contents != nil {
+		return nil
+	}
+
 	contents, meta, err := s.io.Read(ctx)
 	if err != nil {
 		return errors.Wrap(err, "io read")
 	}
 
-	s.file.Contents = strings.ReplaceAll(contents, "\t", "    ")
+	decoded, format := Decode(contents)
+
+	s.file.Contents = rope.New(decoded)
+	s.file.Format = format
+	s.file.Original = contents
 	s.file.Meta = meta
+	s.file.Revision = 0
+	s.file.History = nil
+	s.file.Saved = app.SaveState{}
 
 	return nil
 }
@@ -63,32 +104,96 @@ func (s *editor) Unload(_ context.Context) error {
 	s.file.Lock()
 	defer s.file.Unlock()
 
-	s.file.Contents = ""
+	s.file.Contents = nil
+	s.file.Original = ""
+	s.file.History = nil
 
 	return nil
 }
 
-func (s *editor) Read(_ context.Context) (string, error) {
+func (s *editor) Read(_ context.Context) (string, *app.FileMeta, error) {
 	s.file.Lock()
 	defer s.file.Unlock()
 
-	return s.file.Contents, nil
+	if s.file.Contents == nil {
+		return "", s.file.meta(), nil
+	}
+
+	return s.file.Contents.String(), s.file.meta(), nil
+}
+
+func (s *editor) Encoded(_ context.Context) (string, error) {
+	s.file.Lock()
+	defer s.file.Unlock()
+
+	if s.file.Contents == nil {
+		return "", errors.New("contents not loaded")
+	}
+
+	return Restore(s.file.Contents.String(), s.file.Original), nil
 }
 
-func (s *editor) Write(ctx context.Context) error {
+func (s *editor) Write(ctx context.Context) ([]*app.ChangeMsg, error) {
 	s.file.Lock()
 	defer s.file.Unlock()
 
-	if s.file.Contents == "" {
+	if s.file.Contents == nil || s.file.Contents.Len() == 0 {
 		log.Error(ctx, "contents empty")
 
-		return nil
+		return nil, nil
+	}
+
+	// contents are same as written, no version is saved again
+	if !s.file.Saved.Dirty {
+		return nil, nil
 	}
 
-	err := s.io.Write(ctx, s.file.Meta.Name, s.file.Contents)
+	changes, err := s.sync(ctx)
 	if err != nil {
-		return errors.Wrap(err, "io write")
+		return nil, err
 	}
 
-	return nil
+	contents := Restore(s.file.Contents.String(), s.file.Original)
+
+	err = s.io.Write(ctx, s.file.Meta.Name, contents)
+	if err != nil {
+		return nil, errors.Wrap(err, "io write")
+	}
+
+	s.file.Original = contents
+	s.file.Meta.Fingerprint = Fingerprint(ctx, s.io)
+	s.file.Saved = app.SaveState{
+		SavedRevision: s.file.Revision,
+	}
+
+	return changes, nil
+}
+
+func (s *editor) Reload(ctx context.Context) ([]*app.ChangeMsg, error) {
+	s.file.Lock()
+	defer s.file.Unlock()
+
+	if s.file.Contents == nil {
+		return nil, nil
+	}
+
+	return s.sync(ctx)
+}
+
+func (s *editor) Replace(_ context.Context, contents string) ([]*app.ChangeMsg, error) {
+	s.file.Lock()
+	defer s.file.Unlock()
+
+	if s.file.Contents == nil {
+		return nil, errors.New("contents not loaded")
+	}
+
+	decoded, _ := Decode(contents)
+
+	return s.replace(decoded)
+}
+
+// State is empty, clients join with contents and revision
+func (s *editor) State(_ context.Context) (string, error) {
+	return "", nil
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/fakovacic/editor/internal/app"
 	"github.com/fakovacic/editor/internal/app/editor"
 	"github.com/fakovacic/editor/internal/app/editor/mocks"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestEditor(t *testing.T) {
@@ -23,6 +24,9 @@ func TestEditor(t *testing.T) {
 		WriteFunc: func(ctx context.Context, contents string, name string) error {
 			return nil
 		},
+		StatFunc: func(ctx context.Context) (string, error) {
+			return "", nil
+		},
 	}
 
 	editor := editor.New(io)
@@ -34,7 +38,7 @@ func TestEditor(t *testing.T) {
 	}
 
 	// content must be empty
-	content, err := editor.Read(ctx)
+	content, _, err := editor.Read(ctx)
 	if err != nil {
 		t.Errorf("error must be nil")
 	}
@@ -56,7 +60,7 @@ func TestEditor(t *testing.T) {
 	}
 
 	// content must be set
-	content, err = editor.Read(ctx)
+	content, _, err = editor.Read(ctx)
 	if err != nil {
 		t.Errorf("error must be nil")
 	}
@@ -65,12 +69,50 @@ func TestEditor(t *testing.T) {
 		t.Errorf("content must be set")
 	}
 
+	// replace content, changes bring clients to it
+	changes, err := editor.Replace(ctx, "mock-content\nrestored")
+	if err != nil {
+		t.Errorf("error must be nil")
+	}
+
+	assert.Len(t, changes, 2)
+
+	content, _, err = editor.Read(ctx)
+	if err != nil {
+		t.Errorf("error must be nil")
+	}
+
+	assert.Equal(t, "mock-content\nrestored", content)
+	assert.Equal(t, app.SaveState{Dirty: true}, editor.FileMeta(ctx).SaveState)
+
 	// write content
-	err = editor.Write(ctx)
+	_, err = editor.Write(ctx)
 	if err != nil {
 		t.Errorf("error must be nil")
 	}
 
+	assert.Equal(t, app.SaveState{SavedRevision: 2}, editor.FileMeta(ctx).SaveState)
+
+	// clean contents are not written again
+	_, err = editor.Write(ctx)
+	if err != nil {
+		t.Errorf("error must be nil")
+	}
+
+	assert.Len(t, io.WriteCalls(), 1)
+
+	// empty change leaves contents same as written
+	_, err = editor.Change(ctx, &app.ChangeMsg{
+		Action:   "insert",
+		Lines:    []string{""},
+		Revision: 2,
+	})
+	if err != nil {
+		t.Errorf("error must be nil")
+	}
+
+	assert.Equal(t, app.SaveState{SavedRevision: 2}, editor.FileMeta(ctx).SaveState)
+
 	// unload content
 	err = editor.Unload(ctx)
 	if err != nil {
@@ -78,7 +120,7 @@ func TestEditor(t *testing.T) {
 	}
 
 	// contents must be empty
-	content, err = editor.Read(ctx)
+	content, _, err = editor.Read(ctx)
 	if err != nil {
 		t.Errorf("error must be nil")
 	}
@@ -87,3 +129,270 @@ func TestEditor(t *testing.T) {
 		t.Errorf("content must be empty")
 	}
 }
+
+func TestEditorFormat(t *testing.T) {
+	ctx := context.Background()
+	ioContent := "\uFEFFa {\r\n\tcolor: red;\r\n}\r\n"
+
+	io := &mocks.IOMock{
+		ReadFunc: func(ctx context.Context) (string, *app.FileMeta, error) {
+			return ioContent, &app.FileMeta{
+				Name:      "mock-name",
+				Extension: "mock-extension",
+			}, nil
+		},
+		WriteFunc: func(ctx context.Context, name string, contents string) error {
+			ioContent = contents
+
+			return nil
+		},
+		StatFunc: func(ctx context.Context) (string, error) {
+			return "", nil
+		},
+	}
+
+	editor := editor.New(io)
+
+	err := editor.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, meta, err := editor.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "a {\n\tcolor: red;\n}\n", content)
+	assert.Equal(t, app.FileFormat{
+		IndentTabs:      true,
+		IndentSize:      4,
+		LineEnding:      app.CRLF,
+		TrailingNewline: true,
+		BOM:             true,
+	}, meta.Format)
+
+	// not edited, file is not written
+	_, err = editor.Write(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Empty(t, io.WriteCalls())
+
+	_, err = editor.Change(ctx, &app.ChangeMsg{
+		Action: "insert",
+		Start:  app.ChangeRow{Row: 1, Column: 12},
+		End:    app.ChangeRow{Row: 2, Column: 1},
+		Lines:  []string{"", "\t"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = editor.Write(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "\uFEFFa {\r\n\tcolor: red;\r\n\t\r\n}\r\n", ioContent)
+}
+
+func TestEditorSourceChanged(t *testing.T) {
+	cases := []struct {
+		it string
+
+		source string
+
+		expectedContents string
+		expectedWritten  string
+		expectedChanges  []*app.ChangeMsg
+		expectedError    string
+	}{
+		{
+			it:               "source unchanged",
+			source:           "a\nb\nc\n",
+			expectedContents: "Xa\nb\nc\n",
+			expectedWritten:  "Xa\nb\nc\n",
+		},
+		{
+			it:               "source changed other lines",
+			source:           "a\nb\nc\nd\n",
+			expectedContents: "Xa\nb\nc\nd\n",
+			expectedWritten:  "Xa\nb\nc\nd\n",
+			expectedChanges: []*app.ChangeMsg{
+				{
+					Action:   "insert",
+					Start:    app.ChangeRow{Row: 3},
+					End:      app.ChangeRow{Row: 4},
+					Lines:    []string{"d", ""},
+					Revision: 2,
+				},
+			},
+		},
+		{
+			it:               "source changed format",
+			source:           "a\r\nb\r\nC\r\n",
+			expectedContents: "Xa\nb\nC\n",
+			expectedWritten:  "Xa\r\nb\r\nC\r\n",
+			expectedChanges: []*app.ChangeMsg{
+				{
+					Action:   "remove",
+					Start:    app.ChangeRow{Row: 2},
+					End:      app.ChangeRow{Row: 3},
+					Lines:    []string{"c", ""},
+					Revision: 2,
+				},
+				{
+					Action:   "insert",
+					Start:    app.ChangeRow{Row: 2},
+					End:      app.ChangeRow{Row: 3},
+					Lines:    []string{"C", ""},
+					Revision: 3,
+				},
+			},
+		},
+		{
+			it:               "source changed same line",
+			source:           "Ya\nb\nc\n",
+			expectedContents: "Xa\nb\nc\n",
+			expectedError:    "file 'mock-name' changed on source, edits conflict",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			ctx := context.Background()
+			source, fingerprint, written := "a\nb\nc\n", "1", ""
+
+			io := &mocks.IOMock{
+				ReadFunc: func(ctx context.Context) (string, *app.FileMeta, error) {
+					return source, &app.FileMeta{
+						Name:        "mock-name",
+						Fingerprint: fingerprint,
+					}, nil
+				},
+				WriteFunc: func(ctx context.Context, name string, contents string) error {
+					written = contents
+
+					return nil
+				},
+				StatFunc: func(ctx context.Context) (string, error) {
+					return fingerprint, nil
+				},
+			}
+
+			editor := editor.New(io)
+
+			err := editor.Load(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = editor.Change(ctx, &app.ChangeMsg{
+				Action: "insert",
+				End:    app.ChangeRow{Column: 1},
+				Lines:  []string{"X"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.source != source {
+				source, fingerprint = tc.source, "2"
+			}
+
+			changes, err := editor.Write(ctx)
+			if err != nil {
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+
+			contents, _, err := editor.Read(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.expectedContents, contents)
+			assert.Equal(t, tc.expectedWritten, written)
+			assert.Equal(t, tc.expectedChanges, changes)
+
+			if fingerprint == "1" {
+				assert.Len(t, io.ReadCalls(), 1)
+			}
+
+			// only load is version of file, source read on write is not
+			for i, call := range io.ReadCalls() {
+				assert.Equal(t, i > 0, app.IsSync(call.ContextMoqParam))
+			}
+		})
+	}
+}
+
+func TestEditorReloadSaveState(t *testing.T) {
+	cases := []struct {
+		it string
+
+		edited bool
+
+		expected app.SaveState
+	}{
+		{
+			it:       "source changes only",
+			expected: app.SaveState{SavedRevision: 1},
+		},
+		{
+			it:       "source changes merged with edits",
+			edited:   true,
+			expected: app.SaveState{Dirty: true},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			ctx := context.Background()
+			source := "a\nb\n"
+
+			io := &mocks.IOMock{
+				ReadFunc: func(ctx context.Context) (string, *app.FileMeta, error) {
+					return source, &app.FileMeta{
+						Name: "mock-name",
+					}, nil
+				},
+				StatFunc: func(ctx context.Context) (string, error) {
+					return "", nil
+				},
+			}
+
+			editor := editor.New(io)
+
+			err := editor.Load(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.edited {
+				_, err = editor.Change(ctx, &app.ChangeMsg{
+					Action: "insert",
+					End:    app.ChangeRow{Column: 1},
+					Lines:  []string{"X"},
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			source = "a\nb\nc\n"
+
+			_, err = editor.Reload(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.expected, editor.FileMeta(ctx).SaveState)
+
+			// source read on reload of watched file is no version of it
+			assert.Len(t, io.ReadCalls(), 2)
+			assert.True(t, app.IsSync(io.ReadCalls()[1].ContextMoqParam))
+		})
+	}
+}
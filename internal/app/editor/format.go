@@ -0,0 +1,174 @@
+package editor
+
+import (
+	"strings"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor/diff"
+)
+
+const (
+	bom = "\uFEFF"
+
+	defaultIndentSize = 4
+	maxIndentSize     = 8
+)
+
+// Decode detects file format and returns contents as they are edited,
+// without BOM and with LF line endings
+func Decode(contents string) (string, app.FileFormat) {
+	format := app.FileFormat{
+		LineEnding: app.LF,
+	}
+
+	if strings.HasPrefix(contents, bom) {
+		format.BOM = true
+		contents = contents[len(bom):]
+	}
+
+	crlf := strings.Count(contents, "\r\n")
+	if crlf > strings.Count(contents, "\n")-crlf {
+		format.LineEnding = app.CRLF
+	}
+
+	contents = strings.ReplaceAll(contents, "\r\n", "\n")
+
+	format.TrailingNewline = strings.HasSuffix(contents, "\n")
+	format.IndentTabs, format.IndentSize = detectIndent(contents)
+
+	return contents, format
+}
+
+// Encode returns edited contents in file format
+func Encode(contents string, format app.FileFormat) string {
+	if format.LineEnding == app.CRLF {
+		contents = strings.ReplaceAll(contents, "\n", "\r\n")
+	}
+
+	contents = endLine(contents, format)
+
+	if format.BOM {
+		contents = bom + contents
+	}
+
+	return contents
+}
+
+// Restore returns contents to write, original io contents are kept byte
+// for byte when edited contents are unchanged, unedited lines keep their
+// line endings and edited ones get line ending of file, file ends with
+// new line only if it did
+func Restore(contents, original string) string {
+	decoded, format := Decode(original)
+	if contents == decoded {
+		return original
+	}
+
+	// decoding keeps lines, only their endings are changed
+	originalLines := diff.SplitLines(strings.TrimPrefix(original, bom))
+	hunks := diff.Lines(diff.SplitLines(decoded), diff.SplitLines(contents))
+
+	var b strings.Builder
+
+	if format.BOM {
+		b.WriteString(bom)
+	}
+
+	i := 0
+
+	for _, hunk := range hunks {
+		for ; i < hunk.Start; i++ {
+			b.WriteString(originalLines[i])
+		}
+
+		for _, line := range hunk.Lines {
+			if format.LineEnding == app.CRLF && strings.HasSuffix(line, "\n") {
+				line = strings.TrimSuffix(line, "\n") + "\r\n"
+			}
+
+			b.WriteString(line)
+		}
+
+		i = hunk.End
+	}
+
+	for ; i < len(originalLines); i++ {
+		b.WriteString(originalLines[i])
+	}
+
+	// empty file has no final new line to keep
+	if decoded == "" {
+		return b.String()
+	}
+
+	return endLine(b.String(), format)
+}
+
+// endLine ends last line with new line if file had one and removes it
+// if file had none, edits never change final new line of file
+func endLine(contents string, format app.FileFormat) string {
+	if contents == "" {
+		return contents
+	}
+
+	ended := strings.HasSuffix(contents, "\n")
+
+	switch {
+	case format.TrailingNewline && !ended:
+		return contents + format.LineEnding.Separator()
+	case !format.TrailingNewline && ended:
+		return strings.TrimSuffix(contents[:len(contents)-1], "\r")
+	}
+
+	return contents
+}
+
+// detectIndent returns if most indented lines use tabs and indent size,
+// size is most common increase of spaces between following lines
+func detectIndent(contents string) (bool, int) {
+	var (
+		tabs, spaces int
+		prev         int
+	)
+
+	sizes := make(map[int]int)
+
+	for _, line := range strings.Split(contents, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		switch {
+		case line[0] == '\t':
+			tabs++
+		case indent > 0:
+			spaces++
+
+			// single space is usually comment alignment
+			size := indent - prev
+			if size > 1 && size <= maxIndentSize {
+				sizes[size]++
+			}
+		}
+
+		prev = indent
+	}
+
+	if tabs > spaces {
+		return true, defaultIndentSize
+	}
+
+	size := defaultIndentSize
+	count := 0
+
+	for s := 2; s <= maxIndentSize; s++ {
+		if sizes[s] > count {
+			size = s
+			count = sizes[s]
+		}
+	}
+
+	return false, size
+}
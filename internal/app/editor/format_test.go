@@ -0,0 +1,155 @@
+package editor_test
+
+import (
+	"testing"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	cases := []struct {
+		it string
+
+		contents string
+
+		expectedContents string
+		expectedFormat   app.FileFormat
+	}{
+		{
+			it:               "empty",
+			contents:         "",
+			expectedContents: "",
+			expectedFormat: app.FileFormat{
+				IndentSize: 4,
+				LineEnding: app.LF,
+			},
+		},
+		{
+			it:               "tabs",
+			contents:         "a {\n\tcolor: red;\n\tmargin: 0;\n}\n",
+			expectedContents: "a {\n\tcolor: red;\n\tmargin: 0;\n}\n",
+			expectedFormat: app.FileFormat{
+				IndentTabs:      true,
+				IndentSize:      4,
+				LineEnding:      app.LF,
+				TrailingNewline: true,
+			},
+		},
+		{
+			it:               "two spaces",
+			contents:         "@media print {\n  a {\n    color: red;\n  }\n}",
+			expectedContents: "@media print {\n  a {\n    color: red;\n  }\n}",
+			expectedFormat: app.FileFormat{
+				IndentSize: 2,
+				LineEnding: app.LF,
+			},
+		},
+		{
+			it:               "comment alignment ignored",
+			contents:         "/**\n * comment\n */\na {\n    color: red;\n}",
+			expectedContents: "/**\n * comment\n */\na {\n    color: red;\n}",
+			expectedFormat: app.FileFormat{
+				IndentSize: 4,
+				LineEnding: app.LF,
+			},
+		},
+		{
+			it:               "crlf with bom",
+			contents:         "\uFEFFa {\r\n  color: red;\r\n}\r\n",
+			expectedContents: "a {\n  color: red;\n}\n",
+			expectedFormat: app.FileFormat{
+				IndentSize:      2,
+				LineEnding:      app.CRLF,
+				TrailingNewline: true,
+				BOM:             true,
+			},
+		},
+		{
+			it:               "mixed line endings",
+			contents:         "a {\r\n  color: red;\n}\n",
+			expectedContents: "a {\n  color: red;\n}\n",
+			expectedFormat: app.FileFormat{
+				IndentSize:      2,
+				LineEnding:      app.LF,
+				TrailingNewline: true,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			contents, format := editor.Decode(tc.contents)
+
+			assert.Equal(t, tc.expectedContents, contents)
+			assert.Equal(t, tc.expectedFormat, format)
+		})
+	}
+}
+
+func TestRestore(t *testing.T) {
+	cases := []struct {
+		it string
+
+		contents string
+		original string
+
+		expectedResponse string
+	}{
+		{
+			it:               "unchanged crlf with bom",
+			contents:         "a {\n  color: red;\n}\n",
+			original:         "\uFEFFa {\r\n  color: red;\r\n}\r\n",
+			expectedResponse: "\uFEFFa {\r\n  color: red;\r\n}\r\n",
+		},
+		{
+			it:               "changed crlf with bom",
+			contents:         "a {\n  color: blue;\n}\n",
+			original:         "\uFEFFa {\r\n  color: red;\r\n}\r\n",
+			expectedResponse: "\uFEFFa {\r\n  color: blue;\r\n}\r\n",
+		},
+		{
+			it:               "unchanged mixed line endings",
+			contents:         "a {\n  color: red;\n}\n",
+			original:         "a {\r\n  color: red;\n}\n",
+			expectedResponse: "a {\r\n  color: red;\n}\n",
+		},
+		{
+			it:               "changed mixed line endings",
+			contents:         "a {\n  color: blue;\n  margin: 0;\n}\n",
+			original:         "a {\r\n  color: red;\r\n}\n",
+			expectedResponse: "a {\r\n  color: blue;\r\n  margin: 0;\r\n}\n",
+		},
+		{
+			it:               "no new line at end of file",
+			contents:         "a {\n  color: red;\n}\n",
+			original:         "a {\r\n}",
+			expectedResponse: "a {\r\n  color: red;\r\n}",
+		},
+		{
+			it:               "new line at end of file",
+			contents:         "a {\n  color: red;\n}",
+			original:         "a {\r\n}\r\n",
+			expectedResponse: "a {\r\n  color: red;\r\n}\r\n",
+		},
+		{
+			it:               "empty file",
+			contents:         "a {\n}\n",
+			original:         "",
+			expectedResponse: "a {\n}\n",
+		},
+		{
+			it:               "changed tabs",
+			contents:         "a {\n\tcolor: blue;\n}",
+			original:         "a {\n\tcolor: red;\n}",
+			expectedResponse: "a {\n\tcolor: blue;\n}",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			assert.Equal(t, tc.expectedResponse, editor.Restore(tc.contents, tc.original))
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package file
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor"
+	"github.com/fakovacic/editor/internal/errors"
+)
+
+// NewDir returns files allowed in root and its subdirectories
+func NewDir(root *Root) editor.Dir {
+	return &ioDir{
+		root: root,
+	}
+}
+
+type ioDir struct {
+	root *Root
+}
+
+func (s *ioDir) List(_ context.Context) ([]app.FileInfo, error) {
+	files := make([]app.FileInfo, 0)
+
+	err := filepath.WalkDir(s.root.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == s.root.dir {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.root.dir, path)
+		if err != nil {
+			return err
+		}
+
+		name := filepath.ToSlash(rel)
+
+		// hidden files and dirs like .git are skipped
+		if strings.HasPrefix(entry.Name(), ".") || s.root.excluded(name) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		// symlinks are not followed
+		if !entry.Type().IsRegular() || s.root.allowed(name) != nil {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		file := app.FileInfo{
+			Name: name,
+		}
+
+		err = file.Type.Parse(filepath.Ext(name))
+		if err != nil {
+			return err
+		}
+
+		file.Size = info.Size()
+		file.ModTime = info.ModTime()
+
+		files = append(files, file)
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "walk dir")
+	}
+
+	return files, nil
+}
+
+func (s *ioDir) Create(_ context.Context, name string) error {
+	path, err := s.root.Path(name)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return errors.Wrap(err, "os create dir")
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return errors.BadRequest("file '%s' already exist", name)
+		}
+
+		return errors.Wrap(err, "os create file")
+	}
+
+	err = f.Close()
+	if err != nil {
+		return errors.Wrap(err, "os close file")
+	}
+
+	return nil
+}
+
+func (s *ioDir) Rename(_ context.Context, name, newName string) error {
+	path, err := s.root.Path(name)
+	if err != nil {
+		return err
+	}
+
+	newPath, err := s.root.Path(newName)
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errors.NotFound("file '%s' not found", name)
+		}
+
+		return errors.Wrap(err, "os stat file")
+	}
+
+	_, err = os.Lstat(newPath)
+	if err == nil {
+		return errors.BadRequest("file '%s' already exist", newName)
+	}
+
+	err = os.MkdirAll(filepath.Dir(newPath), 0755)
+	if err != nil {
+		return errors.Wrap(err, "os create dir")
+	}
+
+	err = os.Rename(path, newPath)
+	if err != nil {
+		return errors.Wrap(err, "os rename file")
+	}
+
+	return nil
+}
+
+func (s *ioDir) Delete(_ context.Context, name string) error {
+	path, err := s.root.Path(name)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errors.NotFound("file '%s' not found", name)
+		}
+
+		return errors.Wrap(err, "os remove file")
+	}
+
+	return nil
+}
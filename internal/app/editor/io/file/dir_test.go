@@ -0,0 +1,187 @@
+package file_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor/io/file"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, contents := range map[string]string{
+		"index.html":        "<html></html>",
+		"css/main.css":      "a {}",
+		"js/app/main.js":    "let a;",
+		"readme.md":         "# readme",
+		".git/config.js":    "hidden",
+		"css/.draft.css":    "hidden",
+		"css/print/a.css":   "",
+		"images/logo.svg":   "<svg></svg>",
+		"images/logo.html/": "",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+
+		// names ending with slash are dirs
+		if name[len(name)-1] == '/' {
+			err := os.MkdirAll(path, 0755)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			continue
+		}
+
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(path, []byte(contents), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	root, err := file.NewRoot(dir, nil, []string{"print"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := file.NewDir(root).List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make(map[string]app.FileType)
+
+	for _, f := range files {
+		names[f.Name] = f.Type
+
+		assert.False(t, f.ModTime.IsZero())
+	}
+
+	assert.Equal(t, map[string]app.FileType{
+		"index.html":     app.HTML,
+		"css/main.css":   app.CSS,
+		"js/app/main.js": app.Javascript,
+	}, names)
+}
+
+func TestFileOps(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	root, err := file.NewRoot(dir, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := file.NewDir(root)
+
+	err = files.Create(ctx, "css/landing.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.FileExists(t, filepath.Join(dir, "css", "landing.css"))
+
+	err = files.Create(ctx, "css/landing.css")
+	assert.Equal(t, "file 'css/landing.css' already exist", err.Error())
+
+	err = files.Create(ctx, "main.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = files.Rename(ctx, "css/landing.css", "main.css")
+	assert.Equal(t, "file 'main.css' already exist", err.Error())
+
+	err = files.Rename(ctx, "css/landing.css", "pages/landing.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoFileExists(t, filepath.Join(dir, "css", "landing.css"))
+	assert.FileExists(t, filepath.Join(dir, "pages", "landing.css"))
+
+	err = files.Rename(ctx, "css/landing.css", "landing.css")
+	assert.Equal(t, "file 'css/landing.css' not found", err.Error())
+
+	err = files.Delete(ctx, "pages/landing.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoFileExists(t, filepath.Join(dir, "pages", "landing.css"))
+
+	err = files.Delete(ctx, "pages/landing.css")
+	assert.Equal(t, "file 'pages/landing.css' not found", err.Error())
+}
+
+func TestDirEscape(t *testing.T) {
+	tmp := t.TempDir()
+	ctx := context.Background()
+
+	dir := filepath.Join(tmp, "root")
+	secret := filepath.Join(tmp, "secret.css")
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(secret, []byte("secret"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Symlink(tmp, filepath.Join(dir, "up"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := file.NewRoot(dir, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := file.NewDir(root)
+
+	list, err := files.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Empty(t, list)
+
+	for _, name := range []string{"../secret.css", "up/secret.css", secret} {
+		_, err = file.NewFile(root, name)
+		assert.Error(t, err, name)
+
+		assert.Error(t, files.Create(ctx, name), name)
+		assert.Error(t, files.Delete(ctx, name), name)
+		assert.Error(t, files.Rename(ctx, name, "stolen.css"), name)
+	}
+
+	err = files.Create(ctx, "main.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Error(t, files.Rename(ctx, "main.css", "up/secret.css"))
+	assert.Error(t, files.Rename(ctx, "main.css", "../main.css"))
+
+	contents, err := os.ReadFile(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "secret", string(contents))
+	assert.NoFileExists(t, filepath.Join(tmp, "main.css"))
+}
@@ -2,11 +2,14 @@ package file
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
 	"github.com/fakovacic/editor/internal/app"
 	"github.com/fakovacic/editor/internal/app/editor"
+	"github.com/fakovacic/editor/internal/atomicfile"
 	"github.com/fakovacic/editor/internal/errors"
 )
 
@@ -16,8 +19,31 @@ func New(filepath string) editor.IO {
 	}
 }
 
+// NewFile returns file of root, path is resolved on every read and write
+func NewFile(root *Root, name string) (editor.IO, error) {
+	_, err := root.Path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ioFile{
+		Path: name,
+		root: root,
+	}, nil
+}
+
 type ioFile struct {
 	Path string
+	root *Root
+}
+
+// path returns os path of file
+func (s *ioFile) path() (string, error) {
+	if s.root == nil {
+		return s.Path, nil
+	}
+
+	return s.root.Path(s.Path)
 }
 
 func (s *ioFile) Read(_ context.Context) (string, *app.FileMeta, error) {
@@ -30,18 +56,61 @@ func (s *ioFile) Read(_ context.Context) (string, *app.FileMeta, error) {
 
 	file.Name = filepath.Base(s.Path)
 
-	contents, err := os.ReadFile(s.Path)
+	path, err := s.path()
+	if err != nil {
+		return "", nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "os read file")
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "os stat file")
+	}
+
+	contents, err := io.ReadAll(f)
 	if err != nil {
 		return "", nil, errors.Wrap(err, "os read file")
 	}
 
+	file.Fingerprint = fingerprint(info)
+
 	return string(contents), &file, nil
 }
 
+func (s *ioFile) Stat(_ context.Context) (string, error) {
+	path, err := s.path()
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", errors.Wrap(err, "os stat file")
+	}
+
+	return fingerprint(info), nil
+}
+
+// fingerprint changes when file is written
+func fingerprint(info os.FileInfo) string {
+	return fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size())
+}
+
 func (s *ioFile) Write(_ context.Context, _, content string) error {
-	err := os.WriteFile(s.Path, []byte(content), 0644)
+	path, err := s.path()
+	if err != nil {
+		return err
+	}
+
+	// crash while writing leaves old contents, not truncated file
+	err = atomicfile.WriteFile(path, []byte(content), 0644)
 	if err != nil {
-		return errors.Wrap(err, "os flush file")
+		return errors.Wrap(err, "write file")
 	}
 
 	return nil
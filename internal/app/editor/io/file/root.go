@@ -0,0 +1,181 @@
+package file
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/errors"
+)
+
+// Root is sandboxed directory, files are addressed by names relative to
+// it and no name resolves to path outside of it, symlinks included
+type Root struct {
+	dir     string
+	include []string
+	exclude []string
+}
+
+// NewRoot returns root of directory, when include patterns are set only
+// matching files are allowed, files matching exclude patterns never are.
+// Pattern without slash matches any path element as "*.min.js" or
+// "node_modules", with slash it matches whole name and "**" matches any
+// number of dirs as "js/**/*.js".
+func NewRoot(dir string, include, exclude []string) (*Root, error) {
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return nil, errors.BadRequest("invalid pattern '%s'", pattern)
+		}
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "root path")
+	}
+
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, errors.Wrap(err, "root path")
+	}
+
+	return &Root{
+		dir:     resolved,
+		include: include,
+		exclude: exclude,
+	}, nil
+}
+
+// Path returns os path of file in root, name must be local slash path of
+// supported file type allowed by patterns, existing part of path must not
+// resolve outside of root
+func (r *Root) Path(name string) (string, error) {
+	if name == "" {
+		return "", errors.BadRequest("file name empty")
+	}
+
+	if strings.Contains(name, "\\") || !filepath.IsLocal(name) {
+		return "", errors.BadRequest("file '%s' outside root", name)
+	}
+
+	name = path.Clean(name)
+
+	err := r.allowed(name)
+	if err != nil {
+		return "", err
+	}
+
+	p := filepath.Join(r.dir, filepath.FromSlash(name))
+
+	err = r.contains(p)
+	if err != nil {
+		return "", errors.Wrap(err, "file '%s'", name)
+	}
+
+	return p, nil
+}
+
+// allowed checks file type and patterns of cleaned name
+func (r *Root) allowed(name string) error {
+	var fileType app.FileType
+
+	err := fileType.Parse(path.Ext(name))
+	if err != nil {
+		return errors.Wrap(err, "file '%s'", name)
+	}
+
+	if r.excluded(name) {
+		return errors.BadRequest("file '%s' excluded", name)
+	}
+
+	if len(r.include) == 0 {
+		return nil
+	}
+
+	for _, pattern := range r.include {
+		if match(pattern, name) {
+			return nil
+		}
+	}
+
+	return errors.BadRequest("file '%s' not included", name)
+}
+
+func (r *Root) excluded(name string) bool {
+	for _, pattern := range r.exclude {
+		if match(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// contains checks that longest existing part of path resolves inside root,
+// missing rest of path is created inside it
+func (r *Root) contains(p string) error {
+	for dir := p; ; dir = filepath.Dir(dir) {
+		resolved, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			if os.IsNotExist(err) && dir != r.dir {
+				continue
+			}
+
+			return errors.Wrap(err, "resolve path")
+		}
+
+		rel, err := filepath.Rel(r.dir, resolved)
+		if err != nil || !(rel == "." || filepath.IsLocal(rel)) {
+			return errors.BadRequest("symlink outside root")
+		}
+
+		return nil
+	}
+}
+
+// match reports if pattern matches slash separated name
+func match(pattern, name string) bool {
+	segments := strings.Split(name, "/")
+
+	if !strings.Contains(pattern, "/") {
+		for _, segment := range segments {
+			ok, _ := path.Match(pattern, segment)
+			if ok {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), segments)
+}
+
+func matchSegments(patterns, segments []string) bool {
+	if len(patterns) == 0 {
+		return len(segments) == 0
+	}
+
+	if patterns[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(patterns[1:], segments[i:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+
+	ok, _ := path.Match(patterns[0], segments[0])
+	if !ok {
+		return false
+	}
+
+	return matchSegments(patterns[1:], segments[1:])
+}
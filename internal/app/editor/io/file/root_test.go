@@ -0,0 +1,174 @@
+package file_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fakovacic/editor/internal/app/editor/io/file"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRootPath(t *testing.T) {
+	tmp, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(tmp, "root")
+	outside := filepath.Join(tmp, "outside")
+
+	for _, d := range []string{filepath.Join(dir, "css"), outside} {
+		err = os.MkdirAll(d, 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, f := range []string{filepath.Join(dir, "css", "main.css"), filepath.Join(outside, "secret.css")} {
+		err = os.WriteFile(f, []byte("a {}"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for link, target := range map[string]string{
+		"link-out":      outside,
+		"link-file.css": filepath.Join(outside, "secret.css"),
+		"link-in.css":   filepath.Join(dir, "css", "main.css"),
+	} {
+		err = os.Symlink(target, filepath.Join(dir, link))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		it string
+
+		include []string
+		exclude []string
+		name    string
+
+		expectedResponse string
+		expectedError    string
+	}{
+		{
+			it:               "existing file",
+			name:             "css/main.css",
+			expectedResponse: "css/main.css",
+		},
+		{
+			it:               "new file in new dir",
+			name:             "css/pages/home.css",
+			expectedResponse: "css/pages/home.css",
+		},
+		{
+			it:               "symlink inside root",
+			name:             "link-in.css",
+			expectedResponse: "link-in.css",
+		},
+		{
+			it:            "empty name",
+			name:          "",
+			expectedError: "file name empty",
+		},
+		{
+			it:            "parent dir",
+			name:          "../outside/secret.css",
+			expectedError: "file '../outside/secret.css' outside root",
+		},
+		{
+			it:            "parent dir after clean",
+			name:          "css/../../outside/secret.css",
+			expectedError: "file 'css/../../outside/secret.css' outside root",
+		},
+		{
+			it:            "absolute path",
+			name:          filepath.Join(outside, "secret.css"),
+			expectedError: "file '" + filepath.Join(outside, "secret.css") + "' outside root",
+		},
+		{
+			it:            "backslash",
+			name:          `css\..\..\outside\secret.css`,
+			expectedError: `file 'css\..\..\outside\secret.css' outside root`,
+		},
+		{
+			it:            "symlinked dir outside root",
+			name:          "link-out/secret.css",
+			expectedError: "file 'link-out/secret.css': symlink outside root",
+		},
+		{
+			it:            "new file in symlinked dir outside root",
+			name:          "link-out/new.css",
+			expectedError: "file 'link-out/new.css': symlink outside root",
+		},
+		{
+			it:            "symlinked file outside root",
+			name:          "link-file.css",
+			expectedError: "file 'link-file.css': symlink outside root",
+		},
+		{
+			it:            "unknown extension",
+			name:          "css/notes.txt",
+			expectedError: "file 'css/notes.txt': invalid file type '.txt'",
+		},
+		{
+			it:            "excluded by name",
+			exclude:       []string{"*.min.js"},
+			name:          "js/app.min.js",
+			expectedError: "file 'js/app.min.js' excluded",
+		},
+		{
+			it:            "excluded by dir",
+			exclude:       []string{"vendor"},
+			name:          "js/vendor/lib.js",
+			expectedError: "file 'js/vendor/lib.js' excluded",
+		},
+		{
+			it:               "included",
+			include:          []string{"css/**"},
+			name:             "css/pages/home.css",
+			expectedResponse: "css/pages/home.css",
+		},
+		{
+			it:            "not included",
+			include:       []string{"css/**"},
+			name:          "js/app.js",
+			expectedError: "file 'js/app.js' not included",
+		},
+		{
+			it:            "included and excluded",
+			include:       []string{"css/**/*.css"},
+			exclude:       []string{"css/print/*"},
+			name:          "css/print/main.css",
+			expectedError: "file 'css/print/main.css' excluded",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			root, err := file.NewRoot(dir, tc.include, tc.exclude)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p, err := root.Path(tc.name)
+			if err != nil {
+				assert.Equal(t, tc.expectedError, err.Error())
+				assert.Empty(t, tc.expectedResponse)
+
+				return
+			}
+
+			assert.Empty(t, tc.expectedError)
+			assert.Equal(t, filepath.Join(dir, filepath.FromSlash(tc.expectedResponse)), p)
+		})
+	}
+}
+
+func TestNewRootInvalidPattern(t *testing.T) {
+	_, err := file.NewRoot(t.TempDir(), []string{"css/["}, nil)
+
+	assert.Equal(t, "invalid pattern 'css/['", err.Error())
+}
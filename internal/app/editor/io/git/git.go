@@ -0,0 +1,109 @@
+package git
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor"
+	"github.com/fakovacic/editor/internal/git"
+)
+
+// New returns io of file at path in working tree of repo, every write
+// is committed with usernames of clients in context as authors
+func New(repo *git.Repo, io editor.IO, path string) editor.IO {
+	return &ioGit{
+		repo: repo,
+		next: io,
+		path: path,
+	}
+}
+
+type ioGit struct {
+	repo *git.Repo
+	next editor.IO
+	path string
+}
+
+func (s *ioGit) Read(ctx context.Context) (string, *app.FileMeta, error) {
+	return s.next.Read(ctx)
+}
+
+func (s *ioGit) Stat(ctx context.Context) (string, error) {
+	return s.next.Stat(ctx)
+}
+
+func (s *ioGit) Write(ctx context.Context, filename, content string) error {
+	err := s.next.Write(ctx, filename, content)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Commit(ctx,
+		fmt.Sprintf("Update %s", filename),
+		app.GetSaveInfo(ctx).Authors,
+		s.path,
+	)
+}
+
+// NewDir returns dir in working tree of repo, created, renamed and
+// deleted files are committed, path returns os path of file name
+func NewDir(repo *git.Repo, dir editor.Dir, path func(string) (string, error)) editor.Dir {
+	return &dirGit{
+		repo: repo,
+		next: dir,
+		path: path,
+	}
+}
+
+type dirGit struct {
+	repo *git.Repo
+	next editor.Dir
+	path func(string) (string, error)
+}
+
+func (s *dirGit) List(ctx context.Context) ([]app.FileInfo, error) {
+	return s.next.List(ctx)
+}
+
+func (s *dirGit) Create(ctx context.Context, name string) error {
+	err := s.next.Create(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	return s.commit(ctx, fmt.Sprintf("Create %s", name), name)
+}
+
+func (s *dirGit) Rename(ctx context.Context, name, newName string) error {
+	err := s.next.Rename(ctx, name, newName)
+	if err != nil {
+		return err
+	}
+
+	return s.commit(ctx, fmt.Sprintf("Rename %s to %s", name, newName), name, newName)
+}
+
+func (s *dirGit) Delete(ctx context.Context, name string) error {
+	err := s.next.Delete(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	return s.commit(ctx, fmt.Sprintf("Delete %s", name), name)
+}
+
+func (s *dirGit) commit(ctx context.Context, message string, names ...string) error {
+	paths := make([]string, 0, len(names))
+
+	for _, name := range names {
+		path, err := s.path(name)
+		if err != nil {
+			return err
+		}
+
+		paths = append(paths, path)
+	}
+
+	return s.repo.Commit(ctx, message, app.GetSaveInfo(ctx).Authors, paths...)
+}
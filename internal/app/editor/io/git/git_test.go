@@ -0,0 +1,96 @@
+package git_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fakovacic/editor/internal/app"
+	ioFile "github.com/fakovacic/editor/internal/app/editor/io/file"
+	ioGit "github.com/fakovacic/editor/internal/app/editor/io/git"
+	"github.com/fakovacic/editor/internal/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommits(t *testing.T) {
+	_, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+
+	out, err := exec.Command("git", "-C", dir, "init", "--quiet").CombinedOutput()
+	if err != nil {
+		t.Fatalf("git init: %v %s", err, out)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "main.css"), []byte("a {}"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := app.WithSaveInfo(context.Background(), app.SaveInfo{
+		Authors: []string{"alice", "bob"},
+	})
+
+	repo, err := git.Open(ctx, dir, git.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := ioFile.NewRoot(dir, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := root.Path("main.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileIO, err := ioFile.NewFile(root, "main.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ioGit.New(repo, fileIO, path).Write(ctx, "main.css", "b {}")
+	assert.NoError(t, err)
+
+	wsDir := ioGit.NewDir(repo, ioFile.NewDir(root), root.Path)
+
+	err = wsDir.Create(ctx, "css/new.css")
+	assert.NoError(t, err)
+
+	err = wsDir.Rename(ctx, "main.css", "css/main.css")
+	assert.NoError(t, err)
+
+	err = wsDir.Delete(ctx, "css/new.css")
+	assert.NoError(t, err)
+
+	// failed ops are not committed
+	err = wsDir.Create(ctx, "css/main.css")
+	assert.Equal(t, "file 'css/main.css' already exist", err.Error())
+
+	out, err = exec.Command("git", "-C", dir, "log", "--format=%an|%s|%(trailers:key=Co-authored-by,valueonly,separator=)").CombinedOutput()
+	if err != nil {
+		t.Fatalf("git log: %v %s", err, out)
+	}
+
+	assert.Equal(t, []string{
+		"alice|Delete css/new.css|bob <bob@editor.local>",
+		"alice|Rename main.css to css/main.css|bob <bob@editor.local>",
+		"alice|Create css/new.css|bob <bob@editor.local>",
+		"alice|Update main.css|bob <bob@editor.local>",
+	}, strings.Split(strings.TrimSpace(string(out)), "\n"))
+
+	out, err = exec.Command("git", "-C", dir, "status", "--porcelain").CombinedOutput()
+	if err != nil {
+		t.Fatalf("git status: %v %s", err, out)
+	}
+
+	assert.Empty(t, string(out))
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/fakovacic/editor/internal/errors"
+)
+
+// Config of requests to server, file contents are written with Method
+// and Header is sent with every request
+type Config struct {
+	Method Method
+	Header http.Header
+}
+
+// Method is http method file contents are written with
+type Method string
+
+const (
+	MethodPost  Method = http.MethodPost
+	MethodPut   Method = http.MethodPut
+	MethodPatch Method = http.MethodPatch
+)
+
+func (t Method) String() string {
+	return string(t)
+}
+
+func (t *Method) Parse(s string) error {
+	s = strings.ToUpper(strings.Trim(s, "\""))
+	switch s {
+	case "POST":
+		*t = MethodPost
+	case "PUT":
+		*t = MethodPut
+	case "PATCH":
+		*t = MethodPatch
+	default:
+		return errors.BadRequest("invalid http method '%s'", s)
+	}
+
+	return nil
+}
+
+// ParseHeader parses header lines as "Authorization: Bearer token"
+func ParseHeader(lines []string) (http.Header, error) {
+	header := make(http.Header)
+
+	for _, line := range lines {
+		key, value, ok := strings.Cut(line, ":")
+
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, errors.BadRequest("invalid header '%s'", line)
+		}
+
+		header.Add(key, strings.TrimSpace(value))
+	}
+
+	return header, nil
+}
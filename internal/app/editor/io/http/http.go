@@ -2,10 +2,14 @@ package http
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
+	"net/url"
+	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/fakovacic/editor/internal/app"
 	"github.com/fakovacic/editor/internal/app/editor"
@@ -16,16 +20,78 @@ type httpClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
-func New(filepath string, client httpClient) editor.IO {
+// New returns file at url, contents are written with config method,
+// POST if not set, only if file was not changed since it was read
+func New(filepath string, client httpClient, config Config) editor.IO {
+	if config.Method == "" {
+		config.Method = MethodPost
+	}
+
 	return &ioHTTP{
 		path:   filepath,
 		client: client,
+		config: config,
+	}
+}
+
+// NewDir returns files under directory url, server must return json
+// index of directory as nginx with autoindex_format json and accept
+// PUT, MOVE and DELETE as nginx with dav_methods
+func NewDir(dirpath string, client httpClient, config Config) editor.Dir {
+	return &ioHTTP{
+		path:   dirpath,
+		client: client,
+		config: config,
 	}
 }
 
 type ioHTTP struct {
-	client httpClient
-	path   string
+	client  httpClient
+	path    string
+	config  Config
+	version version
+}
+
+// version of file last read or written, write is conditional on it
+type version struct {
+	etag         string
+	lastModified string
+	sync.Mutex
+}
+
+func (v *version) set(header http.Header) {
+	v.Lock()
+	defer v.Unlock()
+
+	v.etag = header.Get("ETag")
+	v.lastModified = header.Get("Last-Modified")
+}
+
+// precondition sets write condition, weak etag never matches If-Match
+func (v *version) precondition(header http.Header) {
+	v.Lock()
+	defer v.Unlock()
+
+	switch {
+	case v.etag != "" && !strings.HasPrefix(v.etag, "W/"):
+		header.Set("If-Match", v.etag)
+	case v.lastModified != "":
+		header.Set("If-Unmodified-Since", v.lastModified)
+	}
+}
+
+// newRequest returns request with config headers
+func (s *ioHTTP) newRequest(ctx context.Context, method, target string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range s.config.Header {
+		req.Header[key] = values
+	}
+
+	return req, nil
 }
 
 func (s *ioHTTP) Read(ctx context.Context) (string, *app.FileMeta, error) {
@@ -38,7 +104,7 @@ func (s *ioHTTP) Read(ctx context.Context) (string, *app.FileMeta, error) {
 
 	file.Name = filepath.Base(s.path)
 
-	req, err := http.NewRequestWithContext(ctx,
+	req, err := s.newRequest(ctx,
 		http.MethodGet,
 		s.path,
 		http.NoBody,
@@ -62,12 +128,50 @@ func (s *ioHTTP) Read(ctx context.Context) (string, *app.FileMeta, error) {
 		return "", nil, errors.New("status code not equal 200: %v", resp.StatusCode)
 	}
 
+	s.version.set(resp.Header)
+
+	file.Fingerprint = fingerprint(resp.Header)
+
 	return string(body), &file, nil
 }
 
-func (s *ioHTTP) Write(ctx context.Context, _, content string) error {
-	req, err := http.NewRequestWithContext(ctx,
-		http.MethodPost,
+func (s *ioHTTP) Stat(ctx context.Context) (string, error) {
+	req, err := s.newRequest(ctx,
+		http.MethodHead,
+		s.path,
+		http.NoBody,
+	)
+	if err != nil {
+		return "", errors.New("create http request: %v", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", errors.New("http request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.New("status code not equal 200: %v", resp.StatusCode)
+	}
+
+	return fingerprint(resp.Header), nil
+}
+
+// fingerprint is etag or last modified time of response, empty if
+// server sends neither
+func fingerprint(header http.Header) string {
+	etag := header.Get("ETag")
+	if etag != "" {
+		return etag
+	}
+
+	return header.Get("Last-Modified")
+}
+
+func (s *ioHTTP) Write(ctx context.Context, filename, content string) error {
+	req, err := s.newRequest(ctx,
+		s.config.Method.String(),
 		s.path,
 		strings.NewReader(content),
 	)
@@ -75,6 +179,65 @@ func (s *ioHTTP) Write(ctx context.Context, _, content string) error {
 		return errors.New("create http request: %v", err)
 	}
 
+	s.version.precondition(req.Header)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.New("http request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return errors.Conflict("file '%s' changed on source since read", filename)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.New("status code not success: %v", resp.StatusCode)
+	}
+
+	// without validators in response next write is not conditional
+	s.version.set(resp.Header)
+
+	return nil
+}
+
+// indexEntry is entry of nginx json autoindex
+type indexEntry struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	MTime string `json:"mtime"`
+	Size  int64  `json:"size"`
+}
+
+func (s *ioHTTP) List(ctx context.Context) ([]app.FileInfo, error) {
+	files := make([]app.FileInfo, 0)
+
+	err := s.list(ctx, "", &files)
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// list appends files of directory dir relative to root and walks subdirectories
+func (s *ioHTTP) list(ctx context.Context, dir string, files *[]app.FileInfo) error {
+	dirURL, err := url.JoinPath(s.path, dir, "/")
+	if err != nil {
+		return errors.New("directory url: %v", err)
+	}
+
+	req, err := s.newRequest(ctx,
+		http.MethodGet,
+		dirURL,
+		http.NoBody,
+	)
+	if err != nil {
+		return errors.New("create http request: %v", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
 	resp, err := s.client.Do(req)
 	if err != nil {
 		return errors.New("http request: %v", err)
@@ -85,5 +248,147 @@ func (s *ioHTTP) Write(ctx context.Context, _, content string) error {
 		return errors.New("status code not equal 200: %v", resp.StatusCode)
 	}
 
+	var entries []indexEntry
+
+	err = json.NewDecoder(resp.Body).Decode(&entries)
+	if err != nil {
+		return errors.New("decode directory index: %v", err)
+	}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name, ".") || strings.Contains(entry.Name, "/") {
+			continue
+		}
+
+		name := path.Join(dir, entry.Name)
+
+		switch entry.Type {
+		case "directory":
+			err = s.list(ctx, name, files)
+			if err != nil {
+				return err
+			}
+		case "file":
+			var file app.FileInfo
+
+			parseErr := file.Type.Parse(path.Ext(name))
+			if parseErr != nil {
+				continue
+			}
+
+			file.Name = name
+			file.Size = entry.Size
+
+			modTime, timeErr := http.ParseTime(entry.MTime)
+			if timeErr == nil {
+				file.ModTime = modTime
+			}
+
+			*files = append(*files, file)
+		}
+	}
+
 	return nil
 }
+
+func (s *ioHTTP) Create(ctx context.Context, name string) error {
+	fileURL, err := url.JoinPath(s.path, name)
+	if err != nil {
+		return errors.New("file url: %v", err)
+	}
+
+	// existing file is not overwritten
+	status, err := s.do(ctx, http.MethodPut, fileURL, map[string]string{
+		"If-None-Match": "*",
+	})
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	case http.StatusPreconditionFailed:
+		return errors.BadRequest("file '%s' already exist", name)
+	default:
+		return errors.New("status code not success: %v", status)
+	}
+}
+
+func (s *ioHTTP) Rename(ctx context.Context, name, newName string) error {
+	fileURL, err := url.JoinPath(s.path, name)
+	if err != nil {
+		return errors.New("file url: %v", err)
+	}
+
+	newURL, err := url.JoinPath(s.path, newName)
+	if err != nil {
+		return errors.New("file url: %v", err)
+	}
+
+	status, err := s.do(ctx, "MOVE", fileURL, map[string]string{
+		"Destination": newURL,
+		"Overwrite":   "F",
+	})
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case http.StatusCreated, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return errors.NotFound("file '%s' not found", name)
+	case http.StatusPreconditionFailed:
+		return errors.BadRequest("file '%s' already exist", newName)
+	default:
+		return errors.New("status code not success: %v", status)
+	}
+}
+
+func (s *ioHTTP) Delete(ctx context.Context, name string) error {
+	fileURL, err := url.JoinPath(s.path, name)
+	if err != nil {
+		return errors.New("file url: %v", err)
+	}
+
+	status, err := s.do(ctx, http.MethodDelete, fileURL, nil)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return errors.NotFound("file '%s' not found", name)
+	default:
+		return errors.New("status code not success: %v", status)
+	}
+}
+
+// do sends request without body and returns response status code
+func (s *ioHTTP) do(ctx context.Context, method, target string, headers map[string]string) (int, error) {
+	req, err := s.newRequest(ctx,
+		method,
+		target,
+		http.NoBody,
+	)
+	if err != nil {
+		return 0, errors.New("create http request: %v", err)
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, errors.New("http request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
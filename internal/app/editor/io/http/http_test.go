@@ -0,0 +1,415 @@
+package http_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor"
+	ioHttp "github.com/fakovacic/editor/internal/app/editor/io/http"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestList(t *testing.T) {
+	// nginx json autoindex
+	index := map[string]string{
+		"/assets/": `[
+			{"name":"css","type":"directory","mtime":"Wed, 18 Oct 2023 11:22:33 GMT"},
+			{"name":"index.html","type":"file","mtime":"Wed, 18 Oct 2023 11:22:33 GMT","size":13},
+			{"name":"logo.svg","type":"file","mtime":"Wed, 18 Oct 2023 11:22:33 GMT","size":120},
+			{"name":".hidden.css","type":"file","mtime":"Wed, 18 Oct 2023 11:22:33 GMT","size":1}
+		]`,
+		"/assets/css/": `[
+			{"name":"main.css","type":"file","mtime":"Thu, 19 Oct 2023 08:00:00 GMT","size":4}
+		]`,
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := index[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		it string
+
+		path string
+
+		expectedResponse []app.FileInfo
+		expectedError    string
+	}{
+		{
+			it:   "walk directories",
+			path: srv.URL + "/assets/",
+			expectedResponse: []app.FileInfo{
+				{
+					Name:    "css/main.css",
+					Size:    4,
+					ModTime: time.Date(2023, 10, 19, 8, 0, 0, 0, time.UTC),
+					Type:    app.CSS,
+				},
+				{
+					Name:    "index.html",
+					Size:    13,
+					ModTime: time.Date(2023, 10, 18, 11, 22, 33, 0, time.UTC),
+					Type:    app.HTML,
+				},
+			},
+		},
+		{
+			it:            "directory not found",
+			path:          srv.URL + "/missing/",
+			expectedError: "status code not equal 200: 404",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			files, err := ioHttp.NewDir(tc.path, srv.Client(), ioHttp.Config{}).List(context.Background())
+			if err != nil {
+				assert.Equal(t, tc.expectedError, err.Error())
+
+				return
+			}
+
+			assert.Equal(t, tc.expectedResponse, files)
+		})
+	}
+}
+
+func TestFileOps(t *testing.T) {
+	var requests []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+r.Header.Get("If-None-Match")+r.Header.Get("Destination"))
+
+		switch r.URL.Path {
+		case "/assets/exist.css":
+			w.WriteHeader(http.StatusPreconditionFailed)
+		case "/assets/missing.css":
+			w.WriteHeader(http.StatusNotFound)
+		case "/assets/main.css":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		it string
+
+		op func(dir editor.Dir) error
+
+		expectedRequest string
+		expectedError   string
+	}{
+		{
+			it: "create",
+			op: func(dir editor.Dir) error {
+				return dir.Create(context.Background(), "css/landing.css")
+			},
+			expectedRequest: "PUT /assets/css/landing.css *",
+		},
+		{
+			it: "create existing",
+			op: func(dir editor.Dir) error {
+				return dir.Create(context.Background(), "exist.css")
+			},
+			expectedRequest: "PUT /assets/exist.css *",
+			expectedError:   "file 'exist.css' already exist",
+		},
+		{
+			it: "rename",
+			op: func(dir editor.Dir) error {
+				return dir.Rename(context.Background(), "main.css", "landing.css")
+			},
+			expectedRequest: "MOVE /assets/main.css " + srv.URL + "/assets/landing.css",
+		},
+		{
+			it: "rename missing",
+			op: func(dir editor.Dir) error {
+				return dir.Rename(context.Background(), "missing.css", "landing.css")
+			},
+			expectedRequest: "MOVE /assets/missing.css " + srv.URL + "/assets/landing.css",
+			expectedError:   "file 'missing.css' not found",
+		},
+		{
+			it: "delete",
+			op: func(dir editor.Dir) error {
+				return dir.Delete(context.Background(), "main.css")
+			},
+			expectedRequest: "DELETE /assets/main.css ",
+		},
+		{
+			it: "delete missing",
+			op: func(dir editor.Dir) error {
+				return dir.Delete(context.Background(), "missing.css")
+			},
+			expectedRequest: "DELETE /assets/missing.css ",
+			expectedError:   "file 'missing.css' not found",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			requests = nil
+
+			err := tc.op(ioHttp.NewDir(srv.URL+"/assets/", srv.Client(), ioHttp.Config{}))
+			if err != nil {
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+
+			assert.Equal(t, []string{tc.expectedRequest}, requests)
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/etag.css":
+			w.Header().Set("ETag", `"5f1a-1c"`)
+			w.Header().Set("Last-Modified", "Wed, 18 Oct 2023 11:22:33 GMT")
+		case "/modified.css":
+			w.Header().Set("Last-Modified", "Wed, 18 Oct 2023 11:22:33 GMT")
+		}
+
+		_, _ = w.Write([]byte("a {}"))
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		it string
+
+		path string
+
+		expectedResponse string
+	}{
+		{
+			it:               "etag",
+			path:             "/etag.css",
+			expectedResponse: `"5f1a-1c"`,
+		},
+		{
+			it:               "last modified",
+			path:             "/modified.css",
+			expectedResponse: "Wed, 18 Oct 2023 11:22:33 GMT",
+		},
+		{
+			it:               "unknown",
+			path:             "/plain.css",
+			expectedResponse: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			io := ioHttp.New(srv.URL+tc.path, srv.Client(), ioHttp.Config{})
+
+			_, meta, err := io.Read(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fingerprint, err := io.Stat(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.expectedResponse, meta.Fingerprint)
+			assert.Equal(t, tc.expectedResponse, fingerprint)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var (
+		requests []string
+		version  int
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+r.Header.Get("Authorization")+" "+r.Header.Get("If-Match")+r.Header.Get("If-Unmodified-Since"))
+
+		switch r.URL.Path {
+		case "/etag.css":
+			etag := fmt.Sprintf(`"v%d"`, version)
+
+			if r.Method != http.MethodGet {
+				if r.Header.Get("If-Match") != etag {
+					w.WriteHeader(http.StatusPreconditionFailed)
+
+					return
+				}
+
+				version++
+
+				w.Header().Set("ETag", fmt.Sprintf(`"v%d"`, version))
+				w.WriteHeader(http.StatusCreated)
+
+				return
+			}
+
+			w.Header().Set("ETag", etag)
+		case "/weak.css":
+			w.Header().Set("ETag", `W/"v1"`)
+			w.Header().Set("Last-Modified", "Wed, 18 Oct 2023 11:22:33 GMT")
+		case "/changed.css":
+			if r.Method == http.MethodGet {
+				w.Header().Set("ETag", `"v1"`)
+
+				break
+			}
+
+			w.WriteHeader(http.StatusPreconditionFailed)
+		case "/error.css":
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		it string
+
+		path   string
+		config ioHttp.Config
+
+		expectedRequests []string
+		expectedError    string
+		expectedConflict bool
+	}{
+		{
+			it:   "default method without validators",
+			path: "/plain.css",
+			expectedRequests: []string{
+				"GET /plain.css  ",
+				"POST /plain.css  ",
+				"POST /plain.css  ",
+			},
+		},
+		{
+			it:   "etag of read and write",
+			path: "/etag.css",
+			config: ioHttp.Config{
+				Method: ioHttp.MethodPut,
+				Header: http.Header{
+					"Authorization": []string{"Bearer token"},
+				},
+			},
+			expectedRequests: []string{
+				`GET /etag.css Bearer token `,
+				`PUT /etag.css Bearer token "v1"`,
+				`PUT /etag.css Bearer token "v2"`,
+			},
+		},
+		{
+			it:   "weak etag uses last modified",
+			path: "/weak.css",
+			config: ioHttp.Config{
+				Method: ioHttp.MethodPatch,
+			},
+			expectedRequests: []string{
+				"GET /weak.css  ",
+				"PATCH /weak.css  Wed, 18 Oct 2023 11:22:33 GMT",
+				"PATCH /weak.css  Wed, 18 Oct 2023 11:22:33 GMT",
+			},
+		},
+		{
+			it:   "changed since read",
+			path: "/changed.css",
+			expectedRequests: []string{
+				"GET /changed.css  ",
+				`POST /changed.css  "v1"`,
+			},
+			expectedError:    "file 'changed.css' changed on source since read",
+			expectedConflict: true,
+		},
+		{
+			it:   "server error",
+			path: "/error.css",
+			expectedRequests: []string{
+				"GET /error.css  ",
+				"POST /error.css  ",
+			},
+			expectedError: "status code not success: 500",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			requests = nil
+			version = 1
+
+			io := ioHttp.New(srv.URL+tc.path, srv.Client(), tc.config)
+
+			_, meta, err := io.Read(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// second write is conditional on first one
+			for i := 0; i < 2 && err == nil; i++ {
+				err = io.Write(context.Background(), meta.Name, "a {}")
+			}
+
+			if err != nil {
+				assert.Equal(t, tc.expectedError, err.Error())
+				assert.Equal(t, tc.expectedConflict, errors.IsConflict(err))
+			}
+
+			assert.Equal(t, tc.expectedRequests, requests)
+		})
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	cases := []struct {
+		it string
+
+		lines []string
+
+		expectedResponse http.Header
+		expectedError    string
+	}{
+		{
+			it:    "headers",
+			lines: []string{"Authorization: Bearer token", "x-api-key:key:with:colons"},
+			expectedResponse: http.Header{
+				"Authorization": []string{"Bearer token"},
+				"X-Api-Key":     []string{"key:with:colons"},
+			},
+		},
+		{
+			it:            "missing value separator",
+			lines:         []string{"Authorization"},
+			expectedError: "invalid header 'Authorization'",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			header, err := ioHttp.ParseHeader(tc.lines)
+			if err != nil {
+				assert.Equal(t, tc.expectedError, err.Error())
+
+				return
+			}
+
+			assert.Equal(t, tc.expectedResponse, header)
+		})
+	}
+}
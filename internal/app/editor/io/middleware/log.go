@@ -58,3 +58,113 @@ func (m *logMiddleware) Write(ctx context.Context, filename, content string) err
 
 	return err
 }
+
+func (m *logMiddleware) Stat(ctx context.Context) (string, error) {
+	log.Info(ctx, "part-request",
+		log.String("service", m.service),
+		log.String("method", "Stat"),
+		log.String("layer", "part"))
+
+	fingerprint, err := m.next.Stat(ctx)
+
+	log.Info(ctx, "part-response",
+		log.String("service", m.service),
+		log.String("method", "Stat"),
+		log.String("layer", "part"),
+		log.Err(err))
+
+	return fingerprint, err
+}
+
+func NewDirLogMiddleware(next editor.Dir, ioType io.Type) editor.Dir {
+	return &dirLogMiddleware{
+		next:    next,
+		service: fmt.Sprintf("io-%s", ioType),
+	}
+}
+
+type dirLogMiddleware struct {
+	next    editor.Dir
+	service string
+}
+
+func (m *dirLogMiddleware) List(ctx context.Context) ([]app.FileInfo, error) {
+	log.Info(ctx, "part-request",
+		log.String("service", m.service),
+		log.String("method", "List"),
+		log.String("layer", "part"))
+
+	files, err := m.next.List(ctx)
+
+	log.Info(ctx, "part-response",
+		log.String("service", m.service),
+		log.String("method", "List"),
+		log.String("layer", "part"),
+		log.Any("res", map[string]any{
+			"files": len(files),
+		}),
+		log.Err(err))
+
+	return files, err
+}
+
+func (m *dirLogMiddleware) Create(ctx context.Context, name string) error {
+	log.Info(ctx, "part-request",
+		log.String("service", m.service),
+		log.String("method", "Create"),
+		log.String("layer", "part"),
+		log.Any("req", map[string]any{
+			"name": name,
+		}))
+
+	err := m.next.Create(ctx, name)
+
+	log.Info(ctx, "part-response",
+		log.String("service", m.service),
+		log.String("method", "Create"),
+		log.String("layer", "part"),
+		log.Err(err))
+
+	return err
+}
+
+func (m *dirLogMiddleware) Rename(ctx context.Context, name, newName string) error {
+	log.Info(ctx, "part-request",
+		log.String("service", m.service),
+		log.String("method", "Rename"),
+		log.String("layer", "part"),
+		log.Any("req", map[string]any{
+			"name":    name,
+			"newName": newName,
+		}))
+
+	err := m.next.Rename(ctx, name, newName)
+
+	log.Info(ctx, "part-response",
+		log.String("service", m.service),
+		log.String("method", "Rename"),
+		log.String("layer", "part"),
+		log.Err(err))
+
+	return err
+}
+
+func (m *dirLogMiddleware) Delete(ctx context.Context, name string) error {
+	log.Info(ctx, "part-request",
+		log.String("service", m.service),
+		log.String("method", "Delete"),
+		log.String("layer", "part"),
+		log.Any("req", map[string]any{
+			"name": name,
+		}))
+
+	err := m.next.Delete(ctx, name)
+
+	log.Info(ctx, "part-response",
+		log.String("service", m.service),
+		log.String("method", "Delete"),
+		log.String("layer", "part"),
+		log.Err(err))
+
+	return err
+}
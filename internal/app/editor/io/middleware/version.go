@@ -8,22 +8,31 @@ import (
 	"github.com/fakovacic/editor/internal/log"
 )
 
-func NewVersioningMiddleware(io editor.IO, versioning app.Versioning) editor.IO {
+// NewVersioningMiddleware returns io saving versions of file under name,
+// path of file relative to workspace
+func NewVersioningMiddleware(io editor.IO, versioning app.Versioning, name string) editor.IO {
 	return &versioningMiddleware{
 		next:       io,
 		versioning: versioning,
+		name:       name,
 	}
 }
 
 type versioningMiddleware struct {
 	next       editor.IO
 	versioning app.Versioning
+	name       string
 }
 
+// Read saves contents as loaded from source as version with load trigger,
+// source read on sync of loaded file or its reload is not saved
 func (m *versioningMiddleware) Read(ctx context.Context) (string, *app.FileMeta, error) {
 	content, file, err := m.next.Read(ctx)
-	if err == nil {
-		vErr := m.versioning.Save(ctx, file.Name, content)
+	if err == nil && !app.IsSync(ctx) {
+		info := app.GetSaveInfo(ctx)
+		info.Trigger = app.SaveLoad
+
+		vErr := m.versioning.Save(app.WithSaveInfo(ctx, info), m.name, content)
 		if vErr != nil {
 			log.Error(ctx, "error while saving file: %v", vErr)
 		}
@@ -35,7 +44,7 @@ func (m *versioningMiddleware) Read(ctx context.Context) (string, *app.FileMeta,
 func (m *versioningMiddleware) Write(ctx context.Context, filename, content string) error {
 	err := m.next.Write(ctx, filename, content)
 	if err == nil {
-		vErr := m.versioning.Save(ctx, filename, content)
+		vErr := m.versioning.Save(ctx, m.name, content)
 		if vErr != nil {
 			log.Error(ctx, "error while saving file: %v", vErr)
 		}
@@ -43,3 +52,7 @@ func (m *versioningMiddleware) Write(ctx context.Context, filename, content stri
 
 	return err
 }
+
+func (m *versioningMiddleware) Stat(ctx context.Context) (string, error) {
+	return m.next.Stat(ctx)
+}
@@ -0,0 +1,189 @@
+package s3
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/fakovacic/editor/internal/s3"
+)
+
+// New returns file of bucket object key, contents are written only
+// if object was not changed since it was read
+func New(client s3.Client, key string) editor.IO {
+	return &ioS3{
+		client: client,
+		key:    key,
+	}
+}
+
+// NewDir returns files of bucket objects under key prefix
+func NewDir(client s3.Client, prefix string) editor.Dir {
+	return &ioS3{
+		client: client,
+		key:    prefix,
+	}
+}
+
+type ioS3 struct {
+	client s3.Client
+	key    string
+
+	// etag of object last read or written, write is conditional on it
+	etag string
+	mu   sync.Mutex
+}
+
+func (s *ioS3) Read(ctx context.Context) (string, *app.FileMeta, error) {
+	var file app.FileMeta
+
+	err := file.Extension.Parse(path.Ext(s.key))
+	if err != nil {
+		return "", nil, errors.Wrap(err, "parse extension")
+	}
+
+	file.Name = path.Base(s.key)
+
+	obj, err := s.client.Get(ctx, s.key)
+	if err != nil {
+		return "", nil, err
+	}
+
+	s.mu.Lock()
+	s.etag = obj.ETag
+	s.mu.Unlock()
+
+	file.Fingerprint = obj.ETag
+
+	return obj.Body, &file, nil
+}
+
+func (s *ioS3) Stat(ctx context.Context) (string, error) {
+	obj, err := s.client.Head(ctx, s.key)
+	if err != nil {
+		return "", err
+	}
+
+	return obj.ETag, nil
+}
+
+func (s *ioS3) Write(ctx context.Context, filename, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj, err := s.client.Put(ctx, s.key, content, s3.Condition{
+		IfMatch: s.etag,
+	})
+	if err != nil {
+		if errors.IsConflict(err) {
+			return errors.Conflict("file '%s' changed on source since read", filename)
+		}
+
+		return err
+	}
+
+	s.etag = obj.ETag
+
+	return nil
+}
+
+func (s *ioS3) List(ctx context.Context) ([]app.FileInfo, error) {
+	objects, err := s.client.List(ctx, s.key)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]app.FileInfo, 0, len(objects))
+
+	for _, obj := range objects {
+		name := strings.TrimPrefix(obj.Key, s.key)
+
+		// hidden files and dirs are skipped as in file io
+		if hidden(name) {
+			continue
+		}
+
+		var file app.FileInfo
+
+		parseErr := file.Type.Parse(path.Ext(name))
+		if parseErr != nil {
+			continue
+		}
+
+		file.Name = name
+		file.Size = obj.Size
+		file.ModTime = obj.LastModified
+
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+// hidden reports if any element of name starts with dot
+func hidden(name string) bool {
+	for _, elem := range strings.Split(name, "/") {
+		if elem == "" || strings.HasPrefix(elem, ".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *ioS3) Create(ctx context.Context, name string) error {
+	// existing file is not overwritten
+	_, err := s.client.Put(ctx, s.key+name, "", s3.Condition{
+		IfNoneMatch: "*",
+	})
+	if err != nil {
+		if errors.IsConflict(err) {
+			return errors.BadRequest("file '%s' already exist", name)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Rename copies object to new key and deletes it, objects can not be moved
+func (s *ioS3) Rename(ctx context.Context, name, newName string) error {
+	_, err := s.client.Head(ctx, s.key+newName)
+	if err == nil {
+		return errors.BadRequest("file '%s' already exist", newName)
+	}
+
+	if !errors.IsNotFound(err) {
+		return err
+	}
+
+	err = s.client.Copy(ctx, s.key+name, s.key+newName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return errors.NotFound("file '%s' not found", name)
+		}
+
+		return err
+	}
+
+	return s.client.Delete(ctx, s.key+name)
+}
+
+func (s *ioS3) Delete(ctx context.Context, name string) error {
+	// delete of missing object succeeds
+	_, err := s.client.Head(ctx, s.key+name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return errors.NotFound("file '%s' not found", name)
+		}
+
+		return err
+	}
+
+	return s.client.Delete(ctx, s.key+name)
+}
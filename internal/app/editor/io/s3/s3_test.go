@@ -0,0 +1,175 @@
+package s3_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor"
+	ioS3 "github.com/fakovacic/editor/internal/app/editor/io/s3"
+	"github.com/fakovacic/editor/internal/s3"
+	"github.com/fakovacic/editor/internal/s3/s3test"
+	"github.com/stretchr/testify/assert"
+)
+
+func newClient(t *testing.T, srv *s3test.Server) s3.Client {
+	t.Helper()
+
+	client, err := s3.New(srv.Config(), http.DefaultClient, time.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+func TestReadWrite(t *testing.T) {
+	srv := s3test.NewServer()
+	defer srv.Close()
+
+	srv.SetObject("assets/main.css", "a {}")
+
+	ctx := context.Background()
+	file := ioS3.New(newClient(t, srv), "assets/main.css")
+
+	contents, meta, err := file.Read(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "a {}", contents)
+	assert.Equal(t, "main.css", meta.Name)
+	assert.Equal(t, app.CSS, meta.Extension)
+	assert.NotEmpty(t, meta.Fingerprint)
+
+	fingerprint, err := file.Stat(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, meta.Fingerprint, fingerprint)
+
+	// write is conditional on version read
+	err = file.Write(ctx, "main.css", "b {}")
+	assert.NoError(t, err)
+
+	err = file.Write(ctx, "main.css", "c {}")
+	assert.NoError(t, err)
+
+	fingerprint, err = file.Stat(ctx)
+	assert.NoError(t, err)
+	assert.NotEqual(t, meta.Fingerprint, fingerprint)
+
+	srv.SetObject("assets/main.css", "d {}")
+
+	err = file.Write(ctx, "main.css", "e {}")
+	assert.Equal(t, "file 'main.css' changed on source since read", err.Error())
+
+	body, _ := srv.Object("assets/main.css")
+	assert.Equal(t, "d {}", body)
+
+	_, _, err = ioS3.New(newClient(t, srv), "assets/missing.css").Read(ctx)
+	assert.Equal(t, "object 'assets/missing.css' not found", err.Error())
+}
+
+func TestList(t *testing.T) {
+	srv := s3test.NewServer()
+	defer srv.Close()
+
+	srv.PageSize = 2
+
+	srv.SetObject("assets/css/main.css", "a {}")
+	srv.SetObject("assets/index.html", "<html></html>")
+	srv.SetObject("assets/logo.svg", "<svg></svg>")
+	srv.SetObject("assets/.hidden/main.css", "")
+	srv.SetObject("other/main.css", "")
+
+	files, err := ioS3.NewDir(newClient(t, srv), "assets/").List(context.Background())
+	assert.NoError(t, err)
+
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.Name)
+	}
+
+	assert.Equal(t, []string{"css/main.css", "index.html"}, names)
+	assert.Equal(t, app.CSS, files[0].Type)
+	assert.Equal(t, int64(4), files[0].Size)
+	assert.False(t, files[0].ModTime.IsZero())
+}
+
+func TestFileOps(t *testing.T) {
+	cases := []struct {
+		it string
+
+		op func(dir editor.Dir) error
+
+		expectedKeys  []string
+		expectedError string
+	}{
+		{
+			it: "create",
+			op: func(dir editor.Dir) error {
+				return dir.Create(context.Background(), "css/new.css")
+			},
+			expectedKeys: []string{"assets/css/new.css", "assets/exist.css", "assets/main.css"},
+		},
+		{
+			it: "create existing",
+			op: func(dir editor.Dir) error {
+				return dir.Create(context.Background(), "exist.css")
+			},
+			expectedError: "file 'exist.css' already exist",
+		},
+		{
+			it: "rename",
+			op: func(dir editor.Dir) error {
+				return dir.Rename(context.Background(), "main.css", "css/main.css")
+			},
+			expectedKeys: []string{"assets/css/main.css", "assets/exist.css"},
+		},
+		{
+			it: "rename to existing",
+			op: func(dir editor.Dir) error {
+				return dir.Rename(context.Background(), "main.css", "exist.css")
+			},
+			expectedError: "file 'exist.css' already exist",
+		},
+		{
+			it: "rename missing",
+			op: func(dir editor.Dir) error {
+				return dir.Rename(context.Background(), "missing.css", "new.css")
+			},
+			expectedError: "file 'missing.css' not found",
+		},
+		{
+			it: "delete",
+			op: func(dir editor.Dir) error {
+				return dir.Delete(context.Background(), "main.css")
+			},
+			expectedKeys: []string{"assets/exist.css"},
+		},
+		{
+			it: "delete missing",
+			op: func(dir editor.Dir) error {
+				return dir.Delete(context.Background(), "missing.css")
+			},
+			expectedError: "file 'missing.css' not found",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			srv := s3test.NewServer()
+			defer srv.Close()
+
+			srv.SetObject("assets/main.css", "a {}")
+			srv.SetObject("assets/exist.css", "b {}")
+
+			err := tc.op(ioS3.NewDir(newClient(t, srv), "assets/"))
+			if err != nil {
+				assert.Equal(t, tc.expectedError, err.Error())
+
+				return
+			}
+
+			assert.Equal(t, tc.expectedKeys, srv.Keys())
+		})
+	}
+}
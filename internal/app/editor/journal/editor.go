@@ -0,0 +1,289 @@
+package journal
+
+import (
+	"context"
+	"sync"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/fakovacic/editor/internal/log"
+)
+
+// NewEditor returns editor keeping changes applied to contents of next in
+// journal, contents are checkpointed every checkpoint changes and journal
+// is cleared once contents are written. Journal left by previous run is
+// replayed on first load, recovered contents are merged with source
+// changed since and replace ones read from io.
+func NewEditor(next app.Editor, journal Journal, replay Replay, checkpoint int) app.Editor {
+	return &journalEditor{
+		next:       next,
+		journal:    journal,
+		replay:     replay,
+		checkpoint: checkpoint,
+	}
+}
+
+type journalEditor struct {
+	next       app.Editor
+	journal    Journal
+	replay     Replay
+	checkpoint int
+
+	// recovered is set once journal of previous run is replayed,
+	// started once checkpoint is written after contents were written,
+	// base is last contents of next same as source
+	recovered bool
+	started   bool
+	changes   int
+	base      string
+	sync.Mutex
+}
+
+func (s *journalEditor) FileMeta(ctx context.Context) *app.FileMeta {
+	return s.next.FileMeta(ctx)
+}
+
+func (s *journalEditor) Load(ctx context.Context) error {
+	s.Lock()
+	defer s.Unlock()
+
+	err := s.next.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.rebase(ctx)
+
+	if s.recovered {
+		return nil
+	}
+
+	// journal is kept until contents are recovered, so edits
+	// are not lost if recovering fails
+	err = s.recover(ctx)
+	if err != nil {
+		return errors.Wrap(err, "recover journal")
+	}
+
+	s.recovered = true
+
+	return nil
+}
+
+// recover replays journal left by previous run, contents of journal
+// are newer than ones read from io as they were never written
+func (s *journalEditor) recover(ctx context.Context) error {
+	checkpoint, changes, err := s.journal.Read(ctx)
+	if err != nil {
+		return err
+	}
+
+	if checkpoint == nil {
+		return nil
+	}
+
+	contents, err := s.replay(checkpoint.Contents, checkpoint.State, changes)
+	if err != nil {
+		return errors.Wrap(err, "replay")
+	}
+
+	current, _, err := s.next.Read(ctx)
+	if err != nil {
+		return errors.Wrap(err, "editor read content")
+	}
+
+	if contents == current {
+		return s.journal.Clear(ctx)
+	}
+
+	// source changed since edits were made, conflicting edits are kept
+	// in journal instead of overwriting changes of source
+	if checkpoint.Base != nil && *checkpoint.Base != current {
+		var name string
+
+		meta := s.next.FileMeta(ctx)
+		if meta != nil {
+			name = meta.Name
+		}
+
+		contents, err = editor.Merge(name, *checkpoint.Base, contents, current)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = s.next.Replace(ctx, contents)
+	if err != nil {
+		return errors.Wrap(err, "editor replace")
+	}
+
+	log.Info(ctx, "contents recovered from journal", log.Any("changes", len(changes)))
+
+	// recovered contents are not written yet
+	return s.start(ctx)
+}
+
+func (s *journalEditor) Unload(ctx context.Context) error {
+	return s.next.Unload(ctx)
+}
+
+// Write clears journal once contents are written, changes made after
+// write start it again
+func (s *journalEditor) Write(ctx context.Context) ([]*app.ChangeMsg, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	changes, err := s.next.Write(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// contents editor did not write, as empty ones, are only in journal
+	meta := s.next.FileMeta(ctx)
+	if meta == nil || meta.Dirty {
+		return changes, nil
+	}
+
+	err = s.journal.Clear(ctx)
+	if err != nil {
+		log.Error(ctx, "journal clear:", log.Err(err))
+	}
+
+	s.started = false
+	s.rebase(ctx)
+
+	return changes, nil
+}
+
+func (s *journalEditor) Reload(ctx context.Context) ([]*app.ChangeMsg, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	changes, err := s.next.Reload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.rebase(ctx)
+	s.record(ctx, changes)
+
+	return changes, nil
+}
+
+func (s *journalEditor) Read(ctx context.Context) (string, *app.FileMeta, error) {
+	return s.next.Read(ctx)
+}
+
+func (s *journalEditor) Change(ctx context.Context, msg *app.ChangeMsg) (*app.ChangeMsg, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	change, err := s.next.Change(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, []*app.ChangeMsg{change})
+
+	return change, nil
+}
+
+func (s *journalEditor) Replace(ctx context.Context, contents string) ([]*app.ChangeMsg, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	changes, err := s.next.Replace(ctx, contents)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, changes)
+
+	return changes, nil
+}
+
+func (s *journalEditor) Encoded(ctx context.Context) (string, error) {
+	return s.next.Encoded(ctx)
+}
+
+func (s *journalEditor) State(ctx context.Context) (string, error) {
+	return s.next.State(ctx)
+}
+
+// record appends applied changes to journal, journal is started again
+// from checkpoint of contents if it is not started or has enough changes.
+// Edits are not rejected when journal fails, next change starts it again.
+func (s *journalEditor) record(ctx context.Context, changes []*app.ChangeMsg) {
+	if len(changes) == 0 {
+		return
+	}
+
+	if s.started {
+		err := s.journal.Append(ctx, changes)
+		if err != nil {
+			log.Error(ctx, "journal append:", log.Err(err))
+		}
+
+		s.changes += len(changes)
+
+		if err == nil && s.changes < s.checkpoint {
+			return
+		}
+	}
+
+	err := s.start(ctx)
+	if err != nil {
+		log.Error(ctx, "journal checkpoint:", log.Err(err))
+	}
+}
+
+// rebase sets contents of next as base of journal while they are same as
+// source, source merged with edits is not known so base is kept until
+// contents are written
+func (s *journalEditor) rebase(ctx context.Context) {
+	meta := s.next.FileMeta(ctx)
+	if meta == nil || meta.Dirty {
+		return
+	}
+
+	contents, _, err := s.next.Read(ctx)
+	if err != nil {
+		log.Error(ctx, "editor read content:", log.Err(err))
+
+		return
+	}
+
+	s.base = contents
+}
+
+// start writes checkpoint of current contents, changes are appended after it
+func (s *journalEditor) start(ctx context.Context) error {
+	s.started = false
+
+	contents, _, err := s.next.Read(ctx)
+	if err != nil {
+		return errors.Wrap(err, "editor read content")
+	}
+
+	state, err := s.next.State(ctx)
+	if err != nil {
+		return errors.Wrap(err, "editor state")
+	}
+
+	base := s.base
+
+	err = s.journal.Checkpoint(ctx, Checkpoint{
+		Contents: contents,
+		State:    state,
+		Base:     &base,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.started = true
+	s.changes = 0
+
+	return nil
+}
@@ -0,0 +1,257 @@
+package journal_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor"
+	"github.com/fakovacic/editor/internal/app/editor/crdt"
+	"github.com/fakovacic/editor/internal/app/editor/journal"
+	"github.com/fakovacic/editor/internal/app/editor/mocks"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditorRecover(t *testing.T) {
+	insert := func(column int, value string) *app.ChangeMsg {
+		return &app.ChangeMsg{
+			Action:   editor.OpInsert,
+			Start:    app.ChangeRow{Column: column},
+			End:      app.ChangeRow{Column: column + len(value)},
+			Lines:    []string{value},
+			Revision: column,
+		}
+	}
+
+	insertOp := func(clock int, origin *app.CRDTID, value string) *app.ChangeMsg {
+		return &app.ChangeMsg{
+			Ops: []app.CRDTOp{
+				{
+					Action: editor.OpInsert,
+					ID:     app.CRDTID{Site: "client", Clock: clock},
+					Origin: origin,
+					Value:  value,
+				},
+			},
+		}
+	}
+
+	cases := []struct {
+		it string
+
+		newEditor func(editor.IO) app.Editor
+		replay    journal.Replay
+		changes   []*app.ChangeMsg
+
+		expected string
+	}{
+		{
+			it:        "delta changes after checkpoint",
+			newEditor: editor.New,
+			replay:    editor.Replay,
+			changes: []*app.ChangeMsg{
+				insert(0, "x"),
+				insert(1, "y"),
+				insert(2, "z"),
+			},
+
+			expected: "xyza {}",
+		},
+		{
+			it:        "crdt ops after checkpoint of state",
+			newEditor: crdt.New,
+			replay:    crdt.Replay,
+			changes: []*app.ChangeMsg{
+				insertOp(5, nil, "x"),
+				insertOp(6, &app.CRDTID{Site: "client", Clock: 5}, "y"),
+				insertOp(7, &app.CRDTID{Site: "client", Clock: 6}, "z"),
+			},
+
+			expected: "xyza {}",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			ctx := context.Background()
+
+			written := make([]string, 0)
+
+			io := &mocks.IOMock{
+				ReadFunc: func(_ context.Context) (string, *app.FileMeta, error) {
+					return "a {}", &app.FileMeta{
+						Name: "main.css",
+					}, nil
+				},
+				WriteFunc: func(_ context.Context, _, contents string) error {
+					written = append(written, contents)
+
+					return nil
+				},
+				StatFunc: func(_ context.Context) (string, error) {
+					return "", nil
+				},
+			}
+
+			path := filepath.Join(t.TempDir(), journal.Name("main.css"))
+
+			// checkpoint after second change, third one is appended
+			crashed := journal.NewEditor(tc.newEditor(io), journal.NewFile(path), tc.replay, 2)
+
+			err := crashed.Load(ctx)
+			assert.NoError(t, err)
+
+			for _, change := range tc.changes {
+				_, err = crashed.Change(ctx, change)
+				assert.NoError(t, err)
+			}
+
+			// server restarts, contents were never written
+			recovered := journal.NewEditor(tc.newEditor(io), journal.NewFile(path), tc.replay, 2)
+
+			err = recovered.Load(ctx)
+			assert.NoError(t, err)
+
+			contents, _, err := recovered.Read(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, contents)
+
+			_, err = recovered.Write(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{tc.expected}, written)
+
+			// written contents are not recovered again
+			checkpoint, _, err := journal.NewFile(path).Read(ctx)
+			assert.NoError(t, err)
+			assert.Nil(t, checkpoint)
+		})
+	}
+}
+
+func TestEditorWriteSkipped(t *testing.T) {
+	ctx := context.Background()
+
+	io := &mocks.IOMock{
+		ReadFunc: func(_ context.Context) (string, *app.FileMeta, error) {
+			return "a {}", &app.FileMeta{
+				Name: "main.css",
+			}, nil
+		},
+		WriteFunc: func(_ context.Context, _, _ string) error {
+			return nil
+		},
+		StatFunc: func(_ context.Context) (string, error) {
+			return "", nil
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), journal.Name("main.css"))
+
+	e := journal.NewEditor(editor.New(io), journal.NewFile(path), editor.Replay, 100)
+
+	err := e.Load(ctx)
+	assert.NoError(t, err)
+
+	_, err = e.Change(ctx, &app.ChangeMsg{
+		Action: editor.OpRemove,
+		End:    app.ChangeRow{Column: 4},
+		Lines:  []string{"a {}"},
+	})
+	assert.NoError(t, err)
+
+	// empty contents are not written, journal of them is kept
+	_, err = e.Write(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, io.WriteCalls())
+
+	checkpoint, changes, err := journal.NewFile(path).Read(ctx)
+	assert.NoError(t, err)
+	assert.NotNil(t, checkpoint)
+	assert.Empty(t, changes)
+	assert.Equal(t, "", checkpoint.Contents)
+}
+
+func TestEditorRecoverMerge(t *testing.T) {
+	cases := []struct {
+		it string
+
+		source string
+
+		expected string
+		conflict bool
+	}{
+		{
+			it:     "source changed in other lines",
+			source: "a {}\nb {}\nc {}\nd {}\n",
+
+			expected: "xa {}\nb {}\nc {}\nd {}\n",
+		},
+		{
+			it:     "source changed in edited line",
+			source: "y {}\nb {}\nc {}\n",
+
+			conflict: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			ctx := context.Background()
+
+			source := "a {}\nb {}\nc {}\n"
+
+			io := &mocks.IOMock{
+				ReadFunc: func(_ context.Context) (string, *app.FileMeta, error) {
+					return source, &app.FileMeta{
+						Name: "main.css",
+					}, nil
+				},
+				WriteFunc: func(_ context.Context, _, _ string) error {
+					return nil
+				},
+				StatFunc: func(_ context.Context) (string, error) {
+					return "", nil
+				},
+			}
+
+			path := filepath.Join(t.TempDir(), journal.Name("main.css"))
+
+			crashed := journal.NewEditor(editor.New(io), journal.NewFile(path), editor.Replay, 100)
+
+			err := crashed.Load(ctx)
+			assert.NoError(t, err)
+
+			_, err = crashed.Change(ctx, &app.ChangeMsg{
+				Action: editor.OpInsert,
+				End:    app.ChangeRow{Column: 1},
+				Lines:  []string{"x"},
+			})
+			assert.NoError(t, err)
+
+			// source is changed before server restarts
+			source = tc.source
+
+			recovered := journal.NewEditor(editor.New(io), journal.NewFile(path), editor.Replay, 100)
+
+			err = recovered.Load(ctx)
+			if tc.conflict {
+				assert.True(t, errors.IsConflict(err))
+
+				// edits are kept until conflict is resolved
+				checkpoint, _, err := journal.NewFile(path).Read(ctx)
+				assert.NoError(t, err)
+				assert.NotNil(t, checkpoint)
+
+				return
+			}
+
+			assert.NoError(t, err)
+
+			contents, _, err := recovered.Read(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, contents)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package journal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/atomicfile"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/fakovacic/editor/internal/log"
+)
+
+// NewFile returns journal kept in file at path, one json record per line.
+// Checkpoint replaces file atomically, changes are appended to it.
+func NewFile(path string) Journal {
+	return &file{
+		path: path,
+	}
+}
+
+type file struct {
+	path string
+}
+
+// record is line of journal, first one is checkpoint
+type record struct {
+	Checkpoint *Checkpoint    `json:"checkpoint,omitempty"`
+	Change     *app.ChangeMsg `json:"change,omitempty"`
+}
+
+func (f *file) Checkpoint(_ context.Context, checkpoint Checkpoint) error {
+	data, err := json.Marshal(record{
+		Checkpoint: &checkpoint,
+	})
+	if err != nil {
+		return errors.Wrap(err, "marshal checkpoint")
+	}
+
+	err = os.MkdirAll(filepath.Dir(f.path), 0o700)
+	if err != nil {
+		return errors.Wrap(err, "create journal dir")
+	}
+
+	err = atomicfile.WriteFile(f.path, append(data, '\n'), 0o600)
+	if err != nil {
+		return errors.Wrap(err, "write checkpoint")
+	}
+
+	return nil
+}
+
+// Append writes changes with single write, so changes are in journal once
+// write returns even if server crashes, not if system crashes
+func (f *file) Append(_ context.Context, changes []*app.ChangeMsg) error {
+	var buf bytes.Buffer
+
+	for _, change := range changes {
+		data, err := json.Marshal(record{
+			Change: change,
+		})
+		if err != nil {
+			return errors.Wrap(err, "marshal change")
+		}
+
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	// journal without checkpoint can not be replayed
+	journal, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return errors.Wrap(err, "open journal")
+	}
+
+	_, err = journal.Write(buf.Bytes())
+
+	closeErr := journal.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "append changes")
+	}
+
+	return nil
+}
+
+// Read returns records up to first incomplete one, last change can be
+// written only partly when server crashed
+func (f *file) Read(ctx context.Context) (*Checkpoint, []*app.ChangeMsg, error) {
+	journal, err := os.Open(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+
+		return nil, nil, errors.Wrap(err, "open journal")
+	}
+
+	defer journal.Close()
+
+	var (
+		checkpoint *Checkpoint
+		changes    = make([]*app.ChangeMsg, 0)
+	)
+
+	decoder := json.NewDecoder(journal)
+
+	for {
+		var r record
+
+		err = decoder.Decode(&r)
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			log.Error(ctx, "journal record incomplete:", log.String("path", f.path), log.Err(err))
+
+			break
+		}
+
+		switch {
+		case checkpoint == nil && r.Checkpoint != nil:
+			checkpoint = r.Checkpoint
+		case checkpoint != nil && r.Change != nil:
+			changes = append(changes, r.Change)
+		default:
+			return nil, nil, errors.BadRequest("journal '%s' record %d not valid", f.path, len(changes)+1)
+		}
+	}
+
+	// checkpoint is written atomically, empty journal has nothing to replay
+	if checkpoint == nil {
+		return nil, nil, nil
+	}
+
+	return checkpoint, changes, nil
+}
+
+func (f *file) Clear(_ context.Context) error {
+	err := os.Remove(f.path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "remove journal")
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package journal_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor/journal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFile(t *testing.T) {
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "journals", journal.Name("css/main.css"))
+	assert.Equal(t, "css%2Fmain.css.journal", filepath.Base(path))
+
+	j := journal.NewFile(path)
+
+	// no journal
+	checkpoint, changes, err := j.Read(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, checkpoint)
+	assert.Nil(t, changes)
+
+	// changes need checkpoint
+	err = j.Append(ctx, []*app.ChangeMsg{{Action: "insert"}})
+	assert.Error(t, err)
+
+	err = j.Checkpoint(ctx, journal.Checkpoint{Contents: "a {}"})
+	assert.NoError(t, err)
+
+	change := &app.ChangeMsg{
+		Action:   "insert",
+		End:      app.ChangeRow{Column: 1},
+		Lines:    []string{"b"},
+		Revision: 1,
+	}
+
+	err = j.Append(ctx, []*app.ChangeMsg{change})
+	assert.NoError(t, err)
+
+	// change written partly on crash is dropped
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	assert.NoError(t, err)
+
+	_, err = f.WriteString(`{"change":{"action":"ins`)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	checkpoint, changes, err = j.Read(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, &journal.Checkpoint{Contents: "a {}"}, checkpoint)
+	assert.Equal(t, []*app.ChangeMsg{change}, changes)
+
+	// checkpoint starts journal again
+	err = j.Checkpoint(ctx, journal.Checkpoint{Contents: "ba {}"})
+	assert.NoError(t, err)
+
+	checkpoint, changes, err = j.Read(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, &journal.Checkpoint{Contents: "ba {}"}, checkpoint)
+	assert.Empty(t, changes)
+
+	err = j.Clear(ctx)
+	assert.NoError(t, err)
+
+	checkpoint, _, err = j.Read(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, checkpoint)
+}
@@ -0,0 +1,44 @@
+package journal
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/fakovacic/editor/internal/app"
+)
+
+// Journal keeps changes applied to contents of file since it was last
+// written, so edits survive crash of server
+type Journal interface {
+	// Checkpoint starts journal again from contents and editor state
+	Checkpoint(context.Context, Checkpoint) error
+
+	// Append adds applied changes after checkpoint
+	Append(context.Context, []*app.ChangeMsg) error
+
+	// Read returns checkpoint and changes applied after it,
+	// checkpoint is nil if there is no journal
+	Read(context.Context) (*Checkpoint, []*app.ChangeMsg, error)
+
+	// Clear removes journal, contents are written
+	Clear(context.Context) error
+}
+
+// Checkpoint is contents changes are applied to, state is replication
+// state of editor modes which need it to apply changes. Base is source
+// contents edits were made to, recovered contents are merged with source
+// changed since, journals without it replace source.
+type Checkpoint struct {
+	Contents string  `json:"contents"`
+	State    string  `json:"state,omitempty"`
+	Base     *string `json:"base,omitempty"`
+}
+
+// Replay returns contents of checkpoint with changes applied
+type Replay func(contents, state string, changes []*app.ChangeMsg) (string, error)
+
+// Name returns name of journal of file, journals of files in
+// directories are kept flat as css%2Fmain.css.journal
+func Name(filename string) string {
+	return url.PathEscape(filename) + ".journal"
+}
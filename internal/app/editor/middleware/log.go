@@ -30,14 +30,30 @@ func (m *logMiddleware) Unload(ctx context.Context) error {
 	return m.next.Unload(ctx)
 }
 
-func (m *logMiddleware) Read(ctx context.Context) (string, error) {
+func (m *logMiddleware) Read(ctx context.Context) (string, *app.FileMeta, error) {
 	return m.next.Read(ctx)
 }
 
-func (m *logMiddleware) Write(ctx context.Context) error {
+func (m *logMiddleware) Encoded(ctx context.Context) (string, error) {
+	return m.next.Encoded(ctx)
+}
+
+func (m *logMiddleware) Write(ctx context.Context) ([]*app.ChangeMsg, error) {
 	return m.next.Write(ctx)
 }
 
-func (m *logMiddleware) Change(ctx context.Context, msg *app.ChangeMsg) error {
+func (m *logMiddleware) Reload(ctx context.Context) ([]*app.ChangeMsg, error) {
+	return m.next.Reload(ctx)
+}
+
+func (m *logMiddleware) Change(ctx context.Context, msg *app.ChangeMsg) (*app.ChangeMsg, error) {
 	return m.next.Change(ctx, msg)
 }
+
+func (m *logMiddleware) Replace(ctx context.Context, contents string) ([]*app.ChangeMsg, error) {
+	return m.next.Replace(ctx, contents)
+}
+
+func (m *logMiddleware) State(ctx context.Context) (string, error) {
+	return m.next.State(ctx)
+}
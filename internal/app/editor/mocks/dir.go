@@ -0,0 +1,233 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"context"
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor"
+	"sync"
+)
+
+// Ensure, that DirMock does implement editor.Dir.
+// If this is not the case, regenerate this file with moq.
+var _ editor.Dir = &DirMock{}
+
+// DirMock is a mock implementation of editor.Dir.
+//
+//	func TestSomethingThatUsesDir(t *testing.T) {
+//
+//		// make and configure a mocked editor.Dir
+//		mockedDir := &DirMock{
+//			CreateFunc: func(contextMoqParam context.Context, s string) error {
+//				panic("mock out the Create method")
+//			},
+//			DeleteFunc: func(contextMoqParam context.Context, s string) error {
+//				panic("mock out the Delete method")
+//			},
+//			ListFunc: func(contextMoqParam context.Context) ([]app.FileInfo, error) {
+//				panic("mock out the List method")
+//			},
+//			RenameFunc: func(contextMoqParam context.Context, s1 string, s2 string) error {
+//				panic("mock out the Rename method")
+//			},
+//		}
+//
+//		// use mockedDir in code that requires editor.Dir
+//		// and then make assertions.
+//
+//	}
+type DirMock struct {
+	// CreateFunc mocks the Create method.
+	CreateFunc func(contextMoqParam context.Context, s string) error
+
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(contextMoqParam context.Context, s string) error
+
+	// ListFunc mocks the List method.
+	ListFunc func(contextMoqParam context.Context) ([]app.FileInfo, error)
+
+	// RenameFunc mocks the Rename method.
+	RenameFunc func(contextMoqParam context.Context, s1 string, s2 string) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Create holds details about calls to the Create method.
+		Create []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// S is the s argument value.
+			S string
+		}
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// S is the s argument value.
+			S string
+		}
+		// List holds details about calls to the List method.
+		List []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+		}
+		// Rename holds details about calls to the Rename method.
+		Rename []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// S1 is the s1 argument value.
+			S1 string
+			// S2 is the s2 argument value.
+			S2 string
+		}
+	}
+	lockCreate sync.RWMutex
+	lockDelete sync.RWMutex
+	lockList   sync.RWMutex
+	lockRename sync.RWMutex
+}
+
+// Create calls CreateFunc.
+func (mock *DirMock) Create(contextMoqParam context.Context, s string) error {
+	if mock.CreateFunc == nil {
+		panic("DirMock.CreateFunc: method is nil but Dir.Create was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		S               string
+	}{
+		ContextMoqParam: contextMoqParam,
+		S:               s,
+	}
+	mock.lockCreate.Lock()
+	mock.calls.Create = append(mock.calls.Create, callInfo)
+	mock.lockCreate.Unlock()
+	return mock.CreateFunc(contextMoqParam, s)
+}
+
+// CreateCalls gets all the calls that were made to Create.
+// Check the length with:
+//
+//	len(mockedDir.CreateCalls())
+func (mock *DirMock) CreateCalls() []struct {
+	ContextMoqParam context.Context
+	S               string
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		S               string
+	}
+	mock.lockCreate.RLock()
+	calls = mock.calls.Create
+	mock.lockCreate.RUnlock()
+	return calls
+}
+
+// Delete calls DeleteFunc.
+func (mock *DirMock) Delete(contextMoqParam context.Context, s string) error {
+	if mock.DeleteFunc == nil {
+		panic("DirMock.DeleteFunc: method is nil but Dir.Delete was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		S               string
+	}{
+		ContextMoqParam: contextMoqParam,
+		S:               s,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	return mock.DeleteFunc(contextMoqParam, s)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedDir.DeleteCalls())
+func (mock *DirMock) DeleteCalls() []struct {
+	ContextMoqParam context.Context
+	S               string
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		S               string
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// List calls ListFunc.
+func (mock *DirMock) List(contextMoqParam context.Context) ([]app.FileInfo, error) {
+	if mock.ListFunc == nil {
+		panic("DirMock.ListFunc: method is nil but Dir.List was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+	}{
+		ContextMoqParam: contextMoqParam,
+	}
+	mock.lockList.Lock()
+	mock.calls.List = append(mock.calls.List, callInfo)
+	mock.lockList.Unlock()
+	return mock.ListFunc(contextMoqParam)
+}
+
+// ListCalls gets all the calls that were made to List.
+// Check the length with:
+//
+//	len(mockedDir.ListCalls())
+func (mock *DirMock) ListCalls() []struct {
+	ContextMoqParam context.Context
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+	}
+	mock.lockList.RLock()
+	calls = mock.calls.List
+	mock.lockList.RUnlock()
+	return calls
+}
+
+// Rename calls RenameFunc.
+func (mock *DirMock) Rename(contextMoqParam context.Context, s1 string, s2 string) error {
+	if mock.RenameFunc == nil {
+		panic("DirMock.RenameFunc: method is nil but Dir.Rename was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		S1              string
+		S2              string
+	}{
+		ContextMoqParam: contextMoqParam,
+		S1:              s1,
+		S2:              s2,
+	}
+	mock.lockRename.Lock()
+	mock.calls.Rename = append(mock.calls.Rename, callInfo)
+	mock.lockRename.Unlock()
+	return mock.RenameFunc(contextMoqParam, s1, s2)
+}
+
+// RenameCalls gets all the calls that were made to Rename.
+// Check the length with:
+//
+//	len(mockedDir.RenameCalls())
+func (mock *DirMock) RenameCalls() []struct {
+	ContextMoqParam context.Context
+	S1              string
+	S2              string
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		S1              string
+		S2              string
+	}
+	mock.lockRename.RLock()
+	calls = mock.calls.Rename
+	mock.lockRename.RUnlock()
+	return calls
+}
@@ -23,6 +23,9 @@ var _ editor.IO = &IOMock{}
 //			ReadFunc: func(contextMoqParam context.Context) (string, *app.FileMeta, error) {
 //				panic("mock out the Read method")
 //			},
+//			StatFunc: func(contextMoqParam context.Context) (string, error) {
+//				panic("mock out the Stat method")
+//			},
 //			WriteFunc: func(contextMoqParam context.Context, s1 string, s2 string) error {
 //				panic("mock out the Write method")
 //			},
@@ -36,6 +39,9 @@ type IOMock struct {
 	// ReadFunc mocks the Read method.
 	ReadFunc func(contextMoqParam context.Context) (string, *app.FileMeta, error)
 
+	// StatFunc mocks the Stat method.
+	StatFunc func(contextMoqParam context.Context) (string, error)
+
 	// WriteFunc mocks the Write method.
 	WriteFunc func(contextMoqParam context.Context, s1 string, s2 string) error
 
@@ -46,6 +52,11 @@ type IOMock struct {
 			// ContextMoqParam is the contextMoqParam argument value.
 			ContextMoqParam context.Context
 		}
+		// Stat holds details about calls to the Stat method.
+		Stat []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+		}
 		// Write holds details about calls to the Write method.
 		Write []struct {
 			// ContextMoqParam is the contextMoqParam argument value.
@@ -57,6 +68,7 @@ type IOMock struct {
 		}
 	}
 	lockRead  sync.RWMutex
+	lockStat  sync.RWMutex
 	lockWrite sync.RWMutex
 }
 
@@ -92,6 +104,38 @@ func (mock *IOMock) ReadCalls() []struct {
 	return calls
 }
 
+// Stat calls StatFunc.
+func (mock *IOMock) Stat(contextMoqParam context.Context) (string, error) {
+	if mock.StatFunc == nil {
+		panic("IOMock.StatFunc: method is nil but IO.Stat was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+	}{
+		ContextMoqParam: contextMoqParam,
+	}
+	mock.lockStat.Lock()
+	mock.calls.Stat = append(mock.calls.Stat, callInfo)
+	mock.lockStat.Unlock()
+	return mock.StatFunc(contextMoqParam)
+}
+
+// StatCalls gets all the calls that were made to Stat.
+// Check the length with:
+//
+//	len(mockedIO.StatCalls())
+func (mock *IOMock) StatCalls() []struct {
+	ContextMoqParam context.Context
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+	}
+	mock.lockStat.RLock()
+	calls = mock.calls.Stat
+	mock.lockStat.RUnlock()
+	return calls
+}
+
 // Write calls WriteFunc.
 func (mock *IOMock) Write(contextMoqParam context.Context, s1 string, s2 string) error {
 	if mock.WriteFunc == nil {
@@ -0,0 +1,31 @@
+package editor
+
+import (
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor/rope"
+	"github.com/fakovacic/editor/internal/errors"
+)
+
+// Replay applies changes to contents in order, changes are ones editor
+// applied so they are not transformed, state is not used in delta mode
+func Replay(contents, _ string, changes []*app.ChangeMsg) (string, error) {
+	s := &editor{
+		file: editorFile{
+			Contents: rope.New(contents),
+		},
+	}
+
+	for i, change := range changes {
+		err := validate(change)
+		if err != nil {
+			return "", errors.Wrap(err, "change %d", i)
+		}
+
+		err = s.apply(change, true)
+		if err != nil {
+			return "", errors.Wrap(err, "change %d", i)
+		}
+	}
+
+	return s.file.Contents.String(), nil
+}
@@ -0,0 +1,388 @@
+package rope
+
+import (
+	"strings"
+
+	"github.com/fakovacic/editor/internal/errors"
+)
+
+// maxLeaf is max number of bytes kept in a single node
+const maxLeaf = 1024
+
+// Rope keeps document contents in a treap of text chunks,
+// every node tracks size and new lines of its subtree so
+// offset and line lookups, inserts and removes are O(log n)
+type Rope struct {
+	root *node
+	seed uint32
+}
+
+type node struct {
+	text     string
+	newLines int
+
+	priority uint32
+	left     *node
+	right    *node
+
+	size  int // bytes in subtree
+	lines int // new lines in subtree
+}
+
+func New(s string) *Rope {
+	r := &Rope{
+		seed: 2463534242,
+	}
+
+	for len(s) > 0 {
+		n := min(len(s), maxLeaf)
+
+		r.root = merge(r.root, r.newNode(s[:n]))
+		s = s[n:]
+	}
+
+	return r
+}
+
+// Len returns contents size in bytes
+func (r *Rope) Len() int {
+	return r.root.getSize()
+}
+
+// Rows returns number of rows, document without contents has one row
+func (r *Rope) Rows() int {
+	return r.root.getLines() + 1
+}
+
+func (r *Rope) String() string {
+	var b strings.Builder
+
+	b.Grow(r.Len())
+
+	r.root.write(&b)
+
+	return b.String()
+}
+
+// Insert adds text at byte offset
+func (r *Rope) Insert(offset int, text string) error {
+	if offset < 0 || offset > r.Len() {
+		return errors.BadRequest("offset %d out of range", offset)
+	}
+
+	if text == "" {
+		return nil
+	}
+
+	// typing usually fits in existing chunk
+	if len(text) < maxLeaf && r.root.insertInto(offset, text) {
+		return nil
+	}
+
+	left, right := split(r.root, offset)
+
+	for len(text) > 0 {
+		n := min(len(text), maxLeaf)
+
+		left = merge(left, r.newNode(text[:n]))
+		text = text[n:]
+	}
+
+	r.root = merge(left, right)
+
+	return nil
+}
+
+// Remove deletes length bytes starting from offset
+func (r *Rope) Remove(offset, length int) error {
+	if offset < 0 || length < 0 || offset+length > r.Len() {
+		return errors.BadRequest("range %d:%d out of range", offset, offset+length)
+	}
+
+	if length == 0 {
+		return nil
+	}
+
+	if r.root.removeFrom(offset, length) {
+		return nil
+	}
+
+	left, right := split(r.root, offset)
+	_, right = split(right, length)
+
+	r.root = merge(left, right)
+
+	return nil
+}
+
+// LineStart returns byte offset of first char in row
+func (r *Rope) LineStart(row int) (int, error) {
+	if row < 0 || row >= r.Rows() {
+		return 0, errors.BadRequest("row %d out of range", row)
+	}
+
+	if row == 0 {
+		return 0, nil
+	}
+
+	return r.root.lineStart(row), nil
+}
+
+// Line returns row contents without new line
+func (r *Rope) Line(row int) (string, error) {
+	start, err := r.LineStart(row)
+	if err != nil {
+		return "", err
+	}
+
+	end := r.Len()
+
+	if row+1 < r.Rows() {
+		end = r.root.lineStart(row+1) - 1
+	}
+
+	return r.Substring(start, end)
+}
+
+// Substring returns contents between byte offsets
+func (r *Rope) Substring(from, to int) (string, error) {
+	if from < 0 || to < from || to > r.Len() {
+		return "", errors.BadRequest("range %d:%d out of range", from, to)
+	}
+
+	var b strings.Builder
+
+	b.Grow(to - from)
+
+	r.root.writeRange(&b, from, to)
+
+	return b.String(), nil
+}
+
+func (r *Rope) newNode(text string) *node {
+	// xorshift, priorities only need to be well spread
+	r.seed ^= r.seed << 13
+	r.seed ^= r.seed >> 17
+	r.seed ^= r.seed << 5
+
+	n := &node{
+		text:     text,
+		newLines: strings.Count(text, "\n"),
+		priority: r.seed,
+	}
+
+	n.update()
+
+	return n
+}
+
+func (n *node) getSize() int {
+	if n == nil {
+		return 0
+	}
+
+	return n.size
+}
+
+func (n *node) getLines() int {
+	if n == nil {
+		return 0
+	}
+
+	return n.lines
+}
+
+func (n *node) update() {
+	n.size = n.left.getSize() + len(n.text) + n.right.getSize()
+	n.lines = n.left.getLines() + n.newLines + n.right.getLines()
+}
+
+func (n *node) write(b *strings.Builder) {
+	if n == nil {
+		return
+	}
+
+	n.left.write(b)
+	b.WriteString(n.text)
+	n.right.write(b)
+}
+
+func (n *node) writeRange(b *strings.Builder, from, to int) {
+	if n == nil || from >= to {
+		return
+	}
+
+	leftSize := n.left.getSize()
+
+	if from < leftSize {
+		n.left.writeRange(b, from, min(to, leftSize))
+	}
+
+	textStart := max(from-leftSize, 0)
+	textEnd := min(to-leftSize, len(n.text))
+
+	if textStart < textEnd {
+		b.WriteString(n.text[textStart:textEnd])
+	}
+
+	rightOffset := leftSize + len(n.text)
+
+	if to > rightOffset {
+		n.right.writeRange(b, max(from-rightOffset, 0), to-rightOffset)
+	}
+}
+
+func (n *node) lineStart(row int) int {
+	leftLines := n.left.getLines()
+
+	if row <= leftLines {
+		return n.left.lineStart(row)
+	}
+
+	row -= leftLines
+	offset := n.left.getSize()
+
+	if row <= n.newLines {
+		idx := 0
+
+		for i := 0; i < row; i++ {
+			idx += strings.IndexByte(n.text[idx:], '\n') + 1
+		}
+
+		return offset + idx
+	}
+
+	return offset + len(n.text) + n.right.lineStart(row-n.newLines)
+}
+
+// insertInto adds text into chunk which contains offset, if chunk has room
+func (n *node) insertInto(offset int, text string) bool {
+	if n == nil {
+		return false
+	}
+
+	leftSize := n.left.getSize()
+
+	var ok bool
+
+	switch {
+	case offset < leftSize:
+		ok = n.left.insertInto(offset, text)
+	case offset <= leftSize+len(n.text):
+		if len(n.text)+len(text) > maxLeaf {
+			return false
+		}
+
+		pos := offset - leftSize
+
+		n.text = n.text[:pos] + text + n.text[pos:]
+		n.newLines += strings.Count(text, "\n")
+		ok = true
+	default:
+		ok = n.right.insertInto(offset-leftSize-len(n.text), text)
+	}
+
+	if ok {
+		n.update()
+	}
+
+	return ok
+}
+
+// removeFrom deletes range from chunk, if whole range is in single chunk
+func (n *node) removeFrom(offset, length int) bool {
+	if n == nil {
+		return false
+	}
+
+	leftSize := n.left.getSize()
+
+	var ok bool
+
+	switch {
+	case offset < leftSize:
+		ok = n.left.removeFrom(offset, length)
+	case offset < leftSize+len(n.text):
+		pos := offset - leftSize
+
+		if pos+length > len(n.text) || length == len(n.text) {
+			return false
+		}
+
+		n.newLines -= strings.Count(n.text[pos:pos+length], "\n")
+		n.text = n.text[:pos] + n.text[pos+length:]
+		ok = true
+	default:
+		ok = n.right.removeFrom(offset-leftSize-len(n.text), length)
+	}
+
+	if ok {
+		n.update()
+	}
+
+	return ok
+}
+
+// split returns trees with first k bytes and the rest
+func split(n *node, k int) (*node, *node) {
+	if n == nil {
+		return nil, nil
+	}
+
+	leftSize := n.left.getSize()
+
+	switch {
+	case k <= leftSize:
+		left, right := split(n.left, k)
+		n.left = right
+		n.update()
+
+		return left, n
+	case k >= leftSize+len(n.text):
+		left, right := split(n.right, k-leftSize-len(n.text))
+		n.right = left
+		n.update()
+
+		return n, right
+	default:
+		pos := k - leftSize
+
+		// same priority keeps heap order valid for right subtree
+		rightNode := &node{
+			text:     n.text[pos:],
+			newLines: strings.Count(n.text[pos:], "\n"),
+			priority: n.priority,
+			right:    n.right,
+		}
+		rightNode.update()
+
+		n.text = n.text[:pos]
+		n.newLines -= rightNode.newLines
+		n.right = nil
+		n.update()
+
+		return n, rightNode
+	}
+}
+
+func merge(left, right *node) *node {
+	if left == nil {
+		return right
+	}
+
+	if right == nil {
+		return left
+	}
+
+	if left.priority >= right.priority {
+		left.right = merge(left.right, right)
+		left.update()
+
+		return left
+	}
+
+	right.left = merge(left, right.left)
+	right.update()
+
+	return right
+}
@@ -0,0 +1,237 @@
+package rope_test
+
+import (
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/fakovacic/editor/internal/app/editor/rope"
+	"github.com/stretchr/testify/assert"
+)
+
+const bigCSS = "../../../../examples/assets/big.css"
+
+func TestRope(t *testing.T) {
+	cases := []struct {
+		it string
+
+		contents string
+		edit     func(r *rope.Rope) error
+
+		expectedResponse string
+		expectedError    string
+	}{
+		{
+			it:       "insert in middle",
+			contents: "pre {\n}",
+			edit: func(r *rope.Rope) error {
+				return r.Insert(6, "  display: block;\n")
+			},
+			expectedResponse: "pre {\n  display: block;\n}",
+		},
+		{
+			it:       "insert at end",
+			contents: "pre {",
+			edit: func(r *rope.Rope) error {
+				return r.Insert(5, "\n}")
+			},
+			expectedResponse: "pre {\n}",
+		},
+		{
+			it:       "insert into empty",
+			contents: "",
+			edit: func(r *rope.Rope) error {
+				return r.Insert(0, "pre {}")
+			},
+			expectedResponse: "pre {}",
+		},
+		{
+			it:       "insert out of range",
+			contents: "pre {}",
+			edit: func(r *rope.Rope) error {
+				return r.Insert(7, "}")
+			},
+			expectedResponse: "pre {}",
+			expectedError:    "offset 7 out of range",
+		},
+		{
+			it:       "remove range",
+			contents: "pre {\n  display: block;\n}",
+			edit: func(r *rope.Rope) error {
+				return r.Remove(6, 18)
+			},
+			expectedResponse: "pre {\n}",
+		},
+		{
+			it:       "remove out of range",
+			contents: "pre {}",
+			edit: func(r *rope.Rope) error {
+				return r.Remove(4, 3)
+			},
+			expectedResponse: "pre {}",
+			expectedError:    "range 4:7 out of range",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			r := rope.New(tc.contents)
+
+			err := tc.edit(r)
+			if err != nil {
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+
+			assert.Equal(t, tc.expectedResponse, r.String())
+			assert.Equal(t, len(tc.expectedResponse), r.Len())
+			assert.Equal(t, strings.Count(tc.expectedResponse, "\n")+1, r.Rows())
+		})
+	}
+}
+
+func TestRopeLines(t *testing.T) {
+	contents := "pre {\n  display: block;\n\n}\n"
+
+	r := rope.New(contents)
+
+	lines := strings.Split(contents, "\n")
+
+	assert.Equal(t, len(lines), r.Rows())
+
+	offset := 0
+
+	for row, expected := range lines {
+		start, err := r.LineStart(row)
+		assert.Nil(t, err)
+		assert.Equal(t, offset, start)
+
+		line, err := r.Line(row)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, line)
+
+		offset += len(expected) + 1
+	}
+
+	_, err := r.LineStart(len(lines))
+	assert.NotNil(t, err)
+}
+
+// TestRopeRandom compares rope with plain string edits on contents
+// big enough to be split across many chunks
+func TestRopeRandom(t *testing.T) {
+	contents, err := os.ReadFile(bigCSS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := string(contents)
+	r := rope.New(expected)
+
+	rnd := rand.New(rand.NewSource(1))
+	samples := []string{"a", "\n", "  color: red;\n", strings.Repeat("x", 3000)}
+
+	for i := 0; i < 2000; i++ {
+		offset := rnd.Intn(len(expected) + 1)
+
+		if rnd.Intn(2) == 0 {
+			text := samples[rnd.Intn(len(samples))]
+
+			err = r.Insert(offset, text)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected = expected[:offset] + text + expected[offset:]
+
+			continue
+		}
+
+		length := rnd.Intn(min(len(expected)-offset, 2500) + 1)
+
+		err = r.Remove(offset, length)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected = expected[:offset] + expected[offset+length:]
+	}
+
+	assert.Equal(t, expected, r.String())
+	assert.Equal(t, strings.Count(expected, "\n")+1, r.Rows())
+
+	lines := strings.Split(expected, "\n")
+
+	for _, row := range []int{0, len(lines) / 2, len(lines) - 1} {
+		line, err := r.Line(row)
+		assert.Nil(t, err)
+		assert.Equal(t, lines[row], line)
+	}
+}
+
+func BenchmarkRopeInsert(b *testing.B) {
+	contents, err := os.ReadFile(bigCSS)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	r := rope.New(string(contents))
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		row := i % (r.Rows() - 1)
+
+		offset, err := r.LineStart(row)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		err = r.Insert(offset, "a")
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkStringInsert is baseline, every insert copies whole contents
+func BenchmarkStringInsert(b *testing.B) {
+	contents, err := os.ReadFile(bigCSS)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	s := string(contents)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		offset := (i * 7919) % len(s)
+
+		s = s[:offset] + "a" + s[offset:]
+	}
+}
+
+func BenchmarkRopeRemove(b *testing.B) {
+	contents, err := os.ReadFile(bigCSS)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	r := rope.New(strings.Repeat(string(contents), 4))
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if r.Len() == 0 {
+			b.StopTimer()
+			r = rope.New(string(contents))
+			b.StartTimer()
+		}
+
+		err := r.Remove((i*7919)%r.Len(), 1)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package editor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor/diff"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/fakovacic/editor/internal/log"
+)
+
+// Fingerprint returns fingerprint of source version, empty if io can not
+// tell so source is read and compared on next write
+func Fingerprint(ctx context.Context, io IO) string {
+	fingerprint, err := io.Stat(ctx)
+	if err != nil {
+		log.Error(ctx, "io stat:", log.Err(err))
+
+		return ""
+	}
+
+	return fingerprint
+}
+
+// Changed reads source when its fingerprint differs from one of loaded
+// version, changed reports source contents differ from original ones
+func Changed(ctx context.Context, io IO, fingerprint, original string) (string, string, bool, error) {
+	current := Fingerprint(ctx, io)
+	if current != "" && current == fingerprint {
+		return "", fingerprint, false, nil
+	}
+
+	// source read on sync is not loaded, so it is no version
+	source, meta, err := io.Read(app.WithSync(ctx))
+	if err != nil {
+		return "", "", false, errors.Wrap(err, "io read")
+	}
+
+	return source, meta.Fingerprint, source != original, nil
+}
+
+// Merge merges edited contents with source changed since original was read,
+// edits and source changes of same lines conflict
+func Merge(name, original, contents, source string) (string, error) {
+	base, _ := Decode(original)
+	theirs, _ := Decode(source)
+
+	merged, ok := diff.Merge(base, contents, theirs)
+	if !ok {
+		return "", errors.Conflict("file '%s' changed on source, edits conflict", name)
+	}
+
+	return merged, nil
+}
+
+// sync merges source changed since load into contents,
+// returns changes applied to bring contents to merged ones
+func (s *editor) sync(ctx context.Context) ([]*app.ChangeMsg, error) {
+	source, fingerprint, changed, err := Changed(ctx, s.io, s.file.Meta.Fingerprint, s.file.Original)
+	if err != nil {
+		return nil, err
+	}
+
+	if !changed {
+		s.file.Meta.Fingerprint = fingerprint
+
+		return nil, nil
+	}
+
+	merged, err := Merge(s.file.Meta.Name, s.file.Original, s.file.Contents.String(), source)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := s.replace(merged)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply merge")
+	}
+
+	decoded, format := Decode(source)
+
+	s.file.Original = source
+	s.file.Format = format
+	s.file.Meta.Fingerprint = fingerprint
+
+	// contents without edits are same as source
+	if merged == decoded {
+		s.file.Saved = app.SaveState{
+			SavedRevision: s.file.Revision,
+		}
+	}
+
+	return changes, nil
+}
+
+// replace applies changed lines between contents and new contents,
+// last lines first so rows of earlier changes stay valid
+func (s *editor) replace(contents string) ([]*app.ChangeMsg, error) {
+	lines := diff.SplitLines(s.file.Contents.String())
+	hunks := diff.Lines(lines, diff.SplitLines(contents))
+
+	changes := make([]*app.ChangeMsg, 0)
+
+	for i := len(hunks) - 1; i >= 0; i-- {
+		start := app.ChangeRow{
+			Row: hunks[i].Start,
+		}
+
+		if hunks[i].End > hunks[i].Start {
+			removed := strings.Split(strings.Join(lines[hunks[i].Start:hunks[i].End], ""), "\n")
+
+			change := &app.ChangeMsg{
+				Action: OpRemove,
+				Start:  start,
+				End:    insertEnd(start, removed),
+				Lines:  removed,
+			}
+
+			err := s.apply(change, true)
+			if err != nil {
+				return nil, err
+			}
+
+			changes = append(changes, change)
+		}
+
+		if len(hunks[i].Lines) > 0 {
+			inserted := strings.Split(strings.Join(hunks[i].Lines, ""), "\n")
+
+			change := &app.ChangeMsg{
+				Action: OpInsert,
+				Start:  start,
+				End:    insertEnd(start, inserted),
+				Lines:  inserted,
+			}
+
+			err := s.apply(change, true)
+			if err != nil {
+				return nil, err
+			}
+
+			changes = append(changes, change)
+		}
+	}
+
+	return changes, nil
+}
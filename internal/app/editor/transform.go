@@ -0,0 +1,164 @@
+package editor
+
+import (
+	"github.com/fakovacic/editor/internal/app"
+)
+
+// Transform returns change adjusted to be applied after concurrent applied change.
+// When both insert at same position, first decides which text goes first,
+// server transforms incoming changes with first false, clients transform
+// server changes against their pending changes with first true.
+//
+// Insert inside removed range is kept at start of removed range and remove
+// is split around insert inside it, so both orders of applying end with same
+// contents. Changes with next parts are transformed part by part.
+func Transform(change, applied *app.ChangeMsg, first bool) *app.ChangeMsg {
+	for part := applied; part != nil; part = part.Next {
+		change = transformParts(change, part, first)
+	}
+
+	return change
+}
+
+// transformParts transforms each part of change against single applied change,
+// next part is transformed against applied moved after previous part
+func transformParts(change, applied *app.ChangeMsg, first bool) *app.ChangeMsg {
+	transformed := transform(change, applied, first)
+
+	if change.Next != nil {
+		last := transformed
+		for last.Next != nil {
+			last = last.Next
+		}
+
+		last.Next = Transform(change.Next, transform(applied, change, !first), first)
+	}
+
+	return transformed
+}
+
+// transform adjusts single part of change to be applied after single part
+// of applied change, remove is returned with next part when split
+func transform(change, applied *app.ChangeMsg, first bool) *app.ChangeMsg {
+	transformed := &app.ChangeMsg{
+		Action:   change.Action,
+		Lines:    change.Lines,
+		Revision: change.Revision,
+	}
+
+	switch change.Action {
+	case OpInsert:
+		transformed.Start = transformPoint(change.Start, applied, !first)
+		transformed.End = insertEnd(transformed.Start, transformed.Lines)
+	case OpRemove:
+		if applied.Action == OpInsert && isBefore(change.Start, applied.Start) && isBefore(applied.Start, change.End) {
+			return splitRemove(change, applied)
+		}
+
+		transformed.Start = transformPoint(change.Start, applied, true)
+		transformed.End = transformPoint(change.End, applied, false)
+
+		if isBefore(transformed.End, transformed.Start) {
+			transformed.End = transformed.Start
+		}
+	default:
+		transformed.Start = change.Start
+		transformed.End = change.End
+	}
+
+	return transformed
+}
+
+// splitRemove removes range before and after insert inside it, second part
+// is moved back by first one as it is applied after it
+func splitRemove(change, applied *app.ChangeMsg) *app.ChangeMsg {
+	before := &app.ChangeMsg{
+		Action:   OpRemove,
+		Start:    change.Start,
+		End:      applied.Start,
+		Lines:    change.Lines,
+		Revision: change.Revision,
+	}
+
+	before.Next = &app.ChangeMsg{
+		Action:   OpRemove,
+		Start:    transformPoint(insertEnd(applied.Start, applied.Lines), before, true),
+		End:      transformPoint(transformPoint(change.End, applied, false), before, false),
+		Lines:    change.Lines,
+		Revision: change.Revision,
+	}
+
+	return before
+}
+
+// transformPoint moves point by applied change, after decides if point
+// equal to insert start moves behind inserted text
+func transformPoint(point app.ChangeRow, applied *app.ChangeMsg, after bool) app.ChangeRow {
+	switch applied.Action {
+	case OpInsert:
+		if isBefore(point, applied.Start) || (point == applied.Start && !after) {
+			return point
+		}
+
+		end := insertEnd(applied.Start, applied.Lines)
+
+		if point.Row == applied.Start.Row {
+			return app.ChangeRow{
+				Row:    end.Row,
+				Column: end.Column + point.Column - applied.Start.Column,
+			}
+		}
+
+		return app.ChangeRow{
+			Row:    point.Row + end.Row - applied.Start.Row,
+			Column: point.Column,
+		}
+	case OpRemove:
+		if !isBefore(applied.Start, point) {
+			return point
+		}
+
+		if !isBefore(applied.End, point) {
+			return applied.Start
+		}
+
+		if point.Row == applied.End.Row {
+			return app.ChangeRow{
+				Row:    applied.Start.Row,
+				Column: applied.Start.Column + point.Column - applied.End.Column,
+			}
+		}
+
+		return app.ChangeRow{
+			Row:    point.Row - (applied.End.Row - applied.Start.Row),
+			Column: point.Column,
+		}
+	}
+
+	return point
+}
+
+// insertEnd returns position after inserted lines, columns are in UTF-16 code units
+func insertEnd(start app.ChangeRow, lines []string) app.ChangeRow {
+	if len(lines) <= 1 {
+		column := start.Column
+
+		if len(lines) == 1 {
+			column += columnLen(lines[0])
+		}
+
+		return app.ChangeRow{
+			Row:    start.Row,
+			Column: column,
+		}
+	}
+
+	return app.ChangeRow{
+		Row:    start.Row + len(lines) - 1,
+		Column: columnLen(lines[len(lines)-1]),
+	}
+}
+
+func isBefore(a, b app.ChangeRow) bool {
+	return a.Row < b.Row || (a.Row == b.Row && a.Column < b.Column)
+}
@@ -0,0 +1,457 @@
+package editor_test
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor"
+	"github.com/fakovacic/editor/internal/app/editor/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransform(t *testing.T) {
+	cases := []struct {
+		it string
+
+		change  *app.ChangeMsg
+		applied *app.ChangeMsg
+		first   bool
+
+		expectedResponse *app.ChangeMsg
+	}{
+		{
+			it: "insert after applied insert on same row",
+			change: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 0, Column: 5},
+				End:    app.ChangeRow{Row: 0, Column: 6},
+				Lines:  []string{"b"},
+			},
+			applied: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 0, Column: 2},
+				End:    app.ChangeRow{Row: 0, Column: 4},
+				Lines:  []string{"aa"},
+			},
+			expectedResponse: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 0, Column: 7},
+				End:    app.ChangeRow{Row: 0, Column: 8},
+				Lines:  []string{"b"},
+			},
+		},
+		{
+			it: "insert at same position goes after applied",
+			change: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 1, Column: 2},
+				End:    app.ChangeRow{Row: 1, Column: 3},
+				Lines:  []string{"b"},
+			},
+			applied: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 1, Column: 2},
+				End:    app.ChangeRow{Row: 1, Column: 3},
+				Lines:  []string{"a"},
+			},
+			expectedResponse: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 1, Column: 3},
+				End:    app.ChangeRow{Row: 1, Column: 4},
+				Lines:  []string{"b"},
+			},
+		},
+		{
+			it: "insert at same position goes first",
+			change: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 1, Column: 2},
+				End:    app.ChangeRow{Row: 1, Column: 3},
+				Lines:  []string{"b"},
+			},
+			applied: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 1, Column: 2},
+				End:    app.ChangeRow{Row: 1, Column: 3},
+				Lines:  []string{"a"},
+			},
+			first: true,
+			expectedResponse: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 1, Column: 2},
+				End:    app.ChangeRow{Row: 1, Column: 3},
+				Lines:  []string{"b"},
+			},
+		},
+		{
+			it: "insert after applied new lines",
+			change: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 3, Column: 1},
+				End:    app.ChangeRow{Row: 3, Column: 2},
+				Lines:  []string{"b"},
+			},
+			applied: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 0, Column: 0},
+				End:    app.ChangeRow{Row: 2, Column: 0},
+				Lines:  []string{"", "", ""},
+			},
+			expectedResponse: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 5, Column: 1},
+				End:    app.ChangeRow{Row: 5, Column: 2},
+				Lines:  []string{"b"},
+			},
+		},
+		{
+			it: "insert after applied remove of rows",
+			change: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 4, Column: 6},
+				End:    app.ChangeRow{Row: 4, Column: 7},
+				Lines:  []string{"b"},
+			},
+			applied: &app.ChangeMsg{
+				Action: "remove",
+				Start:  app.ChangeRow{Row: 1, Column: 3},
+				End:    app.ChangeRow{Row: 4, Column: 2},
+				Lines:  []string{"", "", "", ""},
+			},
+			expectedResponse: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 1, Column: 7},
+				End:    app.ChangeRow{Row: 1, Column: 8},
+				Lines:  []string{"b"},
+			},
+		},
+		{
+			it: "insert inside applied remove is kept at its start",
+			change: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 0, Column: 4},
+				End:    app.ChangeRow{Row: 0, Column: 5},
+				Lines:  []string{"b"},
+			},
+			applied: &app.ChangeMsg{
+				Action: "remove",
+				Start:  app.ChangeRow{Row: 0, Column: 2},
+				End:    app.ChangeRow{Row: 0, Column: 6},
+				Lines:  []string{"aaaa"},
+			},
+			expectedResponse: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 0, Column: 2},
+				End:    app.ChangeRow{Row: 0, Column: 3},
+				Lines:  []string{"b"},
+			},
+		},
+		{
+			it: "remove is split around applied insert inside it",
+			change: &app.ChangeMsg{
+				Action: "remove",
+				Start:  app.ChangeRow{Row: 0, Column: 2},
+				End:    app.ChangeRow{Row: 1, Column: 2},
+				Lines:  []string{"aa", "aa"},
+			},
+			applied: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 0, Column: 3},
+				End:    app.ChangeRow{Row: 1, Column: 1},
+				Lines:  []string{"b", "b"},
+			},
+			expectedResponse: &app.ChangeMsg{
+				Action: "remove",
+				Start:  app.ChangeRow{Row: 0, Column: 2},
+				End:    app.ChangeRow{Row: 0, Column: 3},
+				Lines:  []string{"aa", "aa"},
+				Next: &app.ChangeMsg{
+					Action: "remove",
+					Start:  app.ChangeRow{Row: 1, Column: 1},
+					End:    app.ChangeRow{Row: 2, Column: 2},
+					Lines:  []string{"aa", "aa"},
+				},
+			},
+		},
+		{
+			it: "remove overlapping applied remove",
+			change: &app.ChangeMsg{
+				Action: "remove",
+				Start:  app.ChangeRow{Row: 0, Column: 2},
+				End:    app.ChangeRow{Row: 0, Column: 5},
+				Lines:  []string{"cde"},
+			},
+			applied: &app.ChangeMsg{
+				Action: "remove",
+				Start:  app.ChangeRow{Row: 0, Column: 1},
+				End:    app.ChangeRow{Row: 0, Column: 4},
+				Lines:  []string{"bcd"},
+			},
+			expectedResponse: &app.ChangeMsg{
+				Action: "remove",
+				Start:  app.ChangeRow{Row: 0, Column: 1},
+				End:    app.ChangeRow{Row: 0, Column: 2},
+				Lines:  []string{"cde"},
+			},
+		},
+		{
+			it: "remove keeps insert at its start",
+			change: &app.ChangeMsg{
+				Action: "remove",
+				Start:  app.ChangeRow{Row: 0, Column: 2},
+				End:    app.ChangeRow{Row: 0, Column: 4},
+				Lines:  []string{"cd"},
+			},
+			applied: &app.ChangeMsg{
+				Action: "insert",
+				Start:  app.ChangeRow{Row: 0, Column: 2},
+				End:    app.ChangeRow{Row: 0, Column: 3},
+				Lines:  []string{"a"},
+			},
+			first: true,
+			expectedResponse: &app.ChangeMsg{
+				Action: "remove",
+				Start:  app.ChangeRow{Row: 0, Column: 3},
+				End:    app.ChangeRow{Row: 0, Column: 5},
+				Lines:  []string{"cd"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			res := editor.Transform(tc.change, tc.applied, tc.first)
+
+			assert.Equal(t, tc.expectedResponse, res)
+		})
+	}
+}
+
+// TestTransformConverge applies random concurrent changes in both orders,
+// server and client must end with same contents
+func TestTransformConverge(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 5000; i++ {
+		contents := randomText(rnd, 12)
+
+		client := randomChange(rnd, contents)
+		server := randomChange(rnd, contents)
+
+		// server applied its change first, client change comes after it
+		onServer := applyChange(applyChange(contents, server), editor.Transform(client, server, false))
+
+		// client applied own change, server change goes first
+		onClient := applyChange(applyChange(contents, client), editor.Transform(server, client, true))
+
+		if onServer != onClient {
+			t.Fatalf("contents %q client %+v server %+v: %q != %q", contents, client, server, onServer, onClient)
+		}
+	}
+}
+
+func TestChangeConcurrent(t *testing.T) {
+	io := &mocks.IOMock{
+		ReadFunc: func(ctx context.Context) (string, *app.FileMeta, error) {
+			return "a {\n}", &app.FileMeta{}, nil
+		},
+	}
+
+	editor := editor.New(io)
+
+	err := editor.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// both clients change revision 0
+	first, err := editor.Change(context.Background(), &app.ChangeMsg{
+		Action: "insert",
+		Start:  app.ChangeRow{Row: 0, Column: 0},
+		End:    app.ChangeRow{Row: 0, Column: 4},
+		Lines:  []string{"pre "},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, first.Revision)
+
+	second, err := editor.Change(context.Background(), &app.ChangeMsg{
+		Action: "insert",
+		Start:  app.ChangeRow{Row: 0, Column: 3},
+		End:    app.ChangeRow{Row: 1, Column: 0},
+		Lines:  []string{"", ""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 2, second.Revision)
+	assert.Equal(t, app.ChangeRow{Row: 0, Column: 7}, second.Start)
+
+	res, meta, err := editor.Read(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "pre a {\n\n}", res)
+	assert.Equal(t, 2, meta.Revision)
+
+	_, err = editor.Change(context.Background(), &app.ChangeMsg{
+		Action:   "insert",
+		Lines:    []string{"a"},
+		Revision: 3,
+	})
+	assert.NotNil(t, err)
+}
+
+func TestChangeSplit(t *testing.T) {
+	io := &mocks.IOMock{
+		ReadFunc: func(ctx context.Context) (string, *app.FileMeta, error) {
+			return "abcd", &app.FileMeta{}, nil
+		},
+	}
+
+	e := editor.New(io)
+
+	err := e.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inserted, err := e.Change(context.Background(), &app.ChangeMsg{
+		Action: "insert",
+		Start:  app.ChangeRow{Row: 0, Column: 2},
+		End:    app.ChangeRow{Row: 0, Column: 3},
+		Lines:  []string{"x"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// remove of revision 0 is split around insert, both parts are one revision
+	removed, err := e.Change(context.Background(), &app.ChangeMsg{
+		Action: "remove",
+		Start:  app.ChangeRow{Row: 0, Column: 1},
+		End:    app.ChangeRow{Row: 0, Column: 3},
+		Lines:  []string{"bc"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 2, removed.Revision)
+	assert.Equal(t, []string{"b"}, removed.Lines)
+	assert.Equal(t, []string{"c"}, removed.Next.Lines)
+
+	res, _, err := e.Read(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "axd", res)
+
+	replayed, err := editor.Replay("abcd", "", []*app.ChangeMsg{inserted, removed})
+	assert.NoError(t, err)
+	assert.Equal(t, "axd", replayed)
+}
+
+// randomText mixes ascii with accented and emoji chars,
+// emoji takes two UTF-16 columns
+func randomText(rnd *rand.Rand, size int) string {
+	chars := []rune("ab\né😀")
+	text := make([]rune, rnd.Intn(size))
+
+	for i := range text {
+		text[i] = chars[rnd.Intn(len(chars))]
+	}
+
+	return string(text)
+}
+
+func randomChange(rnd *rand.Rand, contents string) *app.ChangeMsg {
+	// offsets at char boundaries
+	offsets := make([]int, 0, len(contents)+1)
+	for i := range contents {
+		offsets = append(offsets, i)
+	}
+
+	offsets = append(offsets, len(contents))
+
+	i := rnd.Intn(len(offsets))
+	start := offsets[i]
+
+	if rnd.Intn(2) == 0 {
+		text := randomText(rnd, 4)
+
+		return &app.ChangeMsg{
+			Action: "insert",
+			Start:  position(contents, start),
+			End:    position(contents[:start]+text, start+len(text)),
+			Lines:  strings.Split(text, "\n"),
+		}
+	}
+
+	end := offsets[i+rnd.Intn(len(offsets)-i)]
+
+	return &app.ChangeMsg{
+		Action: "remove",
+		Start:  position(contents, start),
+		End:    position(contents, end),
+		Lines:  strings.Split(contents[start:end], "\n"),
+	}
+}
+
+// position converts byte offset to row and UTF-16 column
+func position(contents string, offset int) app.ChangeRow {
+	before := contents[:offset]
+	line := before[strings.LastIndex(before, "\n")+1:]
+
+	return app.ChangeRow{
+		Row:    strings.Count(before, "\n"),
+		Column: len(utf16.Encode([]rune(line))),
+	}
+}
+
+// offset converts row and UTF-16 column to byte offset
+func offset(contents string, pos app.ChangeRow) int {
+	lines := strings.Split(contents, "\n")
+	offset := 0
+
+	for _, line := range lines[:pos.Row] {
+		offset += len(line) + 1
+	}
+
+	column := 0
+
+	for i, r := range lines[pos.Row] {
+		if column == pos.Column {
+			return offset + i
+		}
+
+		column += len(utf16.Encode([]rune{r}))
+	}
+
+	return offset + len(lines[pos.Row])
+}
+
+func applyChange(contents string, change *app.ChangeMsg) string {
+	for part := change; part != nil; part = part.Next {
+		start := offset(contents, part.Start)
+
+		if part.Action == "insert" {
+			contents = contents[:start] + strings.Join(part.Lines, "\n") + contents[start:]
+
+			continue
+		}
+
+		contents = contents[:start] + contents[offset(contents, part.End):]
+	}
+
+	return contents
+}
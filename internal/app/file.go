@@ -2,13 +2,68 @@ package app
 
 import (
 	"strings"
+	"time"
 
 	"github.com/fakovacic/editor/internal/errors"
 )
 
 type FileMeta struct {
-	Name      string   `json:"name"`
-	Extension FileType `json:"extension"`
+	Name      string     `json:"name"`
+	Path      string     `json:"path"` // name in workspace
+	Extension FileType   `json:"extension"`
+	Revision  int        `json:"revision"`
+	Mode      EditorMode `json:"mode"`
+	Format    FileFormat `json:"format"`
+	SaveState
+
+	// Fingerprint identifies source version read, as modification time
+	// and size or etag, empty if io can not tell
+	Fingerprint string `json:"-"`
+}
+
+// SaveState tells if contents changed since they were last written,
+// saved revision is revision of contents last written
+type SaveState struct {
+	Dirty         bool `json:"dirty"`
+	SavedRevision int  `json:"savedRevision"`
+}
+
+// FileInfo is file listed in workspace, name is path relative to workspace root
+type FileInfo struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Type    FileType  `json:"type"`
+}
+
+// FileFormat is whitespace style detected on load, contents are edited
+// with LF line endings and without BOM and written back in this format
+type FileFormat struct {
+	IndentTabs      bool       `json:"indentTabs"`
+	IndentSize      int        `json:"indentSize"`
+	LineEnding      LineEnding `json:"lineEnding"`
+	TrailingNewline bool       `json:"trailingNewline"`
+	BOM             bool       `json:"bom"`
+}
+
+type LineEnding string
+
+const (
+	LF   LineEnding = "lf"
+	CRLF LineEnding = "crlf"
+)
+
+func (t LineEnding) String() string {
+	return string(t)
+}
+
+// Separator returns chars ending line
+func (t LineEnding) Separator() string {
+	if t == CRLF {
+		return "\r\n"
+	}
+
+	return "\n"
 }
 
 type FileType string
@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"sync"
 )
 
 //go:generate moq -out ./mocks/ws_conn.go -pkg mocks  . WSConn
@@ -23,6 +24,12 @@ type Hub interface {
 	Unregister(*Client)
 
 	CountRegistered() int
+
+	// Usernames returns usernames of registered clients, sorted
+	Usernames() []string
+
+	// Ready returns usernames of registered clients set ready, sorted
+	Ready() []string
 	Brodcast(context.Context, MsgType, string, string, string, *FileMeta) error
 }
 
@@ -34,6 +41,19 @@ type Client struct {
 	Registered bool
 	Position   *Position
 	Conn       WSConn
+
+	// users hub and session hub write to same connection,
+	// connection allows only one writer at a time
+	write sync.Mutex
+}
+
+// WriteMessage writes message to client connection, writes from
+// different hubs are serialized
+func (c *Client) WriteMessage(messageType int, data []byte) error {
+	c.write.Lock()
+	defer c.write.Unlock()
+
+	return c.Conn.WriteMessage(messageType, data)
 }
 
 type Position struct {
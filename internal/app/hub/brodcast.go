@@ -11,24 +11,40 @@ import (
 )
 
 type BrodcastMsg struct {
-	Data     string        `json:"data,omitempty"`
-	FileMeta *app.FileMeta `json:"fileMeta,omitempty"`
-	Type     app.MsgType   `json:"type"`
-	Client   string        `json:"client"`
-	Clients  []Client      `json:"clients"`
+	Data     string         `json:"data,omitempty"`
+	FileMeta *app.FileMeta  `json:"fileMeta,omitempty"`
+	Saved    *app.SaveState `json:"saved,omitempty"`
+	Type     app.MsgType    `json:"type"`
+	Client   string         `json:"client"`
+	Clients  []Client       `json:"clients"`
 }
 
-func (h *hub) Brodcast(_ context.Context, msgType app.MsgType, clientID, username, msg string, fileMeta *app.FileMeta) error {
+func (h *hub) Brodcast(ctx context.Context, msgType app.MsgType, clientID, username, msg string, fileMeta *app.FileMeta) error {
 	if len(h.clients) == 0 {
 		return nil
 	}
 
+	// save state is read before hub is locked, so hub never waits for editor
+	var saved *app.SaveState
+
+	if h.editor != nil && changesSaveState(msgType) {
+		meta := h.editor.FileMeta(ctx)
+		if meta != nil {
+			saved = &meta.SaveState
+		}
+	}
+
 	h.Lock()
 	defer h.Unlock()
 
 	for _, client := range h.clients {
+		// client logged in, but not connected yet
+		if client.Conn == nil {
+			continue
+		}
+
 		switch msgType {
-		case app.MsgConnected, app.MsgConnDisconnect, app.MsgConnNotReady, app.MsgConnNotUnready, app.MsgServerFileNotReady:
+		case app.MsgConnected, app.MsgConnDisconnect, app.MsgConnNotReady, app.MsgConnNotUnready, app.MsgServerFileNotReady, app.MsgServerTextChangeAck, app.MsgServerCRDTState, app.MsgServerResync, app.MsgServerFileNotOpened, app.MsgServerFileNotCreated, app.MsgServerFileNotRenamed, app.MsgServerFileNotDeleted, app.MsgServerVersions, app.MsgServerVersion, app.MsgServerVersionDiff, app.MsgServerVersionFailed:
 			// only send to the client who sent the message
 			if client.ID != clientID {
 				continue
@@ -40,7 +56,7 @@ func (h *hub) Brodcast(_ context.Context, msgType app.MsgType, clientID, usernam
 				continue
 			}
 
-		case app.MsgServerFileNotSaved, app.MsgServerFileSaved, app.MsgClientsReady, app.MsgClientsUnready:
+		case app.MsgServerFileNotSaved, app.MsgServerFileSaved, app.MsgServerFileAutosaved, app.MsgServerFileMerged, app.MsgServerFileReloaded, app.MsgServerFileConflict, app.MsgServerFiles, app.MsgServerShutdown, app.MsgServerVersionRestored, app.MsgClientsReady, app.MsgClientsUnready:
 			// send to all clients
 		default:
 			continue
@@ -49,6 +65,7 @@ func (h *hub) Brodcast(_ context.Context, msgType app.MsgType, clientID, usernam
 		msg, err := json.Marshal(BrodcastMsg{
 			Data:     msg,
 			FileMeta: fileMeta,
+			Saved:    saved,
 			Client:   username,
 			Type:     msgType,
 			Clients:  h.viewClients(),
@@ -57,7 +74,7 @@ func (h *hub) Brodcast(_ context.Context, msgType app.MsgType, clientID, usernam
 			return errors.Wrap(err, "marshaling message")
 		}
 
-		err = client.Conn.WriteMessage(websocket.TextMessage, msg)
+		err = client.WriteMessage(websocket.TextMessage, msg)
 		if err != nil {
 			return errors.Wrap(err, "writing message to client '%s'", client.ID)
 		}
@@ -66,6 +83,17 @@ func (h *hub) Brodcast(_ context.Context, msgType app.MsgType, clientID, usernam
 	return nil
 }
 
+// changesSaveState reports if message is sent after contents changed or
+// were written, only these messages carry save state of file
+func changesSaveState(msgType app.MsgType) bool {
+	switch msgType {
+	case app.MsgClientsTextChange, app.MsgServerTextChangeAck, app.MsgServerFileSaved, app.MsgServerFileAutosaved, app.MsgServerFileMerged, app.MsgServerFileReloaded:
+		return true
+	default:
+		return false
+	}
+}
+
 func (h *hub) viewClients() []Client {
 	viewClients := make([]Client, 0)
 
@@ -3,7 +3,10 @@ package hub_test
 import (
 	"context"
 	"encoding/json"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/fakovacic/editor/internal/app"
 	"github.com/fakovacic/editor/internal/app/hub"
@@ -151,6 +154,74 @@ func TestBrodcast(t *testing.T) {
 				},
 			},
 		},
+		{
+			it: "send message server-text-change-ack",
+
+			clients: []*app.Client{
+				{
+					ID:       "mock-id",
+					Username: "mock-username",
+					Color:    "mock-color",
+				},
+				{
+					ID:       "mock-id-next",
+					Username: "mock-username-next",
+					Color:    "mock-color-next",
+				},
+			},
+
+			msgType:  app.MsgServerTextChangeAck,
+			clientID: "mock-id",
+			username: "mock-username",
+			msg:      "mock-message",
+			fileMeta: nil,
+
+			expectedMsgs: map[string]hub.BrodcastMsg{
+				"mock-id": {
+					Data:     "mock-message",
+					FileMeta: nil,
+					Type:     app.MsgServerTextChangeAck,
+					Client:   "mock-username",
+				},
+			},
+		},
+		{
+			it: "send message server-resync",
+
+			clients: []*app.Client{
+				{
+					ID:       "mock-id",
+					Username: "mock-username",
+					Color:    "mock-color",
+				},
+				{
+					ID:       "mock-id-next",
+					Username: "mock-username-next",
+					Color:    "mock-color-next",
+				},
+			},
+
+			msgType:  app.MsgServerResync,
+			clientID: "mock-id",
+			username: "mock-username",
+			msg:      "mock-message",
+			fileMeta: &app.FileMeta{
+				Name:     "mock-name",
+				Revision: 2,
+			},
+
+			expectedMsgs: map[string]hub.BrodcastMsg{
+				"mock-id": {
+					Data: "mock-message",
+					FileMeta: &app.FileMeta{
+						Name:     "mock-name",
+						Revision: 2,
+					},
+					Type:   app.MsgServerResync,
+					Client: "mock-username",
+				},
+			},
+		},
 		{
 			it: "send message clients-connected",
 
@@ -281,6 +352,41 @@ func TestBrodcast(t *testing.T) {
 				},
 			},
 		},
+		{
+			it: "send message server-files",
+
+			clients: []*app.Client{
+				{
+					ID:       "mock-id",
+					Username: "mock-username",
+					Color:    "mock-color",
+				},
+				{
+					ID:       "mock-id-next",
+					Username: "mock-username-next",
+					Color:    "mock-color-next",
+				},
+			},
+
+			msgType:  app.MsgServerFiles,
+			clientID: "",
+			username: "",
+			msg:      "mock-message",
+			fileMeta: nil,
+
+			expectedMsgs: map[string]hub.BrodcastMsg{
+				"mock-id": {
+					Data:     "mock-message",
+					FileMeta: nil,
+					Type:     app.MsgServerFiles,
+				},
+				"mock-id-next": {
+					Data:     "mock-message",
+					FileMeta: nil,
+					Type:     app.MsgServerFiles,
+				},
+			},
+		},
 		{
 			it: "send message conn-disconnect",
 
@@ -309,7 +415,7 @@ func TestBrodcast(t *testing.T) {
 		t.Run(tc.it, func(t *testing.T) {
 			colorList := colors.New()
 
-			hb := hub.New(colorList)
+			hb := hub.New(colorList, nil)
 
 			for cl := range tc.clients {
 				conn := &mocks.WSConnMock{
@@ -347,3 +453,141 @@ func TestBrodcast(t *testing.T) {
 		})
 	}
 }
+
+func TestBrodcastSaveState(t *testing.T) {
+	cases := []struct {
+		it string
+
+		editor  app.Editor
+		msgType app.MsgType
+
+		expected *app.SaveState
+	}{
+		{
+			it:      "unsaved changes of file",
+			msgType: app.MsgClientsTextChange,
+			editor: &mocks.EditorMock{
+				FileMetaFunc: func(_ context.Context) *app.FileMeta {
+					return &app.FileMeta{
+						Revision: 4,
+						SaveState: app.SaveState{
+							Dirty:         true,
+							SavedRevision: 2,
+						},
+					}
+				},
+			},
+
+			expected: &app.SaveState{
+				Dirty:         true,
+				SavedRevision: 2,
+			},
+		},
+		{
+			it:      "file not loaded",
+			msgType: app.MsgServerFileSaved,
+			editor: &mocks.EditorMock{
+				FileMetaFunc: func(_ context.Context) *app.FileMeta {
+					return nil
+				},
+			},
+		},
+		{
+			it:      "logged in clients",
+			msgType: app.MsgServerFileSaved,
+		},
+		{
+			it:      "cursor change does not read save state",
+			msgType: app.MsgClientsCursorChange,
+			editor:  &mocks.EditorMock{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			hb := hub.New(colors.New(), tc.editor)
+
+			msgs := make([]hub.BrodcastMsg, 0)
+
+			hb.Register(&app.Client{
+				ID: "mock-id",
+				Conn: &mocks.WSConnMock{
+					WriteMessageFunc: func(_ int, data []byte) error {
+						var msg hub.BrodcastMsg
+
+						err := json.Unmarshal(data, &msg)
+						if err != nil {
+							return err
+						}
+
+						msgs = append(msgs, msg)
+
+						return nil
+					},
+				},
+			})
+
+			err := hb.Brodcast(context.Background(), tc.msgType, "", "", "", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Len(t, msgs, 1)
+			assert.Equal(t, tc.expected, msgs[0].Saved)
+		})
+	}
+}
+
+// TestBrodcastSharedConn brodcasts from users hub and session hub at
+// same time, connection must never be written concurrently
+func TestBrodcastSharedConn(t *testing.T) {
+	var (
+		writing    atomic.Int32
+		concurrent atomic.Bool
+	)
+
+	client := &app.Client{
+		ID: "mock-id",
+		Conn: &mocks.WSConnMock{
+			WriteMessageFunc: func(_ int, _ []byte) error {
+				if writing.Add(1) > 1 {
+					concurrent.Store(true)
+				}
+
+				time.Sleep(time.Microsecond)
+				writing.Add(-1)
+
+				return nil
+			},
+		},
+	}
+
+	users := hub.New(colors.New(), nil)
+	users.Create(client)
+
+	session := hub.New(colors.New(), nil)
+	session.Register(client)
+
+	var wg sync.WaitGroup
+
+	for _, hb := range []app.Hub{users, session} {
+		wg.Add(1)
+
+		go func(hb app.Hub) {
+			defer wg.Done()
+
+			for i := 0; i < 100; i++ {
+				err := hb.Brodcast(context.Background(), app.MsgServerFiles, "", "", "", nil)
+				if err != nil {
+					t.Error(err)
+
+					return
+				}
+			}
+		}(hb)
+	}
+
+	wg.Wait()
+
+	assert.False(t, concurrent.Load())
+}
@@ -1,6 +1,7 @@
 package hub
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/fakovacic/editor/internal/app"
@@ -11,9 +12,12 @@ type Colors interface {
 	Release(string)
 }
 
-func New(colors Colors) app.Hub {
+// New returns hub of clients, editor is of file clients edit so every
+// message tells if file has unsaved changes, nil for logged in clients
+func New(colors Colors, editor app.Editor) app.Hub {
 	return &hub{
 		colors:  colors,
+		editor:  editor,
 		clients: make(map[string]*app.Client),
 	}
 }
@@ -21,6 +25,7 @@ func New(colors Colors) app.Hub {
 type hub struct {
 	clients map[string]*app.Client
 	colors  Colors
+	editor  app.Editor
 	sync.Mutex
 }
 
@@ -129,3 +134,37 @@ func (h *hub) CountRegistered() int {
 
 	return i
 }
+
+func (h *hub) Usernames() []string {
+	h.Lock()
+	defer h.Unlock()
+
+	usernames := make([]string, 0, len(h.clients))
+
+	for _, client := range h.clients {
+		if client.Registered {
+			usernames = append(usernames, client.Username)
+		}
+	}
+
+	sort.Strings(usernames)
+
+	return usernames
+}
+
+func (h *hub) Ready() []string {
+	h.Lock()
+	defer h.Unlock()
+
+	usernames := make([]string, 0, len(h.clients))
+
+	for _, client := range h.clients {
+		if client.Registered && client.Ready {
+			usernames = append(usernames, client.Username)
+		}
+	}
+
+	sort.Strings(usernames)
+
+	return usernames
+}
@@ -11,7 +11,7 @@ import (
 func TestHub(t *testing.T) {
 	colorList := colors.New()
 
-	hub := hub.New(colorList)
+	hub := hub.New(colorList, nil)
 
 	hub.Create(&app.Client{
 		ID:       "mock-id",
@@ -47,8 +47,22 @@ func TestHub(t *testing.T) {
 		t.Fatal("registered count not valid")
 	}
 
+	usernames := hub.Usernames()
+	if len(usernames) != 1 || usernames[0] != "mock-username" {
+		t.Fatal("usernames not valid")
+	}
+
+	if len(hub.Ready()) != 0 {
+		t.Fatal("ready must be empty")
+	}
+
 	hub.SetReady("mock-id", true)
 
+	ready := hub.Ready()
+	if len(ready) != 1 || ready[0] != "mock-username" {
+		t.Fatal("ready not valid")
+	}
+
 	hub.Unregister(&app.Client{
 		ID:       "mock-id",
 		Username: "mock-username",
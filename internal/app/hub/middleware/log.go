@@ -43,6 +43,14 @@ func (m *logMiddleware) CountRegistered() int {
 	return m.next.CountRegistered()
 }
 
+func (m *logMiddleware) Usernames() []string {
+	return m.next.Usernames()
+}
+
+func (m *logMiddleware) Ready() []string {
+	return m.next.Ready()
+}
+
 func (m *logMiddleware) Create(client *app.Client) {
 	m.next.Create(client)
 }
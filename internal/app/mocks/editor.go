@@ -19,22 +19,34 @@ var _ app.Editor = &EditorMock{}
 //
 //		// make and configure a mocked app.Editor
 //		mockedEditor := &EditorMock{
-//			ChangeFunc: func(contextMoqParam context.Context, changeMsg *app.ChangeMsg) error {
+//			ChangeFunc: func(contextMoqParam context.Context, changeMsg *app.ChangeMsg) (*app.ChangeMsg, error) {
 //				panic("mock out the Change method")
 //			},
+//			EncodedFunc: func(contextMoqParam context.Context) (string, error) {
+//				panic("mock out the Encoded method")
+//			},
 //			FileMetaFunc: func(contextMoqParam context.Context) *app.FileMeta {
 //				panic("mock out the FileMeta method")
 //			},
 //			LoadFunc: func(contextMoqParam context.Context) error {
 //				panic("mock out the Load method")
 //			},
-//			ReadFunc: func(contextMoqParam context.Context) (string, error) {
+//			ReadFunc: func(contextMoqParam context.Context) (string, *app.FileMeta, error) {
 //				panic("mock out the Read method")
 //			},
+//			ReloadFunc: func(contextMoqParam context.Context) ([]*app.ChangeMsg, error) {
+//				panic("mock out the Reload method")
+//			},
+//			ReplaceFunc: func(contextMoqParam context.Context, s string) ([]*app.ChangeMsg, error) {
+//				panic("mock out the Replace method")
+//			},
+//			StateFunc: func(contextMoqParam context.Context) (string, error) {
+//				panic("mock out the State method")
+//			},
 //			UnloadFunc: func(contextMoqParam context.Context) error {
 //				panic("mock out the Unload method")
 //			},
-//			WriteFunc: func(contextMoqParam context.Context) error {
+//			WriteFunc: func(contextMoqParam context.Context) ([]*app.ChangeMsg, error) {
 //				panic("mock out the Write method")
 //			},
 //		}
@@ -45,7 +57,10 @@ var _ app.Editor = &EditorMock{}
 //	}
 type EditorMock struct {
 	// ChangeFunc mocks the Change method.
-	ChangeFunc func(contextMoqParam context.Context, changeMsg *app.ChangeMsg) error
+	ChangeFunc func(contextMoqParam context.Context, changeMsg *app.ChangeMsg) (*app.ChangeMsg, error)
+
+	// EncodedFunc mocks the Encoded method.
+	EncodedFunc func(contextMoqParam context.Context) (string, error)
 
 	// FileMetaFunc mocks the FileMeta method.
 	FileMetaFunc func(contextMoqParam context.Context) *app.FileMeta
@@ -54,13 +69,22 @@ type EditorMock struct {
 	LoadFunc func(contextMoqParam context.Context) error
 
 	// ReadFunc mocks the Read method.
-	ReadFunc func(contextMoqParam context.Context) (string, error)
+	ReadFunc func(contextMoqParam context.Context) (string, *app.FileMeta, error)
+
+	// ReloadFunc mocks the Reload method.
+	ReloadFunc func(contextMoqParam context.Context) ([]*app.ChangeMsg, error)
+
+	// ReplaceFunc mocks the Replace method.
+	ReplaceFunc func(contextMoqParam context.Context, s string) ([]*app.ChangeMsg, error)
+
+	// StateFunc mocks the State method.
+	StateFunc func(contextMoqParam context.Context) (string, error)
 
 	// UnloadFunc mocks the Unload method.
 	UnloadFunc func(contextMoqParam context.Context) error
 
 	// WriteFunc mocks the Write method.
-	WriteFunc func(contextMoqParam context.Context) error
+	WriteFunc func(contextMoqParam context.Context) ([]*app.ChangeMsg, error)
 
 	// calls tracks calls to the methods.
 	calls struct {
@@ -71,6 +95,11 @@ type EditorMock struct {
 			// ChangeMsg is the changeMsg argument value.
 			ChangeMsg *app.ChangeMsg
 		}
+		// Encoded holds details about calls to the Encoded method.
+		Encoded []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+		}
 		// FileMeta holds details about calls to the FileMeta method.
 		FileMeta []struct {
 			// ContextMoqParam is the contextMoqParam argument value.
@@ -86,6 +115,23 @@ type EditorMock struct {
 			// ContextMoqParam is the contextMoqParam argument value.
 			ContextMoqParam context.Context
 		}
+		// Reload holds details about calls to the Reload method.
+		Reload []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+		}
+		// Replace holds details about calls to the Replace method.
+		Replace []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// S is the s argument value.
+			S string
+		}
+		// State holds details about calls to the State method.
+		State []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+		}
 		// Unload holds details about calls to the Unload method.
 		Unload []struct {
 			// ContextMoqParam is the contextMoqParam argument value.
@@ -98,15 +144,19 @@ type EditorMock struct {
 		}
 	}
 	lockChange   sync.RWMutex
+	lockEncoded  sync.RWMutex
 	lockFileMeta sync.RWMutex
 	lockLoad     sync.RWMutex
 	lockRead     sync.RWMutex
+	lockReload   sync.RWMutex
+	lockReplace  sync.RWMutex
+	lockState    sync.RWMutex
 	lockUnload   sync.RWMutex
 	lockWrite    sync.RWMutex
 }
 
 // Change calls ChangeFunc.
-func (mock *EditorMock) Change(contextMoqParam context.Context, changeMsg *app.ChangeMsg) error {
+func (mock *EditorMock) Change(contextMoqParam context.Context, changeMsg *app.ChangeMsg) (*app.ChangeMsg, error) {
 	if mock.ChangeFunc == nil {
 		panic("EditorMock.ChangeFunc: method is nil but Editor.Change was just called")
 	}
@@ -141,6 +191,38 @@ func (mock *EditorMock) ChangeCalls() []struct {
 	return calls
 }
 
+// Encoded calls EncodedFunc.
+func (mock *EditorMock) Encoded(contextMoqParam context.Context) (string, error) {
+	if mock.EncodedFunc == nil {
+		panic("EditorMock.EncodedFunc: method is nil but Editor.Encoded was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+	}{
+		ContextMoqParam: contextMoqParam,
+	}
+	mock.lockEncoded.Lock()
+	mock.calls.Encoded = append(mock.calls.Encoded, callInfo)
+	mock.lockEncoded.Unlock()
+	return mock.EncodedFunc(contextMoqParam)
+}
+
+// EncodedCalls gets all the calls that were made to Encoded.
+// Check the length with:
+//
+//	len(mockedEditor.EncodedCalls())
+func (mock *EditorMock) EncodedCalls() []struct {
+	ContextMoqParam context.Context
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+	}
+	mock.lockEncoded.RLock()
+	calls = mock.calls.Encoded
+	mock.lockEncoded.RUnlock()
+	return calls
+}
+
 // FileMeta calls FileMetaFunc.
 func (mock *EditorMock) FileMeta(contextMoqParam context.Context) *app.FileMeta {
 	if mock.FileMetaFunc == nil {
@@ -206,7 +288,7 @@ func (mock *EditorMock) LoadCalls() []struct {
 }
 
 // Read calls ReadFunc.
-func (mock *EditorMock) Read(contextMoqParam context.Context) (string, error) {
+func (mock *EditorMock) Read(contextMoqParam context.Context) (string, *app.FileMeta, error) {
 	if mock.ReadFunc == nil {
 		panic("EditorMock.ReadFunc: method is nil but Editor.Read was just called")
 	}
@@ -237,6 +319,106 @@ func (mock *EditorMock) ReadCalls() []struct {
 	return calls
 }
 
+// Reload calls ReloadFunc.
+func (mock *EditorMock) Reload(contextMoqParam context.Context) ([]*app.ChangeMsg, error) {
+	if mock.ReloadFunc == nil {
+		panic("EditorMock.ReloadFunc: method is nil but Editor.Reload was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+	}{
+		ContextMoqParam: contextMoqParam,
+	}
+	mock.lockReload.Lock()
+	mock.calls.Reload = append(mock.calls.Reload, callInfo)
+	mock.lockReload.Unlock()
+	return mock.ReloadFunc(contextMoqParam)
+}
+
+// ReloadCalls gets all the calls that were made to Reload.
+// Check the length with:
+//
+//	len(mockedEditor.ReloadCalls())
+func (mock *EditorMock) ReloadCalls() []struct {
+	ContextMoqParam context.Context
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+	}
+	mock.lockReload.RLock()
+	calls = mock.calls.Reload
+	mock.lockReload.RUnlock()
+	return calls
+}
+
+// Replace calls ReplaceFunc.
+func (mock *EditorMock) Replace(contextMoqParam context.Context, s string) ([]*app.ChangeMsg, error) {
+	if mock.ReplaceFunc == nil {
+		panic("EditorMock.ReplaceFunc: method is nil but Editor.Replace was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		S               string
+	}{
+		ContextMoqParam: contextMoqParam,
+		S:               s,
+	}
+	mock.lockReplace.Lock()
+	mock.calls.Replace = append(mock.calls.Replace, callInfo)
+	mock.lockReplace.Unlock()
+	return mock.ReplaceFunc(contextMoqParam, s)
+}
+
+// ReplaceCalls gets all the calls that were made to Replace.
+// Check the length with:
+//
+//	len(mockedEditor.ReplaceCalls())
+func (mock *EditorMock) ReplaceCalls() []struct {
+	ContextMoqParam context.Context
+	S               string
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		S               string
+	}
+	mock.lockReplace.RLock()
+	calls = mock.calls.Replace
+	mock.lockReplace.RUnlock()
+	return calls
+}
+
+// State calls StateFunc.
+func (mock *EditorMock) State(contextMoqParam context.Context) (string, error) {
+	if mock.StateFunc == nil {
+		panic("EditorMock.StateFunc: method is nil but Editor.State was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+	}{
+		ContextMoqParam: contextMoqParam,
+	}
+	mock.lockState.Lock()
+	mock.calls.State = append(mock.calls.State, callInfo)
+	mock.lockState.Unlock()
+	return mock.StateFunc(contextMoqParam)
+}
+
+// StateCalls gets all the calls that were made to State.
+// Check the length with:
+//
+//	len(mockedEditor.StateCalls())
+func (mock *EditorMock) StateCalls() []struct {
+	ContextMoqParam context.Context
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+	}
+	mock.lockState.RLock()
+	calls = mock.calls.State
+	mock.lockState.RUnlock()
+	return calls
+}
+
 // Unload calls UnloadFunc.
 func (mock *EditorMock) Unload(contextMoqParam context.Context) error {
 	if mock.UnloadFunc == nil {
@@ -270,7 +452,7 @@ func (mock *EditorMock) UnloadCalls() []struct {
 }
 
 // Write calls WriteFunc.
-func (mock *EditorMock) Write(contextMoqParam context.Context) error {
+func (mock *EditorMock) Write(contextMoqParam context.Context) ([]*app.ChangeMsg, error) {
 	if mock.WriteFunc == nil {
 		panic("EditorMock.WriteFunc: method is nil but Editor.Write was just called")
 	}
@@ -34,6 +34,9 @@ var _ app.Hub = &HubMock{}
 //			GetByUsernameFunc: func(s string) bool {
 //				panic("mock out the GetByUsername method")
 //			},
+//			ReadyFunc: func() []string {
+//				panic("mock out the Ready method")
+//			},
 //			RegisterFunc: func(client *app.Client)  {
 //				panic("mock out the Register method")
 //			},
@@ -49,6 +52,9 @@ var _ app.Hub = &HubMock{}
 //			UnregisterFunc: func(client *app.Client)  {
 //				panic("mock out the Unregister method")
 //			},
+//			UsernamesFunc: func() []string {
+//				panic("mock out the Usernames method")
+//			},
 //		}
 //
 //		// use mockedHub in code that requires app.Hub
@@ -71,6 +77,9 @@ type HubMock struct {
 	// GetByUsernameFunc mocks the GetByUsername method.
 	GetByUsernameFunc func(s string) bool
 
+	// ReadyFunc mocks the Ready method.
+	ReadyFunc func() []string
+
 	// RegisterFunc mocks the Register method.
 	RegisterFunc func(client *app.Client)
 
@@ -86,6 +95,9 @@ type HubMock struct {
 	// UnregisterFunc mocks the Unregister method.
 	UnregisterFunc func(client *app.Client)
 
+	// UsernamesFunc mocks the Usernames method.
+	UsernamesFunc func() []string
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// Brodcast holds details about calls to the Brodcast method.
@@ -121,6 +133,9 @@ type HubMock struct {
 			// S is the s argument value.
 			S string
 		}
+		// Ready holds details about calls to the Ready method.
+		Ready []struct {
+		}
 		// Register holds details about calls to the Register method.
 		Register []struct {
 			// Client is the client argument value.
@@ -150,17 +165,22 @@ type HubMock struct {
 			// Client is the client argument value.
 			Client *app.Client
 		}
+		// Usernames holds details about calls to the Usernames method.
+		Usernames []struct {
+		}
 	}
 	lockBrodcast        sync.RWMutex
 	lockCountRegistered sync.RWMutex
 	lockCreate          sync.RWMutex
 	lockGet             sync.RWMutex
 	lockGetByUsername   sync.RWMutex
+	lockReady           sync.RWMutex
 	lockRegister        sync.RWMutex
 	lockSetPosition     sync.RWMutex
 	lockSetReady        sync.RWMutex
 	lockSetReadyAll     sync.RWMutex
 	lockUnregister      sync.RWMutex
+	lockUsernames       sync.RWMutex
 }
 
 // Brodcast calls BrodcastFunc.
@@ -338,6 +358,33 @@ func (mock *HubMock) GetByUsernameCalls() []struct {
 	return calls
 }
 
+// Ready calls ReadyFunc.
+func (mock *HubMock) Ready() []string {
+	if mock.ReadyFunc == nil {
+		panic("HubMock.ReadyFunc: method is nil but Hub.Ready was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockReady.Lock()
+	mock.calls.Ready = append(mock.calls.Ready, callInfo)
+	mock.lockReady.Unlock()
+	return mock.ReadyFunc()
+}
+
+// ReadyCalls gets all the calls that were made to Ready.
+// Check the length with:
+//
+//	len(mockedHub.ReadyCalls())
+func (mock *HubMock) ReadyCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockReady.RLock()
+	calls = mock.calls.Ready
+	mock.lockReady.RUnlock()
+	return calls
+}
+
 // Register calls RegisterFunc.
 func (mock *HubMock) Register(client *app.Client) {
 	if mock.RegisterFunc == nil {
@@ -505,3 +552,30 @@ func (mock *HubMock) UnregisterCalls() []struct {
 	mock.lockUnregister.RUnlock()
 	return calls
 }
+
+// Usernames calls UsernamesFunc.
+func (mock *HubMock) Usernames() []string {
+	if mock.UsernamesFunc == nil {
+		panic("HubMock.UsernamesFunc: method is nil but Hub.Usernames was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockUsernames.Lock()
+	mock.calls.Usernames = append(mock.calls.Usernames, callInfo)
+	mock.lockUsernames.Unlock()
+	return mock.UsernamesFunc()
+}
+
+// UsernamesCalls gets all the calls that were made to Usernames.
+// Check the length with:
+//
+//	len(mockedHub.UsernamesCalls())
+func (mock *HubMock) UsernamesCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockUsernames.RLock()
+	calls = mock.calls.Usernames
+	mock.lockUsernames.RUnlock()
+	return calls
+}
@@ -19,6 +19,21 @@ var _ app.Versioning = &VersioningMock{}
 //
 //		// make and configure a mocked app.Versioning
 //		mockedVersioning := &VersioningMock{
+//			DeleteFunc: func(contextMoqParam context.Context, s1 string, s2 string) error {
+//				panic("mock out the Delete method")
+//			},
+//			DiffFunc: func(contextMoqParam context.Context, s1 string, s2 string, s3 string) (string, error) {
+//				panic("mock out the Diff method")
+//			},
+//			GetFunc: func(contextMoqParam context.Context, s1 string, s2 string) (string, error) {
+//				panic("mock out the Get method")
+//			},
+//			ListFunc: func(contextMoqParam context.Context, s string) ([]app.Version, error) {
+//				panic("mock out the List method")
+//			},
+//			RestoreFunc: func(contextMoqParam context.Context, s1 string, s2 string) (string, error) {
+//				panic("mock out the Restore method")
+//			},
 //			SaveFunc: func(contextMoqParam context.Context, s1 string, s2 string) error {
 //				panic("mock out the Save method")
 //			},
@@ -29,11 +44,71 @@ var _ app.Versioning = &VersioningMock{}
 //
 //	}
 type VersioningMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(contextMoqParam context.Context, s1 string, s2 string) error
+
+	// DiffFunc mocks the Diff method.
+	DiffFunc func(contextMoqParam context.Context, s1 string, s2 string, s3 string) (string, error)
+
+	// GetFunc mocks the Get method.
+	GetFunc func(contextMoqParam context.Context, s1 string, s2 string) (string, error)
+
+	// ListFunc mocks the List method.
+	ListFunc func(contextMoqParam context.Context, s string) ([]app.Version, error)
+
+	// RestoreFunc mocks the Restore method.
+	RestoreFunc func(contextMoqParam context.Context, s1 string, s2 string) (string, error)
+
 	// SaveFunc mocks the Save method.
 	SaveFunc func(contextMoqParam context.Context, s1 string, s2 string) error
 
 	// calls tracks calls to the methods.
 	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// S1 is the s1 argument value.
+			S1 string
+			// S2 is the s2 argument value.
+			S2 string
+		}
+		// Diff holds details about calls to the Diff method.
+		Diff []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// S1 is the s1 argument value.
+			S1 string
+			// S2 is the s2 argument value.
+			S2 string
+			// S3 is the s3 argument value.
+			S3 string
+		}
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// S1 is the s1 argument value.
+			S1 string
+			// S2 is the s2 argument value.
+			S2 string
+		}
+		// List holds details about calls to the List method.
+		List []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// S is the s argument value.
+			S string
+		}
+		// Restore holds details about calls to the Restore method.
+		Restore []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// S1 is the s1 argument value.
+			S1 string
+			// S2 is the s2 argument value.
+			S2 string
+		}
 		// Save holds details about calls to the Save method.
 		Save []struct {
 			// ContextMoqParam is the contextMoqParam argument value.
@@ -44,7 +119,212 @@ type VersioningMock struct {
 			S2 string
 		}
 	}
-	lockSave sync.RWMutex
+	lockDelete  sync.RWMutex
+	lockDiff    sync.RWMutex
+	lockGet     sync.RWMutex
+	lockList    sync.RWMutex
+	lockRestore sync.RWMutex
+	lockSave    sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *VersioningMock) Delete(contextMoqParam context.Context, s1 string, s2 string) error {
+	if mock.DeleteFunc == nil {
+		panic("VersioningMock.DeleteFunc: method is nil but Versioning.Delete was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		S1              string
+		S2              string
+	}{
+		ContextMoqParam: contextMoqParam,
+		S1:              s1,
+		S2:              s2,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	return mock.DeleteFunc(contextMoqParam, s1, s2)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedVersioning.DeleteCalls())
+func (mock *VersioningMock) DeleteCalls() []struct {
+	ContextMoqParam context.Context
+	S1              string
+	S2              string
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		S1              string
+		S2              string
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// Diff calls DiffFunc.
+func (mock *VersioningMock) Diff(contextMoqParam context.Context, s1 string, s2 string, s3 string) (string, error) {
+	if mock.DiffFunc == nil {
+		panic("VersioningMock.DiffFunc: method is nil but Versioning.Diff was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		S1              string
+		S2              string
+		S3              string
+	}{
+		ContextMoqParam: contextMoqParam,
+		S1:              s1,
+		S2:              s2,
+		S3:              s3,
+	}
+	mock.lockDiff.Lock()
+	mock.calls.Diff = append(mock.calls.Diff, callInfo)
+	mock.lockDiff.Unlock()
+	return mock.DiffFunc(contextMoqParam, s1, s2, s3)
+}
+
+// DiffCalls gets all the calls that were made to Diff.
+// Check the length with:
+//
+//	len(mockedVersioning.DiffCalls())
+func (mock *VersioningMock) DiffCalls() []struct {
+	ContextMoqParam context.Context
+	S1              string
+	S2              string
+	S3              string
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		S1              string
+		S2              string
+		S3              string
+	}
+	mock.lockDiff.RLock()
+	calls = mock.calls.Diff
+	mock.lockDiff.RUnlock()
+	return calls
+}
+
+// Get calls GetFunc.
+func (mock *VersioningMock) Get(contextMoqParam context.Context, s1 string, s2 string) (string, error) {
+	if mock.GetFunc == nil {
+		panic("VersioningMock.GetFunc: method is nil but Versioning.Get was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		S1              string
+		S2              string
+	}{
+		ContextMoqParam: contextMoqParam,
+		S1:              s1,
+		S2:              s2,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	return mock.GetFunc(contextMoqParam, s1, s2)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedVersioning.GetCalls())
+func (mock *VersioningMock) GetCalls() []struct {
+	ContextMoqParam context.Context
+	S1              string
+	S2              string
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		S1              string
+		S2              string
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// List calls ListFunc.
+func (mock *VersioningMock) List(contextMoqParam context.Context, s string) ([]app.Version, error) {
+	if mock.ListFunc == nil {
+		panic("VersioningMock.ListFunc: method is nil but Versioning.List was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		S               string
+	}{
+		ContextMoqParam: contextMoqParam,
+		S:               s,
+	}
+	mock.lockList.Lock()
+	mock.calls.List = append(mock.calls.List, callInfo)
+	mock.lockList.Unlock()
+	return mock.ListFunc(contextMoqParam, s)
+}
+
+// ListCalls gets all the calls that were made to List.
+// Check the length with:
+//
+//	len(mockedVersioning.ListCalls())
+func (mock *VersioningMock) ListCalls() []struct {
+	ContextMoqParam context.Context
+	S               string
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		S               string
+	}
+	mock.lockList.RLock()
+	calls = mock.calls.List
+	mock.lockList.RUnlock()
+	return calls
+}
+
+// Restore calls RestoreFunc.
+func (mock *VersioningMock) Restore(contextMoqParam context.Context, s1 string, s2 string) (string, error) {
+	if mock.RestoreFunc == nil {
+		panic("VersioningMock.RestoreFunc: method is nil but Versioning.Restore was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		S1              string
+		S2              string
+	}{
+		ContextMoqParam: contextMoqParam,
+		S1:              s1,
+		S2:              s2,
+	}
+	mock.lockRestore.Lock()
+	mock.calls.Restore = append(mock.calls.Restore, callInfo)
+	mock.lockRestore.Unlock()
+	return mock.RestoreFunc(contextMoqParam, s1, s2)
+}
+
+// RestoreCalls gets all the calls that were made to Restore.
+// Check the length with:
+//
+//	len(mockedVersioning.RestoreCalls())
+func (mock *VersioningMock) RestoreCalls() []struct {
+	ContextMoqParam context.Context
+	S1              string
+	S2              string
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		S1              string
+		S2              string
+	}
+	mock.lockRestore.RLock()
+	calls = mock.calls.Restore
+	mock.lockRestore.RUnlock()
+	return calls
 }
 
 // Save calls SaveFunc.
@@ -0,0 +1,376 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"context"
+	"github.com/fakovacic/editor/internal/app"
+	"sync"
+)
+
+// Ensure, that WorkspaceMock does implement app.Workspace.
+// If this is not the case, regenerate this file with moq.
+var _ app.Workspace = &WorkspaceMock{}
+
+// WorkspaceMock is a mock implementation of app.Workspace.
+//
+//	func TestSomethingThatUsesWorkspace(t *testing.T) {
+//
+//		// make and configure a mocked app.Workspace
+//		mockedWorkspace := &WorkspaceMock{
+//			CloseFunc: func(contextMoqParam context.Context, s string) error {
+//				panic("mock out the Close method")
+//			},
+//			CreateFunc: func(contextMoqParam context.Context, s string) error {
+//				panic("mock out the Create method")
+//			},
+//			DeleteFunc: func(contextMoqParam context.Context, s string) error {
+//				panic("mock out the Delete method")
+//			},
+//			FilesFunc: func(contextMoqParam context.Context) ([]app.FileInfo, error) {
+//				panic("mock out the Files method")
+//			},
+//			OpenFunc: func(contextMoqParam context.Context, s string) (*app.Session, error) {
+//				panic("mock out the Open method")
+//			},
+//			RenameFunc: func(contextMoqParam context.Context, s1 string, s2 string) error {
+//				panic("mock out the Rename method")
+//			},
+//			SessionsFunc: func(contextMoqParam context.Context) []*app.Session {
+//				panic("mock out the Sessions method")
+//			},
+//		}
+//
+//		// use mockedWorkspace in code that requires app.Workspace
+//		// and then make assertions.
+//
+//	}
+type WorkspaceMock struct {
+	// CloseFunc mocks the Close method.
+	CloseFunc func(contextMoqParam context.Context, s string) error
+
+	// CreateFunc mocks the Create method.
+	CreateFunc func(contextMoqParam context.Context, s string) error
+
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(contextMoqParam context.Context, s string) error
+
+	// FilesFunc mocks the Files method.
+	FilesFunc func(contextMoqParam context.Context) ([]app.FileInfo, error)
+
+	// OpenFunc mocks the Open method.
+	OpenFunc func(contextMoqParam context.Context, s string) (*app.Session, error)
+
+	// RenameFunc mocks the Rename method.
+	RenameFunc func(contextMoqParam context.Context, s1 string, s2 string) error
+
+	// SessionsFunc mocks the Sessions method.
+	SessionsFunc func(contextMoqParam context.Context) []*app.Session
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Close holds details about calls to the Close method.
+		Close []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// S is the s argument value.
+			S string
+		}
+		// Create holds details about calls to the Create method.
+		Create []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// S is the s argument value.
+			S string
+		}
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// S is the s argument value.
+			S string
+		}
+		// Files holds details about calls to the Files method.
+		Files []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+		}
+		// Open holds details about calls to the Open method.
+		Open []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// S is the s argument value.
+			S string
+		}
+		// Rename holds details about calls to the Rename method.
+		Rename []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// S1 is the s1 argument value.
+			S1 string
+			// S2 is the s2 argument value.
+			S2 string
+		}
+		// Sessions holds details about calls to the Sessions method.
+		Sessions []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+		}
+	}
+	lockClose    sync.RWMutex
+	lockCreate   sync.RWMutex
+	lockDelete   sync.RWMutex
+	lockFiles    sync.RWMutex
+	lockOpen     sync.RWMutex
+	lockRename   sync.RWMutex
+	lockSessions sync.RWMutex
+}
+
+// Close calls CloseFunc.
+func (mock *WorkspaceMock) Close(contextMoqParam context.Context, s string) error {
+	if mock.CloseFunc == nil {
+		panic("WorkspaceMock.CloseFunc: method is nil but Workspace.Close was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		S               string
+	}{
+		ContextMoqParam: contextMoqParam,
+		S:               s,
+	}
+	mock.lockClose.Lock()
+	mock.calls.Close = append(mock.calls.Close, callInfo)
+	mock.lockClose.Unlock()
+	return mock.CloseFunc(contextMoqParam, s)
+}
+
+// CloseCalls gets all the calls that were made to Close.
+// Check the length with:
+//
+//	len(mockedWorkspace.CloseCalls())
+func (mock *WorkspaceMock) CloseCalls() []struct {
+	ContextMoqParam context.Context
+	S               string
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		S               string
+	}
+	mock.lockClose.RLock()
+	calls = mock.calls.Close
+	mock.lockClose.RUnlock()
+	return calls
+}
+
+// Create calls CreateFunc.
+func (mock *WorkspaceMock) Create(contextMoqParam context.Context, s string) error {
+	if mock.CreateFunc == nil {
+		panic("WorkspaceMock.CreateFunc: method is nil but Workspace.Create was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		S               string
+	}{
+		ContextMoqParam: contextMoqParam,
+		S:               s,
+	}
+	mock.lockCreate.Lock()
+	mock.calls.Create = append(mock.calls.Create, callInfo)
+	mock.lockCreate.Unlock()
+	return mock.CreateFunc(contextMoqParam, s)
+}
+
+// CreateCalls gets all the calls that were made to Create.
+// Check the length with:
+//
+//	len(mockedWorkspace.CreateCalls())
+func (mock *WorkspaceMock) CreateCalls() []struct {
+	ContextMoqParam context.Context
+	S               string
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		S               string
+	}
+	mock.lockCreate.RLock()
+	calls = mock.calls.Create
+	mock.lockCreate.RUnlock()
+	return calls
+}
+
+// Delete calls DeleteFunc.
+func (mock *WorkspaceMock) Delete(contextMoqParam context.Context, s string) error {
+	if mock.DeleteFunc == nil {
+		panic("WorkspaceMock.DeleteFunc: method is nil but Workspace.Delete was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		S               string
+	}{
+		ContextMoqParam: contextMoqParam,
+		S:               s,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	return mock.DeleteFunc(contextMoqParam, s)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedWorkspace.DeleteCalls())
+func (mock *WorkspaceMock) DeleteCalls() []struct {
+	ContextMoqParam context.Context
+	S               string
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		S               string
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// Files calls FilesFunc.
+func (mock *WorkspaceMock) Files(contextMoqParam context.Context) ([]app.FileInfo, error) {
+	if mock.FilesFunc == nil {
+		panic("WorkspaceMock.FilesFunc: method is nil but Workspace.Files was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+	}{
+		ContextMoqParam: contextMoqParam,
+	}
+	mock.lockFiles.Lock()
+	mock.calls.Files = append(mock.calls.Files, callInfo)
+	mock.lockFiles.Unlock()
+	return mock.FilesFunc(contextMoqParam)
+}
+
+// FilesCalls gets all the calls that were made to Files.
+// Check the length with:
+//
+//	len(mockedWorkspace.FilesCalls())
+func (mock *WorkspaceMock) FilesCalls() []struct {
+	ContextMoqParam context.Context
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+	}
+	mock.lockFiles.RLock()
+	calls = mock.calls.Files
+	mock.lockFiles.RUnlock()
+	return calls
+}
+
+// Open calls OpenFunc.
+func (mock *WorkspaceMock) Open(contextMoqParam context.Context, s string) (*app.Session, error) {
+	if mock.OpenFunc == nil {
+		panic("WorkspaceMock.OpenFunc: method is nil but Workspace.Open was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		S               string
+	}{
+		ContextMoqParam: contextMoqParam,
+		S:               s,
+	}
+	mock.lockOpen.Lock()
+	mock.calls.Open = append(mock.calls.Open, callInfo)
+	mock.lockOpen.Unlock()
+	return mock.OpenFunc(contextMoqParam, s)
+}
+
+// OpenCalls gets all the calls that were made to Open.
+// Check the length with:
+//
+//	len(mockedWorkspace.OpenCalls())
+func (mock *WorkspaceMock) OpenCalls() []struct {
+	ContextMoqParam context.Context
+	S               string
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		S               string
+	}
+	mock.lockOpen.RLock()
+	calls = mock.calls.Open
+	mock.lockOpen.RUnlock()
+	return calls
+}
+
+// Rename calls RenameFunc.
+func (mock *WorkspaceMock) Rename(contextMoqParam context.Context, s1 string, s2 string) error {
+	if mock.RenameFunc == nil {
+		panic("WorkspaceMock.RenameFunc: method is nil but Workspace.Rename was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		S1              string
+		S2              string
+	}{
+		ContextMoqParam: contextMoqParam,
+		S1:              s1,
+		S2:              s2,
+	}
+	mock.lockRename.Lock()
+	mock.calls.Rename = append(mock.calls.Rename, callInfo)
+	mock.lockRename.Unlock()
+	return mock.RenameFunc(contextMoqParam, s1, s2)
+}
+
+// RenameCalls gets all the calls that were made to Rename.
+// Check the length with:
+//
+//	len(mockedWorkspace.RenameCalls())
+func (mock *WorkspaceMock) RenameCalls() []struct {
+	ContextMoqParam context.Context
+	S1              string
+	S2              string
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		S1              string
+		S2              string
+	}
+	mock.lockRename.RLock()
+	calls = mock.calls.Rename
+	mock.lockRename.RUnlock()
+	return calls
+}
+
+// Sessions calls SessionsFunc.
+func (mock *WorkspaceMock) Sessions(contextMoqParam context.Context) []*app.Session {
+	if mock.SessionsFunc == nil {
+		panic("WorkspaceMock.SessionsFunc: method is nil but Workspace.Sessions was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+	}{
+		ContextMoqParam: contextMoqParam,
+	}
+	mock.lockSessions.Lock()
+	mock.calls.Sessions = append(mock.calls.Sessions, callInfo)
+	mock.lockSessions.Unlock()
+	return mock.SessionsFunc(contextMoqParam)
+}
+
+// SessionsCalls gets all the calls that were made to Sessions.
+// Check the length with:
+//
+//	len(mockedWorkspace.SessionsCalls())
+func (mock *WorkspaceMock) SessionsCalls() []struct {
+	ContextMoqParam context.Context
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+	}
+	mock.lockSessions.RLock()
+	calls = mock.calls.Sessions
+	mock.lockSessions.RUnlock()
+	return calls
+}
@@ -19,12 +19,15 @@ var _ app.WriteValidator = &WriteValidatorMock{}
 //
 //		// make and configure a mocked app.WriteValidator
 //		mockedWriteValidator := &WriteValidatorMock{
-//			AddClientFunc: func(contextMoqParam context.Context, s string) error {
+//			AddClientFunc: func(ctx context.Context, id string, username string) error {
 //				panic("mock out the AddClient method")
 //			},
 //			ClearFunc: func(contextMoqParam context.Context)  {
 //				panic("mock out the Clear method")
 //			},
+//			ExpireFunc: func(contextMoqParam context.Context) []string {
+//				panic("mock out the Expire method")
+//			},
 //			IsReadyFunc: func(contextMoqParam context.Context) bool {
 //				panic("mock out the IsReady method")
 //			},
@@ -45,11 +48,14 @@ var _ app.WriteValidator = &WriteValidatorMock{}
 //	}
 type WriteValidatorMock struct {
 	// AddClientFunc mocks the AddClient method.
-	AddClientFunc func(contextMoqParam context.Context, s string) error
+	AddClientFunc func(ctx context.Context, id string, username string) error
 
 	// ClearFunc mocks the Clear method.
 	ClearFunc func(contextMoqParam context.Context)
 
+	// ExpireFunc mocks the Expire method.
+	ExpireFunc func(contextMoqParam context.Context) []string
+
 	// IsReadyFunc mocks the IsReady method.
 	IsReadyFunc func(contextMoqParam context.Context) bool
 
@@ -66,16 +72,23 @@ type WriteValidatorMock struct {
 	calls struct {
 		// AddClient holds details about calls to the AddClient method.
 		AddClient []struct {
-			// ContextMoqParam is the contextMoqParam argument value.
-			ContextMoqParam context.Context
-			// S is the s argument value.
-			S string
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// Username is the username argument value.
+			Username string
 		}
 		// Clear holds details about calls to the Clear method.
 		Clear []struct {
 			// ContextMoqParam is the contextMoqParam argument value.
 			ContextMoqParam context.Context
 		}
+		// Expire holds details about calls to the Expire method.
+		Expire []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+		}
 		// IsReady holds details about calls to the IsReady method.
 		IsReady []struct {
 			// ContextMoqParam is the contextMoqParam argument value.
@@ -105,6 +118,7 @@ type WriteValidatorMock struct {
 	}
 	lockAddClient     sync.RWMutex
 	lockClear         sync.RWMutex
+	lockExpire        sync.RWMutex
 	lockIsReady       sync.RWMutex
 	lockReadyClient   sync.RWMutex
 	lockRemoveClient  sync.RWMutex
@@ -112,21 +126,23 @@ type WriteValidatorMock struct {
 }
 
 // AddClient calls AddClientFunc.
-func (mock *WriteValidatorMock) AddClient(contextMoqParam context.Context, s string) error {
+func (mock *WriteValidatorMock) AddClient(ctx context.Context, id string, username string) error {
 	if mock.AddClientFunc == nil {
 		panic("WriteValidatorMock.AddClientFunc: method is nil but WriteValidator.AddClient was just called")
 	}
 	callInfo := struct {
-		ContextMoqParam context.Context
-		S               string
+		Ctx      context.Context
+		ID       string
+		Username string
 	}{
-		ContextMoqParam: contextMoqParam,
-		S:               s,
+		Ctx:      ctx,
+		ID:       id,
+		Username: username,
 	}
 	mock.lockAddClient.Lock()
 	mock.calls.AddClient = append(mock.calls.AddClient, callInfo)
 	mock.lockAddClient.Unlock()
-	return mock.AddClientFunc(contextMoqParam, s)
+	return mock.AddClientFunc(ctx, id, username)
 }
 
 // AddClientCalls gets all the calls that were made to AddClient.
@@ -134,12 +150,14 @@ func (mock *WriteValidatorMock) AddClient(contextMoqParam context.Context, s str
 //
 //	len(mockedWriteValidator.AddClientCalls())
 func (mock *WriteValidatorMock) AddClientCalls() []struct {
-	ContextMoqParam context.Context
-	S               string
+	Ctx      context.Context
+	ID       string
+	Username string
 } {
 	var calls []struct {
-		ContextMoqParam context.Context
-		S               string
+		Ctx      context.Context
+		ID       string
+		Username string
 	}
 	mock.lockAddClient.RLock()
 	calls = mock.calls.AddClient
@@ -179,6 +197,38 @@ func (mock *WriteValidatorMock) ClearCalls() []struct {
 	return calls
 }
 
+// Expire calls ExpireFunc.
+func (mock *WriteValidatorMock) Expire(contextMoqParam context.Context) []string {
+	if mock.ExpireFunc == nil {
+		panic("WriteValidatorMock.ExpireFunc: method is nil but WriteValidator.Expire was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+	}{
+		ContextMoqParam: contextMoqParam,
+	}
+	mock.lockExpire.Lock()
+	mock.calls.Expire = append(mock.calls.Expire, callInfo)
+	mock.lockExpire.Unlock()
+	return mock.ExpireFunc(contextMoqParam)
+}
+
+// ExpireCalls gets all the calls that were made to Expire.
+// Check the length with:
+//
+//	len(mockedWriteValidator.ExpireCalls())
+func (mock *WriteValidatorMock) ExpireCalls() []struct {
+	ContextMoqParam context.Context
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+	}
+	mock.lockExpire.RLock()
+	calls = mock.calls.Expire
+	mock.lockExpire.RUnlock()
+	return calls
+}
+
 // IsReady calls IsReadyFunc.
 func (mock *WriteValidatorMock) IsReady(contextMoqParam context.Context) bool {
 	if mock.IsReadyFunc == nil {
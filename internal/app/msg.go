@@ -16,6 +16,15 @@ const (
 	MsgConnReady        MsgType = "conn-ready"         // conn content ready for writing
 	MsgConnUnready      MsgType = "conn-unready"       // conn content not ready for writing
 	MsgConnDisconnect   MsgType = "conn-disconnect"    // disconnect conn
+	MsgConnOpenFile     MsgType = "conn-open-file"     // conn opens file in workspace
+	MsgConnCreateFile   MsgType = "conn-create-file"   // conn creates file in workspace
+	MsgConnRenameFile   MsgType = "conn-rename-file"   // conn renames file in workspace
+	MsgConnDeleteFile   MsgType = "conn-delete-file"   // conn deletes file in workspace
+
+	MsgConnVersions       MsgType = "conn-versions"        // conn lists versions of file
+	MsgConnVersion        MsgType = "conn-version"         // conn gets version of file
+	MsgConnVersionDiff    MsgType = "conn-version-diff"    // conn diffs versions of file
+	MsgConnVersionRestore MsgType = "conn-version-restore" // conn restores version of file
 )
 
 // MsgType from server to clients
@@ -29,7 +38,21 @@ const (
 	MsgClientsCursorChange MsgType = "clients-cursor-change" // clients cursor change
 	MsgClientsDisconnected MsgType = "clients-disconnected"  // client disconnected
 
-	MsgServerFileSaved MsgType = "server-file-saved" // file saved
+	MsgServerFileSaved     MsgType = "server-file-saved"     // file saved
+	MsgServerFileAutosaved MsgType = "server-file-autosaved" // file or draft autosaved, data is target
+	MsgServerFileMerged    MsgType = "server-file-merged"    // file changed on source, merged and saved
+	MsgServerFileReloaded  MsgType = "server-file-reloaded"  // file changed on source, merged
+	MsgServerFiles         MsgType = "server-files"          // workspace files added or removed
+	MsgServerShutdown      MsgType = "server-shutdown"       // server shutting down, open files are saved
+
+	MsgServerTextChangeAck MsgType = "server-text-change-ack" // conn text change applied
+	MsgServerCRDTState     MsgType = "server-crdt-state"      // crdt state for conn
+	MsgServerResync        MsgType = "server-resync"          // conn change rejected, contents sent again
+
+	MsgServerVersions        MsgType = "server-versions"         // versions of file for conn
+	MsgServerVersion         MsgType = "server-version"          // contents of version for conn
+	MsgServerVersionDiff     MsgType = "server-version-diff"     // diff of versions for conn
+	MsgServerVersionRestored MsgType = "server-version-restored" // version restored as change of contents
 )
 
 // MsgType error from server to client
@@ -37,8 +60,14 @@ const (
 	MsgConnNotReady   MsgType = "conn-not-ready"   // conn not ready
 	MsgConnNotUnready MsgType = "conn-not-unready" // conn not unready
 
-	MsgServerFileNotReady MsgType = "server-file-not-ready" // file not ready
-	MsgServerFileNotSaved MsgType = "server-file-not-saved" // file not saved
+	MsgServerFileNotReady   MsgType = "server-file-not-ready"   // file not ready
+	MsgServerFileNotSaved   MsgType = "server-file-not-saved"   // file not saved
+	MsgServerFileNotOpened  MsgType = "server-file-not-opened"  // file not opened
+	MsgServerFileNotCreated MsgType = "server-file-not-created" // file not created
+	MsgServerFileNotRenamed MsgType = "server-file-not-renamed" // file not renamed
+	MsgServerFileNotDeleted MsgType = "server-file-not-deleted" // file not deleted
+	MsgServerFileConflict   MsgType = "server-file-conflict"    // file changed on source, not saved
+	MsgServerVersionFailed  MsgType = "server-version-failed"   // version msg of conn failed
 )
 
 const (
@@ -70,6 +99,22 @@ func (t *MsgType) Parse(s string) error {
 		*t = MsgConnUnready
 	case "conn-disconnect":
 		*t = MsgConnDisconnect
+	case "conn-open-file":
+		*t = MsgConnOpenFile
+	case "conn-create-file":
+		*t = MsgConnCreateFile
+	case "conn-rename-file":
+		*t = MsgConnRenameFile
+	case "conn-delete-file":
+		*t = MsgConnDeleteFile
+	case "conn-versions":
+		*t = MsgConnVersions
+	case "conn-version":
+		*t = MsgConnVersion
+	case "conn-version-diff":
+		*t = MsgConnVersionDiff
+	case "conn-version-restore":
+		*t = MsgConnVersionRestore
 	case "clients-connected":
 		*t = MsgClientsConnected
 	case "clients-text-change":
@@ -80,6 +125,30 @@ func (t *MsgType) Parse(s string) error {
 		*t = MsgClientsDisconnected
 	case "server-file-saved":
 		*t = MsgServerFileSaved
+	case "server-file-autosaved":
+		*t = MsgServerFileAutosaved
+	case "server-file-merged":
+		*t = MsgServerFileMerged
+	case "server-file-reloaded":
+		*t = MsgServerFileReloaded
+	case "server-files":
+		*t = MsgServerFiles
+	case "server-shutdown":
+		*t = MsgServerShutdown
+	case "server-text-change-ack":
+		*t = MsgServerTextChangeAck
+	case "server-crdt-state":
+		*t = MsgServerCRDTState
+	case "server-resync":
+		*t = MsgServerResync
+	case "server-versions":
+		*t = MsgServerVersions
+	case "server-version":
+		*t = MsgServerVersion
+	case "server-version-diff":
+		*t = MsgServerVersionDiff
+	case "server-version-restored":
+		*t = MsgServerVersionRestored
 	case "conn-not-ready":
 		*t = MsgConnNotReady
 	case "conn-not-unready":
@@ -88,6 +157,18 @@ func (t *MsgType) Parse(s string) error {
 		*t = MsgServerFileNotReady
 	case "server-file-not-saved":
 		*t = MsgServerFileNotSaved
+	case "server-file-not-opened":
+		*t = MsgServerFileNotOpened
+	case "server-file-not-created":
+		*t = MsgServerFileNotCreated
+	case "server-file-not-renamed":
+		*t = MsgServerFileNotRenamed
+	case "server-file-not-deleted":
+		*t = MsgServerFileNotDeleted
+	case "server-file-conflict":
+		*t = MsgServerFileConflict
+	case "server-version-failed":
+		*t = MsgServerVersionFailed
 	case "nil":
 		*t = MsgNil
 	default:
@@ -108,11 +189,18 @@ type WSMsgTextChange struct {
 	Data ChangeMsg `json:"data"`
 }
 
+// ChangeMsg is Ace delta, revision is document revision delta is based on,
+// server sets it to revision created by applying delta. Next is part of
+// remove split around concurrent insert, applied after delta in same revision.
+// In crdt mode only ops are used.
 type ChangeMsg struct {
-	Action string    `json:"action"`
-	Start  ChangeRow `json:"start"`
-	End    ChangeRow `json:"end"`
-	Lines  []string  `json:"lines"`
+	Action   string     `json:"action"`
+	Start    ChangeRow  `json:"start"`
+	End      ChangeRow  `json:"end"`
+	Lines    []string   `json:"lines"`
+	Revision int        `json:"revision"`
+	Next     *ChangeMsg `json:"next,omitempty"`
+	Ops      []CRDTOp   `json:"ops,omitempty"`
 }
 
 type ChangeRow struct {
@@ -120,6 +208,47 @@ type ChangeRow struct {
 	Column int `json:"column"`
 }
 
+// CRDTOp inserts single char after origin or removes char
+type CRDTOp struct {
+	Action string  `json:"action"`
+	ID     CRDTID  `json:"id"`
+	Origin *CRDTID `json:"origin,omitempty"` // nil inserts at document start
+	Value  string  `json:"value,omitempty"`
+}
+
+type CRDTID struct {
+	Site  string `json:"site"`
+	Clock int    `json:"clock"`
+}
+
+// WebSocket message for saving file, message is kept with saved version
+type WSMsgSave struct {
+	Data SaveMsg `json:"data"`
+}
+
+type SaveMsg struct {
+	Message string `json:"message,omitempty"`
+}
+
+// WebSocket message for opening file
+type WSMsgOpenFile struct {
+	Data OpenFile `json:"data"`
+}
+
+type OpenFile struct {
+	Name string `json:"name"`
+}
+
+// WebSocket message for creating, renaming or deleting file
+type WSMsgFileOp struct {
+	Data FileOp `json:"data"`
+}
+
+type FileOp struct {
+	Name    string `json:"name"`
+	NewName string `json:"newName,omitempty"` // only for rename
+}
+
 // WebSocket message for cursor change
 type WSMsgCursorChange struct {
 	Data CursorChange `json:"data"`
@@ -129,3 +258,13 @@ type CursorChange struct {
 	Index  int `json:"index"`
 	Length int `json:"length"`
 }
+
+// WebSocket message for version of open file, To is only for diff
+type WSMsgVersion struct {
+	Data VersionOp `json:"data"`
+}
+
+type VersionOp struct {
+	ID string `json:"id"`
+	To string `json:"to,omitempty"`
+}
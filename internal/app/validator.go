@@ -1,6 +1,12 @@
 package app
 
-import "context"
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/fakovacic/editor/internal/errors"
+)
 
 //go:generate moq -out ./mocks/write_validator.go -pkg mocks  . WriteValidator
 type WriteValidator interface {
@@ -8,9 +14,53 @@ type WriteValidator interface {
 
 	IsReady(context.Context) bool
 
-	AddClient(context.Context, string) error
+	AddClient(ctx context.Context, id, username string) error
 	RemoveClient(context.Context, string) error
 
 	ReadyClient(context.Context, string) error
 	UnreadyClient(context.Context, string) error
+
+	// Expire unsets ready votes older than expire of approval,
+	// returns ids of clients whose votes expired
+	Expire(context.Context) []string
+}
+
+// Approval tells when clients editing file approved save of it with ready
+// votes, client editing file alone approves its own save with any policy
+type Approval struct {
+	Policy    ApprovalPolicy
+	Approvals int           // ready votes needed by approvals policy
+	Owners    []string      // usernames whose ready vote is enough by owner policy, advisory as usernames are not authenticated
+	Expire    time.Duration // ready votes older than expire are not counted, never expire if zero
+}
+
+type ApprovalPolicy string
+
+const (
+	ApprovalAll       ApprovalPolicy = "all"       // every client is ready
+	ApprovalMajority  ApprovalPolicy = "majority"  // more than half of clients are ready
+	ApprovalOwner     ApprovalPolicy = "owner"     // any of owners is ready
+	ApprovalApprovals ApprovalPolicy = "approvals" // number of clients are ready
+)
+
+func (p ApprovalPolicy) String() string {
+	return string(p)
+}
+
+func (p *ApprovalPolicy) Parse(s string) error {
+	s = strings.Trim(s, "\"")
+	switch s {
+	case "all":
+		*p = ApprovalAll
+	case "majority":
+		*p = ApprovalMajority
+	case "owner":
+		*p = ApprovalOwner
+	case "approvals":
+		*p = ApprovalApprovals
+	default:
+		return errors.BadRequest("invalid approval policy '%s'", s)
+	}
+
+	return nil
 }
@@ -1,8 +1,86 @@
 package app
 
-import "context"
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/fakovacic/editor/internal/errors"
+)
 
 //go:generate moq -out ./mocks/versioning.go -pkg mocks  . Versioning
 type Versioning interface {
 	Save(context.Context, string, string) error
+
+	// List returns versions of file, newest first
+	List(context.Context, string) ([]Version, error)
+
+	// Get returns contents of version of file
+	Get(context.Context, string, string) (string, error)
+
+	// Diff returns unified diff from first to second version of file
+	Diff(context.Context, string, string, string) (string, error)
+
+	// Restore saves version of file as newest version and returns its
+	// contents, editor sets them so clients see restore as change
+	Restore(context.Context, string, string) (string, error)
+
+	// Delete deletes version of file with its metadata
+	Delete(context.Context, string, string) error
+}
+
+// Version is saved contents of file, ID is unique for file,
+// Hash is sha256 of contents
+type Version struct {
+	ID   string    `json:"id"`
+	Name string    `json:"name"`
+	Time time.Time `json:"time"`
+	Size int64     `json:"size"`
+	Hash string    `json:"hash,omitempty"`
+	SaveInfo
+}
+
+// SaveInfo tells who saved contents and why, set in context of write,
+// Authors are usernames of clients editing file and Ready ones who voted,
+// Draft is version of contents which were not written to file
+type SaveInfo struct {
+	Authors []string    `json:"authors,omitempty"`
+	Ready   []string    `json:"ready,omitempty"`
+	Trigger SaveTrigger `json:"trigger,omitempty"`
+	Message string      `json:"message,omitempty"`
+	Draft   bool        `json:"draft,omitempty"`
+}
+
+type SaveTrigger string
+
+const (
+	SaveLoad       SaveTrigger = "load"       // contents as loaded from source
+	SaveManual     SaveTrigger = "save"       // client saved or all clients were ready
+	SaveAuto       SaveTrigger = "autosave"   // saved without clients asking for it
+	SaveDisconnect SaveTrigger = "disconnect" // last client left file
+	SaveShutdown   SaveTrigger = "shutdown"   // server shut down while clients edited file
+)
+
+func (t SaveTrigger) String() string {
+	return string(t)
+}
+
+func (t *SaveTrigger) Parse(s string) error {
+	s = strings.Trim(s, "\"")
+	switch s {
+	case "load":
+		*t = SaveLoad
+	case "save":
+		*t = SaveManual
+	case "autosave":
+		*t = SaveAuto
+	case "disconnect":
+		*t = SaveDisconnect
+	case "shutdown":
+		*t = SaveShutdown
+	default:
+		return errors.BadRequest("invalid save trigger '%s'", s)
+	}
+
+	return nil
 }
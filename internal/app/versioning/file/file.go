@@ -2,11 +2,15 @@ package file
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/versioning"
+	"github.com/fakovacic/editor/internal/atomicfile"
 	"github.com/fakovacic/editor/internal/errors"
 	"github.com/fakovacic/editor/internal/log"
 )
@@ -23,20 +27,117 @@ type versioningFile struct {
 	timeFunc func() time.Time
 }
 
+// Save saves version with its metadata in json file alongside it
 func (s *versioningFile) Save(ctx context.Context, filename, content string) error {
-	f, err := os.Create(fmt.Sprintf("%s/%d_%s", s.Path, s.timeFunc().Unix(), filename))
+	now := s.timeFunc()
+	name := filepath.Join(s.Path, versioning.Name(now, filename))
+
+	err := atomicfile.WriteFile(name, []byte(content), 0644)
 	if err != nil {
-		return errors.Wrap(err, "create file")
+		return errors.Wrap(err, "write file")
 	}
 
-	_, err = f.WriteString(content)
+	meta, err := json.Marshal(versioning.New(ctx, versioning.ID(now), now, filename, content))
 	if err != nil {
-		return errors.Wrap(err, "write file")
+		return errors.Wrap(err, "marshal version")
 	}
 
-	defer f.Close()
+	err = atomicfile.WriteFile(versioning.MetaName(name), meta, 0644)
+	if err != nil {
+		return errors.Wrap(err, "write version metadata")
+	}
 
 	log.Info(ctx, fmt.Sprintf("version saved: %s", filename))
 
 	return nil
 }
+
+func (s *versioningFile) List(_ context.Context, filename string) ([]app.Version, error) {
+	entries, err := os.ReadDir(s.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read dir")
+	}
+
+	versions := make([]app.Version, 0)
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		version, ok := versioning.Parse(entry.Name(), filename)
+		if !ok {
+			continue
+		}
+
+		info, infoErr := entry.Info()
+		if infoErr != nil {
+			continue
+		}
+
+		version.Size = info.Size()
+
+		meta, metaErr := os.ReadFile(filepath.Join(s.Path, versioning.MetaName(entry.Name())))
+		if metaErr == nil {
+			version = versioning.Meta(version, meta)
+		}
+
+		versions = append(versions, version)
+	}
+
+	versioning.Sort(versions)
+
+	return versions, nil
+}
+
+func (s *versioningFile) Get(_ context.Context, filename, id string) (string, error) {
+	err := versioning.ValidID(id)
+	if err != nil {
+		return "", err
+	}
+
+	contents, err := os.ReadFile(filepath.Join(s.Path, id+"_"+versioning.FileName(filename)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", errors.NotFound("version '%s' of file '%s' not found", id, filename)
+		}
+
+		return "", errors.Wrap(err, "read file")
+	}
+
+	return string(contents), nil
+}
+
+func (s *versioningFile) Diff(ctx context.Context, filename, from, to string) (string, error) {
+	return versioning.Diff(ctx, s, filename, from, to)
+}
+
+func (s *versioningFile) Restore(ctx context.Context, filename, id string) (string, error) {
+	return versioning.Restore(ctx, s, filename, id)
+}
+
+func (s *versioningFile) Delete(_ context.Context, filename, id string) error {
+	err := versioning.ValidID(id)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Join(s.Path, id+"_"+versioning.FileName(filename))
+
+	err = os.Remove(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errors.NotFound("version '%s' of file '%s' not found", id, filename)
+		}
+
+		return errors.Wrap(err, "remove file")
+	}
+
+	// versions saved before metadata have none
+	err = os.Remove(versioning.MetaName(name))
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "remove version metadata")
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package file_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+	versioningFile "github.com/fakovacic/editor/internal/app/versioning/file"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersions(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	now := time.Unix(1697628153, 0)
+
+	versioning := versioningFile.New(dir, func() time.Time {
+		now = now.Add(time.Second)
+
+		return now
+	})
+
+	err := versioning.Save(ctx, "main.css", "a {}\n")
+	assert.NoError(t, err)
+
+	info := app.SaveInfo{
+		Authors: []string{"alice", "bob"},
+		Ready:   []string{"bob"},
+		Trigger: app.SaveManual,
+		Message: "Dark theme",
+	}
+
+	err = versioning.Save(app.WithSaveInfo(ctx, info), "main.css", "b {}\n")
+	assert.NoError(t, err)
+
+	// versions of other files and other entries are not listed
+	err = versioning.Save(ctx, "index.html", "<p></p>\n")
+	assert.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "notes_main.css"), []byte("c {}\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err := versioning.List(ctx, "main.css")
+	assert.NoError(t, err)
+	assert.Equal(t, []app.Version{
+		{
+			ID:       "1697628155000000000",
+			Name:     "main.css",
+			Time:     time.Unix(1697628155, 0).UTC(),
+			Size:     5,
+			Hash:     "aef72dbbc44d4c3d5247d3f2db8b1b2ed59255c3ebe0a6488ed691429e600adf",
+			SaveInfo: info,
+		},
+		{
+			ID:   "1697628154000000000",
+			Name: "main.css",
+			Time: time.Unix(1697628154, 0).UTC(),
+			Size: 5,
+			Hash: "7df2d3ac857ea507c490dff0dd16dff64a2af0cd694613bc30a1800c12e1b118",
+		},
+	}, versions)
+
+	contents, err := versioning.Get(ctx, "main.css", "1697628154000000000")
+	assert.NoError(t, err)
+	assert.Equal(t, "a {}\n", contents)
+
+	_, err = versioning.Get(ctx, "main.css", "1")
+	assert.Equal(t, "version '1' of file 'main.css' not found", err.Error())
+
+	// id can not point outside of versions
+	_, err = versioning.Get(ctx, "main.css", "../1697628154000000000")
+	assert.Equal(t, "version id '../1697628154000000000' not valid", err.Error())
+
+	diff, err := versioning.Diff(ctx, "main.css", "1697628154000000000", "1697628155000000000")
+	assert.NoError(t, err)
+	assert.Equal(t, "--- main.css@1697628154000000000\n+++ main.css@1697628155000000000\n@@ -1 +1 @@\n-a {}\n+b {}\n", diff)
+
+	// restored version is saved as newest
+	contents, err = versioning.Restore(ctx, "main.css", "1697628154000000000")
+	assert.NoError(t, err)
+	assert.Equal(t, "a {}\n", contents)
+
+	versions, err = versioning.List(ctx, "main.css")
+	assert.NoError(t, err)
+	assert.Len(t, versions, 3)
+
+	contents, err = versioning.Get(ctx, "main.css", versions[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "a {}\n", contents)
+}
+
+func TestVersionsSameName(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	now := time.Unix(1697628153, 0)
+
+	versioning := versioningFile.New(dir, func() time.Time {
+		now = now.Add(time.Second)
+
+		return now
+	})
+
+	// files with same name in different directories have own versions
+	err := versioning.Save(ctx, "css/main.css", "a {}\n")
+	assert.NoError(t, err)
+
+	err = versioning.Save(ctx, "admin/main.css", "b {}\n")
+	assert.NoError(t, err)
+
+	err = versioning.Save(ctx, "main.css", "c {}\n")
+	assert.NoError(t, err)
+
+	for _, f := range []struct {
+		name     string
+		id       string
+		contents string
+	}{
+		{name: "css/main.css", id: "1697628154000000000", contents: "a {}\n"},
+		{name: "admin/main.css", id: "1697628155000000000", contents: "b {}\n"},
+		{name: "main.css", id: "1697628156000000000", contents: "c {}\n"},
+	} {
+		versions, listErr := versioning.List(ctx, f.name)
+		assert.NoError(t, listErr)
+		assert.Len(t, versions, 1)
+		assert.Equal(t, f.id, versions[0].ID)
+		assert.Equal(t, f.name, versions[0].Name)
+
+		contents, getErr := versioning.Get(ctx, f.name, f.id)
+		assert.NoError(t, getErr)
+		assert.Equal(t, f.contents, contents)
+	}
+
+	// version of other file is not found
+	_, err = versioning.Get(ctx, "admin/main.css", "1697628154000000000")
+	assert.Equal(t, "version '1697628154000000000' of file 'admin/main.css' not found", err.Error())
+
+	_, err = versioning.Restore(ctx, "admin/main.css", "1697628154000000000")
+	assert.Error(t, err)
+
+	err = versioning.Delete(ctx, "admin/main.css", "1697628154000000000")
+	assert.Equal(t, "version '1697628154000000000' of file 'admin/main.css' not found", err.Error())
+
+	// versions are kept flat in dir
+	_, err = os.Stat(filepath.Join(dir, "1697628154000000000_css%2Fmain.css"))
+	assert.NoError(t, err)
+}
+
+func TestVersionsSameSecond(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	now := time.Unix(1697628153, 0)
+
+	versioning := versioningFile.New(dir, func() time.Time {
+		now = now.Add(time.Millisecond)
+
+		return now
+	})
+
+	err := versioning.Save(ctx, "main.css", "a {}\n")
+	assert.NoError(t, err)
+
+	err = versioning.Save(ctx, "main.css", "b {}\n")
+	assert.NoError(t, err)
+
+	// versions saved with ids in seconds are listed
+	err = os.WriteFile(filepath.Join(dir, "1697628100_main.css"), []byte("c {}\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err := versioning.List(ctx, "main.css")
+	assert.NoError(t, err)
+	assert.Len(t, versions, 3)
+
+	ids := make([]string, 0, len(versions))
+	times := make([]time.Time, 0, len(versions))
+
+	for _, version := range versions {
+		ids = append(ids, version.ID)
+		times = append(times, version.Time)
+	}
+
+	assert.Equal(t, []string{"1697628153002000000", "1697628153001000000", "1697628100"}, ids)
+	assert.Equal(t, []time.Time{
+		time.Unix(1697628153, 2000000).UTC(),
+		time.Unix(1697628153, 1000000).UTC(),
+		time.Unix(1697628100, 0).UTC(),
+	}, times)
+
+	for id, expected := range map[string]string{
+		"1697628153001000000": "a {}\n",
+		"1697628153002000000": "b {}\n",
+		"1697628100":          "c {}\n",
+	} {
+		contents, getErr := versioning.Get(ctx, "main.css", id)
+		assert.NoError(t, getErr)
+		assert.Equal(t, expected, contents)
+	}
+}
@@ -0,0 +1,129 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/versioning"
+	"github.com/fakovacic/editor/internal/atomicfile"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/fakovacic/editor/internal/git"
+)
+
+// New returns versioning which keeps versions as commits of file in
+// dir in working tree of repo, instead of copy for each version
+func New(repo *git.Repo, dir string, timeFunc func() time.Time) app.Versioning {
+	return &versioningGit{
+		repo:     repo,
+		dir:      dir,
+		timeFunc: timeFunc,
+	}
+}
+
+type versioningGit struct {
+	repo     *git.Repo
+	dir      string
+	timeFunc func() time.Time
+}
+
+// Save commits version with its metadata in json file alongside it,
+// message of save is body of commit message
+func (s *versioningGit) Save(ctx context.Context, filename, content string) error {
+	path := filepath.Join(s.dir, versioning.FileName(filename))
+
+	// unchanged contents are not committed, metadata alone is no version
+	current, err := os.ReadFile(path)
+	if err == nil && string(current) == content {
+		return nil
+	}
+
+	err = atomicfile.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		return errors.Wrap(err, "write file")
+	}
+
+	version := versioning.New(ctx, "", s.timeFunc(), filename, content)
+
+	meta, err := json.Marshal(version)
+	if err != nil {
+		return errors.Wrap(err, "marshal version")
+	}
+
+	err = atomicfile.WriteFile(versioning.MetaName(path), meta, 0644)
+	if err != nil {
+		return errors.Wrap(err, "write version metadata")
+	}
+
+	message := fmt.Sprintf("Update %s", filename)
+	if version.Message != "" {
+		message += "\n\n" + version.Message
+	}
+
+	return s.repo.Commit(ctx, message, version.Authors, path, versioning.MetaName(path))
+}
+
+func (s *versioningGit) List(ctx context.Context, filename string) ([]app.Version, error) {
+	path := filepath.Join(s.dir, versioning.FileName(filename))
+
+	revisions, err := s.repo.Log(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]app.Version, 0, len(revisions))
+
+	for _, revision := range revisions {
+		version := app.Version{
+			ID:   revision.Hash,
+			Name: filename,
+			Time: revision.Time,
+			Size: revision.Size,
+		}
+
+		meta, metaErr := s.repo.Show(ctx, revision.Hash, versioning.MetaName(path))
+		if metaErr == nil {
+			version = versioning.Meta(version, []byte(meta))
+		}
+
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+// Get returns contents of file in commit, id is commit hash
+func (s *versioningGit) Get(ctx context.Context, filename, id string) (string, error) {
+	if len(id) < 4 || strings.Trim(id, "0123456789abcdef") != "" {
+		return "", errors.BadRequest("version id '%s' not valid", id)
+	}
+
+	contents, err := s.repo.Show(ctx, id, filepath.Join(s.dir, versioning.FileName(filename)))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", errors.NotFound("version '%s' of file '%s' not found", id, filename)
+		}
+
+		return "", err
+	}
+
+	return contents, nil
+}
+
+func (s *versioningGit) Diff(ctx context.Context, filename, from, to string) (string, error) {
+	return versioning.Diff(ctx, s, filename, from, to)
+}
+
+func (s *versioningGit) Restore(ctx context.Context, filename, id string) (string, error) {
+	return versioning.Restore(ctx, s, filename, id)
+}
+
+// Delete is not supported, versions are history of repository
+func (s *versioningGit) Delete(_ context.Context, _, id string) error {
+	return errors.BadRequest("version '%s' is git commit and can not be deleted", id)
+}
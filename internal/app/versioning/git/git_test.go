@@ -0,0 +1,81 @@
+package git_test
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+	versioningGit "github.com/fakovacic/editor/internal/app/versioning/git"
+	"github.com/fakovacic/editor/internal/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersions(t *testing.T) {
+	_, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+
+	out, err := exec.Command("git", "-C", dir, "init", "--quiet").CombinedOutput()
+	if err != nil {
+		t.Fatalf("git init: %v %s", err, out)
+	}
+
+	ctx := context.Background()
+
+	repo, err := git.Open(ctx, dir, git.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2023, 10, 18, 11, 22, 34, 0, time.UTC)
+
+	versioning := versioningGit.New(repo, dir, func() time.Time {
+		return now
+	})
+
+	err = versioning.Save(ctx, "main.css", "a {}")
+	assert.NoError(t, err)
+
+	info := app.SaveInfo{
+		Authors: []string{"alice", "bob"},
+		Ready:   []string{"bob"},
+		Trigger: app.SaveManual,
+		Message: "Dark theme",
+	}
+
+	err = versioning.Save(app.WithSaveInfo(ctx, info), "main.css", "b {}")
+	assert.NoError(t, err)
+
+	// unchanged contents are not new version
+	err = versioning.Save(ctx, "main.css", "b {}")
+	assert.NoError(t, err)
+
+	versions, err := versioning.List(ctx, "main.css")
+	assert.NoError(t, err)
+	assert.Len(t, versions, 2)
+
+	assert.Equal(t, info, versions[0].SaveInfo)
+	assert.Equal(t, now, versions[0].Time)
+	assert.Equal(t, int64(4), versions[0].Size)
+	assert.Equal(t, app.SaveInfo{}, versions[1].SaveInfo)
+
+	out, err = exec.Command("git", "-C", dir, "log", "-1", "--format=%an|%B").CombinedOutput()
+	if err != nil {
+		t.Fatalf("git log: %v %s", err, out)
+	}
+
+	assert.Equal(t, "alice|Update main.css\n\nDark theme\n\nCo-authored-by: bob <bob@editor.local>", strings.TrimSpace(string(out)))
+
+	contents, err := versioning.Get(ctx, "main.css", versions[1].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "a {}", contents)
+
+	_, err = versioning.Get(ctx, "main.css", "../HEAD")
+	assert.Equal(t, "version id '../HEAD' not valid", err.Error())
+}
@@ -2,11 +2,15 @@ package http
 
 import (
 	"context"
+	"encoding/json"
+	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/versioning"
 	"github.com/fakovacic/editor/internal/errors"
 )
 
@@ -14,6 +18,9 @@ type httpClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// New returns versioning of server at url, versions are saved with
+// POST url?file=name with metadata as query params, listed with
+// GET url?file=name as json and read with GET url/id?file=name
 func New(dirpath string, client httpClient, timeFunc func() time.Time) app.Versioning {
 	return &versioningFile{
 		path:     dirpath,
@@ -28,10 +35,66 @@ type versioningFile struct {
 	timeFunc func() time.Time
 }
 
-func (s *versioningFile) Save(ctx context.Context, _, content string) error {
+// url returns url of version id of file, url of versions if id is empty,
+// query is added to params of url
+func (s *versioningFile) url(filename, id string, query url.Values) (string, error) {
+	target := s.path
+
+	if id != "" {
+		versionURL, err := url.JoinPath(s.path, url.PathEscape(id))
+		if err != nil {
+			return "", errors.New("version url: %v", err)
+		}
+
+		target = versionURL
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", errors.New("version url: %v", err)
+	}
+
+	params := u.Query()
+	params.Set("file", filename)
+
+	for key, values := range query {
+		params[key] = values
+	}
+
+	u.RawQuery = params.Encode()
+
+	return u.String(), nil
+}
+
+func (s *versioningFile) Save(ctx context.Context, filename, content string) error {
+	info := app.GetSaveInfo(ctx)
+
+	query := url.Values{
+		"hash":   {versioning.Hash(content)},
+		"author": info.Authors,
+		"ready":  info.Ready,
+	}
+
+	if info.Trigger != "" {
+		query.Set("trigger", info.Trigger.String())
+	}
+
+	if info.Message != "" {
+		query.Set("message", info.Message)
+	}
+
+	if info.Draft {
+		query.Set("draft", "true")
+	}
+
+	target, err := s.url(filename, "", query)
+	if err != nil {
+		return err
+	}
+
 	req, err := http.NewRequestWithContext(ctx,
 		http.MethodPost,
-		s.path,
+		target,
 		strings.NewReader(content),
 	)
 	if err != nil {
@@ -50,3 +113,111 @@ func (s *versioningFile) Save(ctx context.Context, _, content string) error {
 
 	return nil
 }
+
+func (s *versioningFile) List(ctx context.Context, filename string) ([]app.Version, error) {
+	target, err := s.url(filename, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := s.get(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []app.Version
+
+	err = json.Unmarshal(body, &versions)
+	if err != nil {
+		return nil, errors.New("decode versions: %v", err)
+	}
+
+	if versions == nil {
+		versions = make([]app.Version, 0)
+	}
+
+	versioning.Sort(versions)
+
+	return versions, nil
+}
+
+func (s *versioningFile) Get(ctx context.Context, filename, id string) (string, error) {
+	target, err := s.url(filename, id, nil)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := s.get(ctx, target)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", errors.NotFound("version '%s' of file '%s' not found", id, filename)
+		}
+
+		return "", err
+	}
+
+	return string(body), nil
+}
+
+// get returns body of response, not found status is not found error
+func (s *versioningFile) get(ctx context.Context, target string) ([]byte, error) {
+	return s.do(ctx, http.MethodGet, target)
+}
+
+func (s *versioningFile) do(ctx context.Context, method, target string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx,
+		method,
+		target,
+		http.NoBody,
+	)
+	if err != nil {
+		return nil, errors.New("create http request: %v", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errors.New("http request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.New("read body response: %v", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return body, nil
+	case http.StatusNotFound:
+		return nil, errors.NotFound("not found")
+	default:
+		return nil, errors.New("status code not success: %v", resp.StatusCode)
+	}
+}
+
+func (s *versioningFile) Diff(ctx context.Context, filename, from, to string) (string, error) {
+	return versioning.Diff(ctx, s, filename, from, to)
+}
+
+func (s *versioningFile) Restore(ctx context.Context, filename, id string) (string, error) {
+	return versioning.Restore(ctx, s, filename, id)
+}
+
+// Delete deletes version with DELETE url/id?file=name
+func (s *versioningFile) Delete(ctx context.Context, filename, id string) error {
+	target, err := s.url(filename, id, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.do(ctx, http.MethodDelete, target)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return errors.NotFound("version '%s' of file '%s' not found", id, filename)
+		}
+
+		return err
+	}
+
+	return nil
+}
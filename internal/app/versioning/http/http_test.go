@@ -0,0 +1,71 @@
+package http_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+	versioningHTTP "github.com/fakovacic/editor/internal/app/versioning/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersions(t *testing.T) {
+	saved := make(map[string]string)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "main.css", r.URL.Query().Get("file"))
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/versions":
+			// metadata of version is sent as query params
+			assert.Equal(t, "author=alice&file=main.css&hash=9a4487ccbedf68e53bfd4feff149a0fb4e7c47af7ebcdd80db1984dc5cf5abdf&message=Restore+version+1697628154&trigger=save", r.URL.RawQuery)
+
+			body, _ := io.ReadAll(r.Body)
+			saved["1697628155"] = string(body)
+		case r.URL.Path == "/versions":
+			_, _ = w.Write([]byte(`[{"id":"1697628154","name":"main.css","time":"2023-10-18T11:22:34Z","size":4},{"id":"1697628155","name":"main.css","time":"2023-10-18T11:22:35Z","size":4}]`))
+		case r.URL.Path == "/versions/1697628154":
+			_, _ = w.Write([]byte("a {}"))
+		case r.URL.Path == "/versions/1697628155":
+			_, _ = w.Write([]byte("b {}"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	versioning := versioningHTTP.New(srv.URL+"/versions", srv.Client(), time.Now)
+
+	// versions are listed newest first
+	versions, err := versioning.List(ctx, "main.css")
+	assert.NoError(t, err)
+	assert.Len(t, versions, 2)
+	assert.Equal(t, "1697628155", versions[0].ID)
+
+	contents, err := versioning.Get(ctx, "main.css", "1697628154")
+	assert.NoError(t, err)
+	assert.Equal(t, "a {}", contents)
+
+	_, err = versioning.Get(ctx, "main.css", "1")
+	assert.Equal(t, "version '1' of file 'main.css' not found", err.Error())
+
+	diff, err := versioning.Diff(ctx, "main.css", "1697628154", "1697628155")
+	assert.NoError(t, err)
+	assert.Equal(t, "--- main.css@1697628154\n+++ main.css@1697628155\n@@ -1 +1 @@\n-a {}\n\\ No newline at end of file\n+b {}\n\\ No newline at end of file\n", diff)
+
+	restoreCtx := app.WithSaveInfo(ctx, app.SaveInfo{
+		Authors: []string{"alice"},
+		Trigger: app.SaveManual,
+		Message: "Restore version 1697628154",
+	})
+
+	contents, err = versioning.Restore(restoreCtx, "main.css", "1697628154")
+	assert.NoError(t, err)
+	assert.Equal(t, "a {}", contents)
+	assert.Equal(t, "a {}", saved["1697628155"])
+}
@@ -40,3 +40,111 @@ func (m *logMiddleware) Save(ctx context.Context, filename, content string) erro
 
 	return err
 }
+
+func (m *logMiddleware) List(ctx context.Context, filename string) ([]app.Version, error) {
+	log.Info(ctx, "part-request",
+		log.String("service", m.service),
+		log.String("method", "List"),
+		log.String("layer", "part"),
+		log.Any("req", map[string]any{
+			"filename": filename,
+		}))
+
+	versions, err := m.next.List(ctx, filename)
+
+	log.Info(ctx, "part-response",
+		log.String("service", m.service),
+		log.String("method", "List"),
+		log.String("layer", "part"),
+		log.Any("res", map[string]any{
+			"versions": len(versions),
+		}),
+		log.Err(err))
+
+	return versions, err
+}
+
+func (m *logMiddleware) Get(ctx context.Context, filename, id string) (string, error) {
+	log.Info(ctx, "part-request",
+		log.String("service", m.service),
+		log.String("method", "Get"),
+		log.String("layer", "part"),
+		log.Any("req", map[string]any{
+			"filename": filename,
+			"id":       id,
+		}))
+
+	contents, err := m.next.Get(ctx, filename, id)
+
+	log.Info(ctx, "part-response",
+		log.String("service", m.service),
+		log.String("method", "Get"),
+		log.String("layer", "part"),
+		log.Err(err))
+
+	return contents, err
+}
+
+func (m *logMiddleware) Diff(ctx context.Context, filename, from, to string) (string, error) {
+	log.Info(ctx, "part-request",
+		log.String("service", m.service),
+		log.String("method", "Diff"),
+		log.String("layer", "part"),
+		log.Any("req", map[string]any{
+			"filename": filename,
+			"from":     from,
+			"to":       to,
+		}))
+
+	diff, err := m.next.Diff(ctx, filename, from, to)
+
+	log.Info(ctx, "part-response",
+		log.String("service", m.service),
+		log.String("method", "Diff"),
+		log.String("layer", "part"),
+		log.Err(err))
+
+	return diff, err
+}
+
+func (m *logMiddleware) Restore(ctx context.Context, filename, id string) (string, error) {
+	log.Info(ctx, "part-request",
+		log.String("service", m.service),
+		log.String("method", "Restore"),
+		log.String("layer", "part"),
+		log.Any("req", map[string]any{
+			"filename": filename,
+			"id":       id,
+		}))
+
+	contents, err := m.next.Restore(ctx, filename, id)
+
+	log.Info(ctx, "part-response",
+		log.String("service", m.service),
+		log.String("method", "Restore"),
+		log.String("layer", "part"),
+		log.Err(err))
+
+	return contents, err
+}
+
+func (m *logMiddleware) Delete(ctx context.Context, filename, id string) error {
+	log.Info(ctx, "part-request",
+		log.String("service", m.service),
+		log.String("method", "Delete"),
+		log.String("layer", "part"),
+		log.Any("req", map[string]any{
+			"filename": filename,
+			"id":       id,
+		}))
+
+	err := m.next.Delete(ctx, filename, id)
+
+	log.Info(ctx, "part-response",
+		log.String("service", m.service),
+		log.String("method", "Delete"),
+		log.String("layer", "part"),
+		log.Err(err))
+
+	return err
+}
@@ -0,0 +1,303 @@
+package versioning
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/fakovacic/editor/internal/log"
+)
+
+// Retention tells which versions of file are kept, zero value keeps all.
+// Newest version is always kept, it is contents of file.
+type Retention struct {
+	Last     int           // newest versions kept
+	Every    time.Duration // beyond last ones, newest version kept in every interval, as hour or day
+	MaxAge   time.Duration // older versions are deleted
+	MaxSize  int64         // oldest versions are deleted until versions of file fit
+	Interval time.Duration // how often versions are pruned
+}
+
+// Enabled reports if retention deletes any versions
+func (r Retention) Enabled() bool {
+	return r.Last > 0 || r.Every > 0 || r.MaxAge > 0 || r.MaxSize > 0
+}
+
+// Versioning keeps versions by policy
+type Versioning interface {
+	app.Versioning
+
+	// Prune deletes versions retention does not keep, given files with
+	// versions and ones saved or listed since start are pruned every
+	// interval until ctx is done
+	Prune(context.Context, []string)
+}
+
+// NewPolicy returns versioning which does not save contents same as newest
+// version of file, unless newest one is draft and contents are written to
+// file, and prunes versions retention does not keep
+func NewPolicy(next app.Versioning, retention Retention, timeFunc func() time.Time) Versioning {
+	return &policy{
+		next:      next,
+		retention: retention,
+		timeFunc:  timeFunc,
+		newest:    make(map[string]newestVersion),
+		files:     make(map[string]bool),
+	}
+}
+
+type policy struct {
+	next      app.Versioning
+	retention Retention
+	timeFunc  func() time.Time
+
+	// newest version of files, files known to have versions
+	newest map[string]newestVersion
+	files  map[string]bool
+	sync.Mutex
+}
+
+// newestVersion is hash of newest version of file and if it is draft
+type newestVersion struct {
+	hash  string
+	draft bool
+}
+
+// Save saves contents as newest version of file, unless newest one has same
+// contents, contents written to file are saved after draft of them
+func (p *policy) Save(ctx context.Context, filename, content string) error {
+	saved := newestVersion{
+		hash:  Hash(content),
+		draft: app.GetSaveInfo(ctx).Draft,
+	}
+
+	last, err := p.newestOf(ctx, filename)
+	if err != nil {
+		log.Error(ctx, "newest version:", log.Err(err))
+	}
+
+	if last.hash == saved.hash && (saved.draft || !last.draft) {
+		return nil
+	}
+
+	err = p.next.Save(ctx, filename, content)
+	if err != nil {
+		return err
+	}
+
+	p.Lock()
+	p.newest[filename] = saved
+	p.files[filename] = true
+	p.Unlock()
+
+	return nil
+}
+
+// newestOf returns newest version of file, versions saved
+// without hash are read and hashed
+func (p *policy) newestOf(ctx context.Context, filename string) (newestVersion, error) {
+	p.Lock()
+	last, ok := p.newest[filename]
+	p.Unlock()
+
+	if ok {
+		return last, nil
+	}
+
+	versions, err := p.next.List(ctx, filename)
+	if err != nil {
+		return newestVersion{}, err
+	}
+
+	if len(versions) == 0 {
+		return newestVersion{}, nil
+	}
+
+	last = newestVersion{
+		hash:  versions[0].Hash,
+		draft: versions[0].Draft,
+	}
+
+	if last.hash == "" {
+		contents, getErr := p.next.Get(ctx, filename, versions[0].ID)
+		if getErr != nil {
+			return newestVersion{}, getErr
+		}
+
+		last.hash = Hash(contents)
+	}
+
+	p.Lock()
+	p.newest[filename] = last
+	p.files[filename] = true
+	p.Unlock()
+
+	return last, nil
+}
+
+func (p *policy) List(ctx context.Context, filename string) ([]app.Version, error) {
+	versions, err := p.next.List(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versions) > 0 {
+		p.Lock()
+		p.files[filename] = true
+		p.Unlock()
+	}
+
+	return versions, nil
+}
+
+func (p *policy) Get(ctx context.Context, filename, id string) (string, error) {
+	return p.next.Get(ctx, filename, id)
+}
+
+func (p *policy) Diff(ctx context.Context, filename, from, to string) (string, error) {
+	return p.next.Diff(ctx, filename, from, to)
+}
+
+// Restore saves version through policy, so newest version is known
+func (p *policy) Restore(ctx context.Context, filename, id string) (string, error) {
+	return Restore(ctx, p, filename, id)
+}
+
+func (p *policy) Delete(ctx context.Context, filename, id string) error {
+	err := p.next.Delete(ctx, filename, id)
+	if err != nil {
+		return err
+	}
+
+	// deleted version could be newest one
+	p.Lock()
+	delete(p.newest, filename)
+	p.Unlock()
+
+	return nil
+}
+
+func (p *policy) Prune(ctx context.Context, filenames []string) {
+	if !p.retention.Enabled() || p.retention.Interval <= 0 {
+		return
+	}
+
+	// versions saved before start are known once listed
+	for _, filename := range filenames {
+		_, err := p.List(ctx, filename)
+		if err != nil {
+			log.Error(ctx, "list versions:", log.String("file", filename), log.Err(err))
+		}
+	}
+
+	ticker := time.NewTicker(p.retention.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		p.Lock()
+
+		files := make([]string, 0, len(p.files))
+		for filename := range p.files {
+			files = append(files, filename)
+		}
+
+		p.Unlock()
+
+		sort.Strings(files)
+
+		for _, filename := range files {
+			err := p.prune(ctx, filename)
+			if err != nil {
+				log.Error(ctx, "prune versions:", log.String("file", filename), log.Err(err))
+			}
+		}
+	}
+}
+
+// prune deletes versions of file retention does not keep
+func (p *policy) prune(ctx context.Context, filename string) error {
+	versions, err := p.next.List(ctx, filename)
+	if err != nil {
+		return errors.Wrap(err, "list versions")
+	}
+
+	for _, version := range Prune(versions, p.retention, p.timeFunc()) {
+		err = p.Delete(ctx, filename, version.ID)
+		if err != nil && !errors.IsNotFound(err) {
+			return errors.Wrap(err, "delete version '%s'", version.ID)
+		}
+	}
+
+	return nil
+}
+
+// Prune returns versions retention does not keep, versions are newest first.
+// Drafts are kept by retention as other versions, but only newest version
+// which is not draft is always kept.
+func Prune(versions []app.Version, retention Retention, now time.Time) []app.Version {
+	pruned := make([]app.Version, 0)
+
+	var (
+		size    int64
+		full    bool
+		buckets = make(map[time.Time]bool)
+		found   bool
+	)
+
+	for i, version := range versions {
+		var bucket time.Time
+		if retention.Every > 0 {
+			bucket = version.Time.UTC().Truncate(retention.Every)
+		}
+
+		// newest version written to file is its contents
+		if !found && !version.Draft {
+			found = true
+			size += version.Size
+			buckets[bucket] = true
+
+			continue
+		}
+
+		keep := true
+
+		switch {
+		case i < retention.Last:
+		case retention.Every > 0:
+			keep = !buckets[bucket]
+		case retention.Last > 0:
+			keep = false
+		}
+
+		if retention.MaxAge > 0 && now.Sub(version.Time) > retention.MaxAge {
+			keep = false
+		}
+
+		// versions older than first one not fitting do not fit either
+		if keep && retention.MaxSize > 0 && (full || size+version.Size > retention.MaxSize) {
+			full = true
+			keep = false
+		}
+
+		if !keep {
+			pruned = append(pruned, version)
+
+			continue
+		}
+
+		// interval of any kept version has its version
+		buckets[bucket] = true
+		size += version.Size
+	}
+
+	return pruned
+}
@@ -0,0 +1,233 @@
+package versioning_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/mocks"
+	"github.com/fakovacic/editor/internal/app/versioning"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrune(t *testing.T) {
+	now := time.Date(2023, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	// versions every 20 minutes, newest first
+	versions := make([]app.Version, 0)
+
+	for i := 0; i < 8; i++ {
+		versions = append(versions, app.Version{
+			ID:   now.Add(-time.Duration(i) * 20 * time.Minute).Format("1504"),
+			Time: now.Add(-time.Duration(i) * 20 * time.Minute),
+			Size: 10,
+		})
+	}
+
+	cases := []struct {
+		it string
+
+		retention versioning.Retention
+
+		expected []string
+	}{
+		{
+			it: "all kept",
+
+			expected: []string{},
+		},
+		{
+			it: "last kept",
+			retention: versioning.Retention{
+				Last: 3,
+			},
+
+			expected: []string{"1100", "1040", "1020", "1000", "0940"},
+		},
+		{
+			it: "one every hour beyond last",
+			retention: versioning.Retention{
+				Last:  2,
+				Every: time.Hour,
+			},
+
+			// hour of last kept ones already has version
+			expected: []string{"1120", "1100", "1020", "1000"},
+		},
+		{
+			it: "one every hour",
+			retention: versioning.Retention{
+				Every: time.Hour,
+			},
+
+			expected: []string{"1120", "1100", "1020", "1000"},
+		},
+		{
+			it: "max age",
+			retention: versioning.Retention{
+				MaxAge: time.Hour,
+			},
+
+			expected: []string{"1040", "1020", "1000", "0940"},
+		},
+		{
+			it: "max size",
+			retention: versioning.Retention{
+				MaxSize: 35,
+			},
+
+			expected: []string{"1100", "1040", "1020", "1000", "0940"},
+		},
+		{
+			it: "newest kept",
+			retention: versioning.Retention{
+				MaxAge:  time.Minute,
+				MaxSize: 1,
+			},
+
+			expected: []string{"1140", "1120", "1100", "1040", "1020", "1000", "0940"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			pruned := versioning.Prune(versions, tc.retention, now)
+
+			ids := make([]string, 0, len(pruned))
+			for _, version := range pruned {
+				ids = append(ids, version.ID)
+			}
+
+			assert.Equal(t, tc.expected, ids)
+		})
+	}
+}
+
+func TestPruneDrafts(t *testing.T) {
+	now := time.Date(2023, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	versions := []app.Version{
+		{ID: "draft", Time: now.Add(-2 * time.Hour), SaveInfo: app.SaveInfo{Draft: true}},
+		{ID: "written", Time: now.Add(-3 * time.Hour)},
+		{ID: "old", Time: now.Add(-4 * time.Hour)},
+	}
+
+	// newest version written to file is kept as contents of file
+	pruned := versioning.Prune(versions, versioning.Retention{MaxAge: time.Hour}, now)
+
+	assert.Equal(t, []app.Version{versions[0], versions[2]}, pruned)
+}
+
+func TestPolicySave(t *testing.T) {
+	ctx := context.Background()
+
+	saved := []string{"a {}"}
+
+	next := &mocks.VersioningMock{
+		SaveFunc: func(_ context.Context, _, content string) error {
+			saved = append(saved, content)
+
+			return nil
+		},
+		ListFunc: func(_ context.Context, filename string) ([]app.Version, error) {
+			// version saved without metadata has no hash
+			return []app.Version{
+				{
+					ID:   "1697628153",
+					Name: filename,
+				},
+			}, nil
+		},
+		GetFunc: func(_ context.Context, _, _ string) (string, error) {
+			return "a {}", nil
+		},
+	}
+
+	policy := versioning.NewPolicy(next, versioning.Retention{}, time.Now)
+
+	// same as newest version
+	err := policy.Save(ctx, "main.css", "a {}")
+	assert.NoError(t, err)
+
+	err = policy.Save(ctx, "main.css", "b {}")
+	assert.NoError(t, err)
+
+	err = policy.Save(ctx, "main.css", "b {}")
+	assert.NoError(t, err)
+
+	err = policy.Save(ctx, "main.css", "a {}")
+	assert.NoError(t, err)
+
+	// contents of draft written to file are saved again, draft of them is not
+	draftCtx := app.WithSaveInfo(ctx, app.SaveInfo{Draft: true})
+
+	err = policy.Save(draftCtx, "main.css", "c {}")
+	assert.NoError(t, err)
+
+	err = policy.Save(ctx, "main.css", "c {}")
+	assert.NoError(t, err)
+
+	err = policy.Save(draftCtx, "main.css", "c {}")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"a {}", "b {}", "a {}", "c {}", "c {}"}, saved)
+
+	// newest version is read once
+	assert.Len(t, next.ListCalls(), 1)
+	assert.Len(t, next.GetCalls(), 1)
+}
+
+func TestPolicyPrune(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		deleted []string
+		mu      sync.Mutex
+	)
+
+	next := &mocks.VersioningMock{
+		ListFunc: func(_ context.Context, filename string) ([]app.Version, error) {
+			if filename != "css/main.css" {
+				return []app.Version{}, nil
+			}
+
+			return []app.Version{
+				{ID: "1697628155", Name: filename},
+				{ID: "1697628154", Name: filename},
+			}, nil
+		},
+		DeleteFunc: func(_ context.Context, _, id string) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			deleted = append(deleted, id)
+
+			return nil
+		},
+	}
+
+	policy := versioning.NewPolicy(next, versioning.Retention{
+		Last:     1,
+		Interval: time.Millisecond,
+	}, time.Now)
+
+	// versions saved before start are pruned
+	go policy.Prune(ctx, []string{"css/main.css", "index.html"})
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(deleted) > 0
+	}, time.Second, time.Millisecond)
+
+	cancel()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, "1697628154", deleted[0])
+}
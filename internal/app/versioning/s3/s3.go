@@ -0,0 +1,131 @@
+package s3
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/versioning"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/fakovacic/editor/internal/s3"
+)
+
+// New returns versioning which saves each version as bucket object
+// under key prefix
+func New(client s3.Client, prefix string, timeFunc func() time.Time) app.Versioning {
+	return &versioningS3{
+		client:   client,
+		prefix:   prefix,
+		timeFunc: timeFunc,
+	}
+}
+
+type versioningS3 struct {
+	client   s3.Client
+	prefix   string
+	timeFunc func() time.Time
+}
+
+// Save saves version with its metadata in json object alongside it
+func (s *versioningS3) Save(ctx context.Context, filename, content string) error {
+	now := s.timeFunc()
+	key := path.Join(s.prefix, versioning.Name(now, filename))
+
+	_, err := s.client.Put(ctx, key, content, s3.Condition{})
+	if err != nil {
+		return err
+	}
+
+	meta, err := json.Marshal(versioning.New(ctx, versioning.ID(now), now, filename, content))
+	if err != nil {
+		return errors.Wrap(err, "marshal version")
+	}
+
+	_, err = s.client.Put(ctx, versioning.MetaName(key), string(meta), s3.Condition{})
+	if err != nil {
+		return errors.Wrap(err, "put version metadata")
+	}
+
+	return nil
+}
+
+func (s *versioningS3) List(ctx context.Context, filename string) ([]app.Version, error) {
+	// versions are listed as keys in prefix dir, not keys starting with prefix
+	prefix := strings.TrimSuffix(s.prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	objects, err := s.client.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]app.Version, 0)
+
+	for _, obj := range objects {
+		version, ok := versioning.Parse(strings.TrimPrefix(obj.Key, prefix), filename)
+		if !ok {
+			continue
+		}
+
+		version.Size = obj.Size
+
+		// metadata is object of its own, one request for each version
+		meta, metaErr := s.client.Get(ctx, versioning.MetaName(obj.Key))
+		if metaErr == nil {
+			version = versioning.Meta(version, []byte(meta.Body))
+		}
+
+		versions = append(versions, version)
+	}
+
+	versioning.Sort(versions)
+
+	return versions, nil
+}
+
+func (s *versioningS3) Get(ctx context.Context, filename, id string) (string, error) {
+	err := versioning.ValidID(id)
+	if err != nil {
+		return "", err
+	}
+
+	obj, err := s.client.Get(ctx, path.Join(s.prefix, id+"_"+versioning.FileName(filename)))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", errors.NotFound("version '%s' of file '%s' not found", id, filename)
+		}
+
+		return "", err
+	}
+
+	return obj.Body, nil
+}
+
+func (s *versioningS3) Diff(ctx context.Context, filename, from, to string) (string, error) {
+	return versioning.Diff(ctx, s, filename, from, to)
+}
+
+func (s *versioningS3) Restore(ctx context.Context, filename, id string) (string, error) {
+	return versioning.Restore(ctx, s, filename, id)
+}
+
+func (s *versioningS3) Delete(ctx context.Context, filename, id string) error {
+	err := versioning.ValidID(id)
+	if err != nil {
+		return err
+	}
+
+	key := path.Join(s.prefix, id+"_"+versioning.FileName(filename))
+
+	err = s.client.Delete(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	return s.client.Delete(ctx, versioning.MetaName(key))
+}
@@ -0,0 +1,90 @@
+package s3_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+	versioningS3 "github.com/fakovacic/editor/internal/app/versioning/s3"
+	"github.com/fakovacic/editor/internal/s3"
+	"github.com/fakovacic/editor/internal/s3/s3test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSave(t *testing.T) {
+	srv := s3test.NewServer()
+	defer srv.Close()
+
+	client, err := s3.New(srv.Config(), http.DefaultClient, time.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timeFunc := func() time.Time {
+		return time.Unix(1697628153, 0)
+	}
+
+	err = versioningS3.New(client, "versions/", timeFunc).Save(context.Background(), "main.css", "a {}")
+	assert.NoError(t, err)
+
+	body, ok := srv.Object("versions/1697628153000000000_main.css")
+	assert.True(t, ok)
+	assert.Equal(t, "a {}", body)
+}
+
+func TestVersions(t *testing.T) {
+	srv := s3test.NewServer()
+	defer srv.Close()
+
+	client, err := s3.New(srv.Config(), http.DefaultClient, time.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	now := time.Unix(1697628153, 0)
+
+	versioning := versioningS3.New(client, "versions", func() time.Time {
+		now = now.Add(time.Second)
+
+		return now
+	})
+
+	err = versioning.Save(ctx, "main.css", "a {}")
+	assert.NoError(t, err)
+
+	err = versioning.Save(app.WithSaveInfo(ctx, app.SaveInfo{Trigger: app.SaveDisconnect}), "main.css", "b {} b {}")
+	assert.NoError(t, err)
+
+	// objects outside of prefix are not versions
+	srv.SetObject("main.css", "c {}")
+	srv.SetObject("versions-old/1697628100000000000_main.css", "c {}")
+
+	versions, err := versioning.List(ctx, "main.css")
+	assert.NoError(t, err)
+
+	ids := make([]string, 0, len(versions))
+	sizes := make([]int64, 0, len(versions))
+
+	for _, version := range versions {
+		ids = append(ids, version.ID)
+		sizes = append(sizes, version.Size)
+	}
+
+	assert.Equal(t, []string{"1697628155000000000", "1697628154000000000"}, ids)
+	assert.Equal(t, []int64{9, 4}, sizes)
+
+	// metadata is read from object alongside version
+	assert.Equal(t, app.SaveDisconnect, versions[0].Trigger)
+	assert.Equal(t, app.SaveTrigger(""), versions[1].Trigger)
+	assert.NotEmpty(t, versions[1].Hash)
+
+	contents, err := versioning.Get(ctx, "main.css", "1697628154000000000")
+	assert.NoError(t, err)
+	assert.Equal(t, "a {}", contents)
+
+	_, err = versioning.Get(ctx, "main.css", "1")
+	assert.Equal(t, "version '1' of file 'main.css' not found", err.Error())
+}
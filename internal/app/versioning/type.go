@@ -11,6 +11,8 @@ type Type string
 const (
 	HTTP Type = "http"
 	File Type = "file"
+	S3   Type = "s3"
+	Git  Type = "git"
 )
 
 func (t Type) String() string {
@@ -24,6 +26,10 @@ func (t *Type) Parse(s string) error {
 		*t = HTTP
 	case "file":
 		*t = File
+	case "s3":
+		*t = S3
+	case "git":
+		*t = Git
 	default:
 		return errors.BadRequest("invalid msg type '%s'", s)
 	}
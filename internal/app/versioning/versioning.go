@@ -0,0 +1,172 @@
+package versioning
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor/diff"
+	"github.com/fakovacic/editor/internal/errors"
+)
+
+const (
+	diffContext = 3
+
+	// secondIDs are ids of versions saved in seconds before ids were
+	// nanoseconds, as nanoseconds they would be first minutes of 1970
+	secondIDs = 1e12
+)
+
+// Name returns name version of file is saved under, as 1697628153000000000_main.css
+func Name(t time.Time, filename string) string {
+	return ID(t) + "_" + FileName(filename)
+}
+
+// FileName returns escaped path of file relative to workspace, versions
+// of files in directories are kept flat as 1697628153000000000_css%2Fmain.css
+func FileName(filename string) string {
+	return url.PathEscape(filename)
+}
+
+// ID returns id of version saved at t, nanoseconds so versions of file
+// saved in same second do not overwrite each other
+func ID(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// idTime returns time version with id was saved at, ids of versions
+// saved before ids were nanoseconds are seconds
+func idTime(id int64) time.Time {
+	if id < secondIDs {
+		return time.Unix(id, 0).UTC()
+	}
+
+	return time.Unix(0, id).UTC()
+}
+
+// New returns version of contents saved at t with save info of ctx
+func New(ctx context.Context, id string, t time.Time, filename, contents string) app.Version {
+	return app.Version{
+		ID:       id,
+		Name:     filename,
+		Time:     t.UTC(),
+		Size:     int64(len(contents)),
+		Hash:     Hash(contents),
+		SaveInfo: app.GetSaveInfo(ctx),
+	}
+}
+
+// Hash returns hex sha256 of contents
+func Hash(contents string) string {
+	sum := sha256.Sum256([]byte(contents))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// MetaName returns name version metadata is saved under alongside version
+func MetaName(name string) string {
+	return name + ".json"
+}
+
+// Meta returns version with metadata saved alongside it, versions
+// saved without metadata are returned as they are, time of save is
+// kept from metadata when set
+func Meta(version app.Version, data []byte) app.Version {
+	var meta app.Version
+
+	err := json.Unmarshal(data, &meta)
+	if err != nil {
+		return version
+	}
+
+	if !meta.Time.IsZero() {
+		version.Time = meta.Time
+	}
+
+	version.Hash = meta.Hash
+	version.SaveInfo = meta.SaveInfo
+
+	return version
+}
+
+// Parse returns version of file saved under name, false if name is
+// not version of file
+func Parse(name, filename string) (app.Version, bool) {
+	id, rest, ok := strings.Cut(name, "_")
+	if !ok || rest != FileName(filename) {
+		return app.Version{}, false
+	}
+
+	unix, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || unix < 0 {
+		return app.Version{}, false
+	}
+
+	return app.Version{
+		ID:   id,
+		Name: filename,
+		Time: idTime(unix),
+	}, true
+}
+
+// ValidID returns error if id is not id of version saved by Name,
+// so id can not point outside of versions
+func ValidID(id string) error {
+	unix, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || unix < 0 || strconv.FormatInt(unix, 10) != id {
+		return errors.BadRequest("version id '%s' not valid", id)
+	}
+
+	return nil
+}
+
+// Sort sorts versions newest first
+func Sort(versions []app.Version) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Time.After(versions[j].Time)
+	})
+}
+
+// Diff returns unified diff between versions of file read from versioning
+func Diff(ctx context.Context, versioning app.Versioning, filename, from, to string) (string, error) {
+	contentsFrom, err := versioning.Get(ctx, filename, from)
+	if err != nil {
+		return "", err
+	}
+
+	contentsTo, err := versioning.Get(ctx, filename, to)
+	if err != nil {
+		return "", err
+	}
+
+	return diff.Unified(
+		fmt.Sprintf("%s@%s", filename, from),
+		fmt.Sprintf("%s@%s", filename, to),
+		contentsFrom,
+		contentsTo,
+		diffContext,
+	), nil
+}
+
+// Restore saves version of file as newest version and returns its contents
+func Restore(ctx context.Context, versioning app.Versioning, filename, id string) (string, error) {
+	contents, err := versioning.Get(ctx, filename, id)
+	if err != nil {
+		return "", err
+	}
+
+	err = versioning.Save(ctx, filename, contents)
+	if err != nil {
+		return "", errors.Wrap(err, "save restored version")
+	}
+
+	return contents, nil
+}
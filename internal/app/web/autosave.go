@@ -0,0 +1,135 @@
+package web
+
+import (
+	"context"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/fakovacic/editor/internal/log"
+)
+
+// autosaveCheck is how often files open by clients are checked for autosave
+const autosaveCheck = time.Second
+
+// autosaved is revision of file and time it was last saved by autosave
+type autosaved struct {
+	revision int
+	time     time.Time
+}
+
+// Autosave saves files open by clients as autosave tells until ctx is done,
+// clients of file are told if it was saved. Votes of clients are kept,
+// file is still saved by clients as before.
+func (s *service) Autosave(ctx context.Context, autosave app.Autosave) {
+	ticker := time.NewTicker(autosaveCheck)
+	defer ticker.Stop()
+
+	saved := make(map[string]autosaved)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.autosaveFiles(ctx, autosave, saved, time.Now())
+	}
+}
+
+// autosaveFiles saves files with unsaved changes, changed enough since they
+// were written or last autosaved, saved keeps state of files between checks
+func (s *service) autosaveFiles(ctx context.Context, autosave app.Autosave, saved map[string]autosaved, now time.Time) {
+	open := make(map[string]bool)
+
+	for _, session := range s.workspace.Sessions(ctx) {
+		open[session.Name] = true
+
+		meta := session.Editor.FileMeta(ctx)
+		if meta == nil {
+			continue
+		}
+
+		// revision starts again when file is loaded again
+		last, ok := saved[session.Name]
+		if !ok || meta.Revision < last.revision {
+			last = autosaved{
+				time: now,
+			}
+
+			saved[session.Name] = last
+		}
+
+		if !meta.Dirty {
+			continue
+		}
+
+		// changes are counted from last write or autosave
+		changes := meta.Revision - max(meta.SavedRevision, last.revision)
+
+		if !autosave.Due(changes, now.Sub(last.time)) {
+			continue
+		}
+
+		// failed save is tried again once file is due again
+		saved[session.Name] = autosaved{
+			revision: meta.Revision,
+			time:     now,
+		}
+
+		msgType, msg := app.MsgServerFileAutosaved, autosave.Target.String()
+
+		err := s.autosave(ctx, session, autosave.Target)
+		if err != nil {
+			log.Error(ctx, "autosave file:", log.String("name", session.Name), log.Err(err))
+
+			msgType, msg = app.MsgServerFileNotSaved, notSaved(session.Name, err)
+		}
+
+		err = session.Hub.Brodcast(ctx, msgType, "", "", msg, nil)
+		if err != nil {
+			log.Error(ctx, "brodcast:", log.Err(err))
+		}
+	}
+
+	for name := range saved {
+		if !open[name] {
+			delete(saved, name)
+		}
+	}
+}
+
+// autosave writes file or saves its contents as version only, clients
+// editing file are authors of version with autosave trigger
+func (s *service) autosave(ctx context.Context, session *app.Session, target app.AutosaveTarget) error {
+	if target == app.AutosaveFile {
+		_, _, _, err := s.write(ctx, session, app.SaveAuto, "")
+
+		return err
+	}
+
+	if s.versioning == nil {
+		return errors.BadRequest("versioning not enabled")
+	}
+
+	// draft is saved as file would be written
+	contents, err := session.Editor.Encoded(ctx)
+	if err != nil {
+		return errors.Wrap(err, "editor encoded content")
+	}
+
+	ctx = app.WithSaveInfo(ctx, app.SaveInfo{
+		Authors: session.Hub.Usernames(),
+		Ready:   session.Hub.Ready(),
+		Trigger: app.SaveAuto,
+		Draft:   true,
+	})
+
+	err = s.versioning.Save(ctx, session.Name, contents)
+	if err != nil {
+		return errors.Wrap(err, "versioning save")
+	}
+
+	return nil
+}
@@ -0,0 +1,255 @@
+package web_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/mocks"
+	"github.com/fakovacic/editor/internal/app/web"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutosave(t *testing.T) {
+	type brodcast struct {
+		msgType app.MsgType
+		msg     string
+	}
+
+	opened := time.Date(2023, 10, 18, 11, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		it string
+
+		autosave   app.Autosave
+		saved      map[string]web.Autosaved
+		revision   int
+		saveState  app.SaveState
+		elapsed    time.Duration
+		noVersions bool
+
+		expectedBrodcasts []brodcast
+		expectedVersions  int
+		expectedWrites    int
+		expectedSaved     web.Autosaved
+	}{
+		{
+			it: "changes counted from load",
+			autosave: app.Autosave{
+				Changes: 5,
+				Target:  app.AutosaveDraft,
+			},
+			saved:     map[string]web.Autosaved{},
+			revision:  5,
+			saveState: app.SaveState{Dirty: true},
+
+			expectedBrodcasts: []brodcast{
+				{
+					msgType: app.MsgServerFileAutosaved,
+					msg:     "draft",
+				},
+			},
+			expectedVersions: 1,
+			expectedSaved:    web.NewAutosaved(5, opened),
+		},
+		{
+			it: "loaded file not saved",
+			autosave: app.Autosave{
+				Interval: time.Minute,
+				Target:   app.AutosaveDraft,
+			},
+			saved:    map[string]web.Autosaved{},
+			revision: 0,
+
+			expectedBrodcasts: []brodcast{},
+			expectedSaved:     web.NewAutosaved(0, opened),
+		},
+		{
+			it: "not enough changes",
+			autosave: app.Autosave{
+				Interval: time.Hour,
+				Changes:  3,
+				Target:   app.AutosaveDraft,
+			},
+			revision:  4,
+			saveState: app.SaveState{Dirty: true},
+			elapsed:   time.Minute,
+
+			expectedBrodcasts: []brodcast{},
+			expectedSaved:     web.NewAutosaved(2, opened),
+		},
+		{
+			it: "draft saved after changes",
+			autosave: app.Autosave{
+				Changes: 3,
+				Target:  app.AutosaveDraft,
+			},
+			revision:  5,
+			saveState: app.SaveState{Dirty: true},
+			elapsed:   time.Minute,
+
+			expectedBrodcasts: []brodcast{
+				{
+					msgType: app.MsgServerFileAutosaved,
+					msg:     "draft",
+				},
+			},
+			expectedVersions: 1,
+			expectedSaved:    web.NewAutosaved(5, opened.Add(time.Minute)),
+		},
+		{
+			it: "file saved after interval",
+			autosave: app.Autosave{
+				Interval: 5 * time.Minute,
+				Target:   app.AutosaveFile,
+			},
+			revision:  3,
+			saveState: app.SaveState{Dirty: true},
+			elapsed:   5 * time.Minute,
+
+			expectedBrodcasts: []brodcast{
+				{
+					msgType: app.MsgServerFileAutosaved,
+					msg:     "file",
+				},
+			},
+			expectedWrites: 1,
+			expectedSaved:  web.NewAutosaved(3, opened.Add(5*time.Minute)),
+		},
+		{
+			it: "saved file not saved after interval",
+			autosave: app.Autosave{
+				Interval: 5 * time.Minute,
+				Target:   app.AutosaveFile,
+			},
+			revision:  4,
+			saveState: app.SaveState{SavedRevision: 4},
+			elapsed:   time.Hour,
+
+			expectedBrodcasts: []brodcast{},
+			expectedSaved:     web.NewAutosaved(2, opened),
+		},
+		{
+			it: "changes counted from write",
+			autosave: app.Autosave{
+				Changes: 3,
+				Target:  app.AutosaveDraft,
+			},
+			revision:  5,
+			saveState: app.SaveState{Dirty: true, SavedRevision: 4},
+			elapsed:   time.Minute,
+
+			expectedBrodcasts: []brodcast{},
+			expectedSaved:     web.NewAutosaved(2, opened),
+		},
+		{
+			it: "draft needs versioning",
+			autosave: app.Autosave{
+				Changes: 1,
+				Target:  app.AutosaveDraft,
+			},
+			revision:   3,
+			saveState:  app.SaveState{Dirty: true},
+			elapsed:    time.Minute,
+			noVersions: true,
+
+			expectedBrodcasts: []brodcast{
+				{
+					msgType: app.MsgServerFileNotSaved,
+					msg:     "file 'css/main.css' not saved",
+				},
+			},
+			expectedSaved: web.NewAutosaved(3, opened.Add(time.Minute)),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			ctx := context.Background()
+
+			brodcasts := make([]brodcast, 0)
+
+			editorMock := &mocks.EditorMock{
+				FileMetaFunc: func(_ context.Context) *app.FileMeta {
+					return &app.FileMeta{
+						Revision:  tc.revision,
+						SaveState: tc.saveState,
+					}
+				},
+				EncodedFunc: func(_ context.Context) (string, error) {
+					return "a {}\r\n", nil
+				},
+				WriteFunc: func(ctx context.Context) ([]*app.ChangeMsg, error) {
+					assert.Equal(t, app.SaveAuto, app.GetSaveInfo(ctx).Trigger)
+
+					return nil, nil
+				},
+			}
+
+			session := &app.Session{
+				Name:   "css/main.css",
+				Editor: editorMock,
+				Hub: &mocks.HubMock{
+					BrodcastFunc: func(_ context.Context, msgType app.MsgType, _, _, msg string, _ *app.FileMeta) error {
+						brodcasts = append(brodcasts, brodcast{
+							msgType: msgType,
+							msg:     msg,
+						})
+
+						return nil
+					},
+					UsernamesFunc: func() []string {
+						return []string{"mock-username"}
+					},
+					ReadyFunc: func() []string {
+						return nil
+					},
+				},
+			}
+
+			versioningMock := &mocks.VersioningMock{
+				SaveFunc: func(ctx context.Context, filename, contents string) error {
+					assert.Equal(t, "css/main.css", filename)
+					assert.Equal(t, "a {}\r\n", contents)
+					assert.Equal(t, app.SaveInfo{
+						Authors: []string{"mock-username"},
+						Trigger: app.SaveAuto,
+						Draft:   true,
+					}, app.GetSaveInfo(ctx))
+
+					return nil
+				},
+			}
+
+			var versioning app.Versioning = versioningMock
+			if tc.noVersions {
+				versioning = nil
+			}
+
+			service := web.New(&mocks.HubMock{}, &mocks.WorkspaceMock{
+				SessionsFunc: func(_ context.Context) []*app.Session {
+					return []*app.Session{session}
+				},
+			}, versioning, nil)
+
+			// file was last saved at revision 2, closed file is forgotten
+			saved := tc.saved
+			if saved == nil {
+				saved = map[string]web.Autosaved{
+					"css/main.css":  web.NewAutosaved(2, opened),
+					"css/other.css": web.NewAutosaved(1, opened),
+				}
+			}
+
+			web.AutosaveFiles(ctx, service, tc.autosave, saved, opened.Add(tc.elapsed))
+
+			assert.Equal(t, tc.expectedBrodcasts, brodcasts)
+			assert.Len(t, versioningMock.SaveCalls(), tc.expectedVersions)
+			assert.Len(t, editorMock.WriteCalls(), tc.expectedWrites)
+			assert.NotContains(t, saved, "css/other.css")
+
+			assert.Equal(t, tc.expectedSaved, saved["css/main.css"])
+		})
+	}
+}
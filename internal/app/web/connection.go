@@ -17,34 +17,35 @@ const (
 	msgsChan = 10
 )
 
-func (s *service) Connection(ctx context.Context, id string, c *websocket.Conn) error {
+func (s *service) Connection(ctx context.Context, id, file string, c *websocket.Conn) error {
+	if s.closing.Load() {
+		return errors.New("server shutting down")
+	}
+
 	client, err := s.register(ctx, id, c)
 	if err != nil {
 		return errors.Wrap(err, "register")
 	}
 
+	var session *app.Session
+
 	defer func() {
-		deferErr := s.unregister(ctx, client)
+		deferErr := s.unregister(ctx, session, client)
 		if deferErr != nil {
 			log.Error(ctx, "unregister:", log.Err(deferErr))
 		}
 	}()
 
-	// send contents to client
-	fileContents, err := s.editor.Read(ctx)
-	if err != nil {
-		return errors.Wrap(err, "editor read content")
-	}
-
-	err = s.hub.Brodcast(ctx, app.MsgConnected, client.ID, client.Username, fileContents, s.editor.FileMeta(ctx))
+	// in single file mode empty name opens the file,
+	// otherwise client opens file with open file message
+	session, err = s.join(ctx, file, client)
 	if err != nil {
-		return errors.Wrap(err, "brodcast %s", app.MsgConnected)
-	}
+		log.Error(ctx, "join:", log.Err(err))
 
-	// inform other clients about new client
-	err = s.hub.Brodcast(ctx, app.MsgClientsConnected, client.ID, client.Username, "", nil)
-	if err != nil {
-		return errors.Wrap(err, "brodcast %s", app.MsgClientsConnected)
+		err = s.users.Brodcast(ctx, app.MsgServerFileNotOpened, client.ID, client.Username, file, nil)
+		if err != nil {
+			return errors.Wrap(err, "brodcast %s", app.MsgServerFileNotOpened)
+		}
 	}
 
 	// conn ttl
@@ -83,7 +84,25 @@ func (s *service) Connection(ctx context.Context, id string, c *websocket.Conn)
 
 			log.Info(ctx, fmt.Sprintf("websocket message received: %s", wsMsg.Type))
 
-			msgType, msgContent, closeConn, err := s.IncommingMsg(ctx, wsMsg.Type, message, client.ID)
+			// messages changing session of client
+			switch wsMsg.Type {
+			case app.MsgConnOpenFile:
+				session, err = s.openFile(ctx, session, message, client)
+				if err != nil {
+					log.Error(ctx, "open file:", log.Err(err))
+				}
+
+				continue
+			case app.MsgConnCreateFile, app.MsgConnRenameFile, app.MsgConnDeleteFile:
+				session, err = s.fileOp(ctx, session, wsMsg.Type, message, client)
+				if err != nil {
+					log.Error(ctx, "file operation:", log.Err(err))
+				}
+
+				continue
+			}
+
+			msgType, msgContent, closeConn, err := s.IncommingMsg(ctx, session, wsMsg.Type, message, client.ID)
 			if err != nil {
 				log.Error(ctx, "handle message:", log.Err(err))
 			}
@@ -93,7 +112,7 @@ func (s *service) Connection(ctx context.Context, id string, c *websocket.Conn)
 			}
 
 			if msgType != app.MsgNil {
-				err = s.hub.Brodcast(
+				err = session.Hub.Brodcast(
 					ctx,
 					msgType,
 					client.ID,
@@ -109,68 +128,173 @@ func (s *service) Connection(ctx context.Context, id string, c *websocket.Conn)
 	}
 }
 
-func (s *service) register(ctx context.Context, id string, wsConn *websocket.Conn) (*app.Client, error) {
+func (s *service) register(_ context.Context, id string, wsConn *websocket.Conn) (*app.Client, error) {
 	_, err := uuid.Parse(id)
 	if err != nil {
 		return nil, errors.New("id not valid")
 	}
 
-	client, ok := s.hub.Get(id)
+	client, ok := s.users.Get(id)
 	if !ok {
 		return nil, errors.New("id not exist")
 	}
 
 	client.Conn = wsConn
 
-	// check client count
-	// if no clients, load content
-	if s.hub.CountRegistered() == 0 {
-		loadErr := s.editor.Load(ctx)
-		if loadErr != nil {
-			return nil, errors.Wrap(loadErr, "editor load content")
+	return client, nil
+}
+
+func (s *service) unregister(ctx context.Context, session *app.Session, client *app.Client) error {
+	// client logs in again after disconnect
+	defer s.users.Unregister(client)
+
+	if session == nil {
+		return nil
+	}
+
+	err := session.Hub.Brodcast(ctx, app.MsgConnDisconnect, client.ID, client.Username, "", nil)
+	if err != nil {
+		return errors.Wrap(err, "brodcast %s", app.MsgConnDisconnect)
+	}
+
+	err = s.leave(ctx, session, client)
+	if err != nil {
+		return errors.Wrap(err, "leave")
+	}
+
+	return nil
+}
+
+// openFile moves client from current session to session of requested file
+func (s *service) openFile(ctx context.Context, current *app.Session, message []byte, client *app.Client) (*app.Session, error) {
+	var msg app.WSMsgOpenFile
+
+	err := json.Unmarshal(message, &msg)
+	if err != nil {
+		return current, errors.Wrap(err, "unmarshall open file msg")
+	}
+
+	return s.switchFile(ctx, current, msg.Data.Name, client)
+}
+
+// switchFile leaves current session and joins session of file
+func (s *service) switchFile(ctx context.Context, current *app.Session, name string, client *app.Client) (*app.Session, error) {
+	if current != nil {
+		err := s.leave(ctx, current, client)
+		if err != nil {
+			return nil, errors.Wrap(err, "leave")
+		}
+	}
+
+	session, err := s.join(ctx, name, client)
+	if err != nil {
+		brodcastErr := s.users.Brodcast(ctx, app.MsgServerFileNotOpened, client.ID, client.Username, name, nil)
+		if brodcastErr != nil {
+			log.Error(ctx, "brodcast:", log.Err(brodcastErr))
 		}
+
+		return nil, errors.Wrap(err, "join")
 	}
 
-	s.hub.Register(client)
+	return session, nil
+}
+
+// join opens session of file for client and sends it file contents
+func (s *service) join(ctx context.Context, name string, client *app.Client) (*app.Session, error) {
+	// version saved on load records client who opened file
+	ctx = app.WithSaveInfo(ctx, app.SaveInfo{
+		Authors: []string{client.Username},
+		Trigger: app.SaveLoad,
+	})
 
-	err = s.writeValidator.AddClient(ctx, client.ID)
+	session, err := s.workspace.Open(ctx, name)
 	if err != nil {
-		return nil, errors.Wrap(err, "validator add client")
+		return nil, errors.Wrap(err, "workspace open")
 	}
 
-	return client, nil
+	session.Hub.Register(client)
+	session.Hub.SetReady(client.ID, false)
+
+	err = s.connected(ctx, session, client)
+	if err != nil {
+		leaveErr := s.leave(ctx, session, client)
+		if leaveErr != nil {
+			log.Error(ctx, "leave:", log.Err(leaveErr))
+		}
+
+		return nil, err
+	}
+
+	return session, nil
 }
 
-func (s *service) unregister(ctx context.Context, client *app.Client) error {
-	err := s.hub.Brodcast(ctx, app.MsgConnDisconnect, client.ID, client.Username, "", nil)
+func (s *service) connected(ctx context.Context, session *app.Session, client *app.Client) error {
+	err := session.WriteValidator.AddClient(ctx, client.ID, client.Username)
 	if err != nil {
-		return errors.Wrap(err, "brodcast %s", app.MsgConnDisconnect)
+		return errors.Wrap(err, "validator add client")
 	}
 
-	s.hub.Unregister(client)
+	// send contents to client
+	fileContents, fileMeta, err := session.Editor.Read(ctx)
+	if err != nil {
+		return errors.Wrap(err, "editor read content")
+	}
 
-	err = s.hub.Brodcast(ctx, app.MsgClientsDisconnected, client.ID, client.Username, "", nil)
+	if fileMeta != nil {
+		fileMeta.Path = session.Name
+	}
+
+	err = session.Hub.Brodcast(ctx, app.MsgConnected, client.ID, client.Username, fileContents, fileMeta)
+	if err != nil {
+		return errors.Wrap(err, "brodcast %s", app.MsgConnected)
+	}
+
+	// send replication state if editor mode needs it
+	state, err := session.Editor.State(ctx)
+	if err != nil {
+		return errors.Wrap(err, "editor state")
+	}
+
+	if state != "" {
+		err = session.Hub.Brodcast(ctx, app.MsgServerCRDTState, client.ID, client.Username, state, nil)
+		if err != nil {
+			return errors.Wrap(err, "brodcast %s", app.MsgServerCRDTState)
+		}
+	}
+
+	// inform other clients about new client
+	err = session.Hub.Brodcast(ctx, app.MsgClientsConnected, client.ID, client.Username, "", nil)
+	if err != nil {
+		return errors.Wrap(err, "brodcast %s", app.MsgClientsConnected)
+	}
+
+	return nil
+}
+
+// leave removes client from session, last client leaving file
+// closes it in workspace so file is written and unloaded
+func (s *service) leave(ctx context.Context, session *app.Session, client *app.Client) error {
+	session.Hub.Unregister(client)
+
+	err := session.Hub.Brodcast(ctx, app.MsgClientsDisconnected, client.ID, client.Username, "", nil)
 	if err != nil {
 		return errors.Wrap(err, "brodcast %s", app.MsgClientsDisconnected)
 	}
 
-	err = s.writeValidator.RemoveClient(ctx, client.ID)
+	err = session.WriteValidator.RemoveClient(ctx, client.ID)
 	if err != nil {
 		return errors.Wrap(err, "validator remove client")
 	}
 
-	// check client count
-	// if no clients, save file
-	if s.hub.CountRegistered() == 0 {
-		writeErr := s.editor.Write(ctx)
-		if writeErr != nil {
-			return errors.Wrap(writeErr, "editor write")
-		}
+	// file is written on close only if client was last one
+	ctx = app.WithSaveInfo(ctx, app.SaveInfo{
+		Authors: []string{client.Username},
+		Trigger: app.SaveDisconnect,
+	})
 
-		unloadErr := s.editor.Unload(ctx)
-		if unloadErr != nil {
-			return errors.Wrap(unloadErr, "editor unload")
-		}
+	err = s.workspace.Close(ctx, session.Name)
+	if err != nil {
+		return errors.Wrap(err, "workspace close")
 	}
 
 	return nil
@@ -0,0 +1,78 @@
+package web
+
+import (
+	"context"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/fakovacic/editor/internal/log"
+)
+
+// WatchContents reloads files open by clients every interval until ctx
+// is done, source changes are merged and sent to clients of file as
+// changes none of them made
+func (s *service) WatchContents(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// last error of file, same error is reported once
+	failed := make(map[string]string)
+
+	for {
+		for _, session := range s.workspace.Sessions(ctx) {
+			err := s.reload(ctx, session)
+			if err == nil {
+				delete(failed, session.Name)
+
+				continue
+			}
+
+			if failed[session.Name] == err.Error() {
+				continue
+			}
+
+			failed[session.Name] = err.Error()
+
+			log.Error(ctx, "reload file:", log.String("name", session.Name), log.Err(err))
+
+			if !errors.IsConflict(err) {
+				continue
+			}
+
+			brodcastErr := session.Hub.Brodcast(ctx, app.MsgServerFileConflict, "", "", notSaved(session.Name, err), nil)
+			if brodcastErr != nil {
+				log.Error(ctx, "brodcast:", log.Err(brodcastErr))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *service) reload(ctx context.Context, session *app.Session) error {
+	changes, err := session.Editor.Reload(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	err = brodcastChanges(ctx, session, changes)
+	if err != nil {
+		return err
+	}
+
+	err = session.Hub.Brodcast(ctx, app.MsgServerFileReloaded, "", "", "", nil)
+	if err != nil {
+		return errors.Wrap(err, "brodcast %s", app.MsgServerFileReloaded)
+	}
+
+	return nil
+}
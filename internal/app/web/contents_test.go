@@ -0,0 +1,111 @@
+package web_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/mocks"
+	"github.com/fakovacic/editor/internal/app/web"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchContents(t *testing.T) {
+	type brodcast struct {
+		msgType  app.MsgType
+		clientID string
+		msg      string
+	}
+
+	cases := []struct {
+		it string
+
+		changes   []*app.ChangeMsg
+		reloadErr error
+
+		expectedBrodcasts []brodcast
+	}{
+		{
+			it:                "source unchanged",
+			expectedBrodcasts: []brodcast{},
+		},
+		{
+			it: "source changes merged",
+			changes: []*app.ChangeMsg{
+				{
+					Action:   "insert",
+					Start:    app.ChangeRow{Row: 3},
+					End:      app.ChangeRow{Row: 4},
+					Lines:    []string{"d", ""},
+					Revision: 2,
+				},
+			},
+			expectedBrodcasts: []brodcast{
+				{
+					msgType: app.MsgClientsTextChange,
+					msg:     `{"data":{"action":"insert","start":{"row":3,"column":0},"end":{"row":4,"column":0},"lines":["d",""],"revision":2}}`,
+				},
+				{
+					msgType: app.MsgServerFileReloaded,
+				},
+			},
+		},
+		{
+			it:        "source changes conflict",
+			reloadErr: errors.Wrap(errors.Conflict("merge of https://source.local/files/main.css failed"), "io read"),
+			expectedBrodcasts: []brodcast{
+				{
+					msgType: app.MsgServerFileConflict,
+					msg:     "file 'main.css' changed on source, edits conflict",
+				},
+			},
+		},
+		{
+			it:                "source not readable",
+			reloadErr:         errors.New("io read: permission denied"),
+			expectedBrodcasts: []brodcast{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			brodcasts := make([]brodcast, 0)
+
+			session := &app.Session{
+				Name: "main.css",
+				Editor: &mocks.EditorMock{
+					ReloadFunc: func(_ context.Context) ([]*app.ChangeMsg, error) {
+						return tc.changes, tc.reloadErr
+					},
+				},
+				Hub: &mocks.HubMock{
+					BrodcastFunc: func(_ context.Context, msgType app.MsgType, clientID, _, msg string, _ *app.FileMeta) error {
+						brodcasts = append(brodcasts, brodcast{
+							msgType:  msgType,
+							clientID: clientID,
+							msg:      msg,
+						})
+
+						return nil
+					},
+				},
+			}
+
+			service := web.New(&mocks.HubMock{}, &mocks.WorkspaceMock{
+				SessionsFunc: func(_ context.Context) []*app.Session {
+					return []*app.Session{session}
+				},
+			}, nil, nil)
+
+			// done context stops watching after first check
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			service.WatchContents(ctx, time.Second)
+
+			assert.Equal(t, tc.expectedBrodcasts, brodcasts)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package web
+
+import (
+	"context"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+)
+
+// IncommingMsg exposes message handling of service to tests
+func IncommingMsg(ctx context.Context, s Service, session *app.Session, msgType app.MsgType, message []byte, clientID string) (app.MsgType, string, bool, error) {
+	return s.(*service).IncommingMsg(ctx, session, msgType, message, clientID)
+}
+
+// FileOp exposes file operations of service to tests
+func FileOp(ctx context.Context, s Service, current *app.Session, msgType app.MsgType, message []byte, client *app.Client) (*app.Session, error) {
+	return s.(*service).fileOp(ctx, current, msgType, message, client)
+}
+
+// Autosaved is state of autosaved file kept between checks
+type Autosaved = autosaved
+
+// NewAutosaved returns state of file autosaved at revision and time
+func NewAutosaved(revision int, t time.Time) Autosaved {
+	return autosaved{
+		revision: revision,
+		time:     t,
+	}
+}
+
+// ExpireVotes exposes single expiry check of ready votes to tests
+func ExpireVotes(ctx context.Context, s Service) {
+	s.(*service).expireVotes(ctx)
+}
+
+// AutosaveFiles exposes single autosave check of service to tests
+func AutosaveFiles(ctx context.Context, s Service, autosave app.Autosave, saved map[string]Autosaved, now time.Time) {
+	s.(*service).autosaveFiles(ctx, autosave, saved, now)
+}
@@ -0,0 +1,261 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/fakovacic/editor/internal/log"
+)
+
+func (s *service) Files(ctx context.Context, id string) ([]app.FileInfo, error) {
+	err := s.Index(ctx, id)
+	if err != nil {
+		return nil, errors.UnauthorizedWrap(err, "client")
+	}
+
+	files, err := s.workspace.Files(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "workspace files")
+	}
+
+	return files, nil
+}
+
+// WatchFiles lists workspace files every interval until ctx is done,
+// when files are added or removed list is sent to all clients
+func (s *service) WatchFiles(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var names map[string]bool
+
+	for {
+		files, err := s.workspace.Files(ctx)
+		if err != nil {
+			log.Error(ctx, "workspace files:", log.Err(err))
+		}
+
+		if err == nil && changed(names, files) {
+			if names != nil {
+				err = s.brodcastFiles(ctx, files)
+				if err != nil {
+					log.Error(ctx, "brodcast files:", log.Err(err))
+				}
+			}
+
+			names = make(map[string]bool, len(files))
+			for _, file := range files {
+				names[file.Name] = true
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *service) brodcastFiles(ctx context.Context, files []app.FileInfo) error {
+	msg, err := json.Marshal(files)
+	if err != nil {
+		return errors.Wrap(err, "marshaling files")
+	}
+
+	err = s.users.Brodcast(ctx, app.MsgServerFiles, "", "", string(msg), nil)
+	if err != nil {
+		return errors.Wrap(err, "brodcast %s", app.MsgServerFiles)
+	}
+
+	return nil
+}
+
+// changed reports if files were added or removed, changes of contents are ignored
+func changed(names map[string]bool, files []app.FileInfo) bool {
+	if names == nil || len(names) != len(files) {
+		return true
+	}
+
+	for _, file := range files {
+		if !names[file.Name] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fileOp creates, renames or deletes file and returns session of client
+// after operation, file open by other clients is not renamed or deleted
+func (s *service) fileOp(ctx context.Context, current *app.Session, msgType app.MsgType, message []byte, client *app.Client) (*app.Session, error) {
+	var msg app.WSMsgFileOp
+
+	err := json.Unmarshal(message, &msg)
+	if err != nil {
+		return current, errors.Wrap(err, "unmarshall file operation msg")
+	}
+
+	var (
+		session   = current
+		failedMsg app.MsgType
+	)
+
+	switch msgType {
+	case app.MsgConnCreateFile:
+		failedMsg = app.MsgServerFileNotCreated
+
+		err = s.workspace.Create(ctx, msg.Data.Name)
+		if err == nil {
+			s.refreshFiles(ctx)
+
+			// new file is opened for client who created it
+			session, err = s.switchFile(ctx, current, msg.Data.Name, client)
+		}
+	case app.MsgConnRenameFile:
+		failedMsg = app.MsgServerFileNotRenamed
+
+		session, err = s.renameFile(ctx, current, msg.Data, client)
+	case app.MsgConnDeleteFile:
+		failedMsg = app.MsgServerFileNotDeleted
+
+		session, err = s.deleteFile(ctx, current, msg.Data, client)
+	default:
+		return current, errors.BadRequest("invalid file operation '%s'", msgType)
+	}
+
+	if err != nil {
+		brodcastErr := s.users.Brodcast(ctx, failedMsg, client.ID, client.Username, fileOpFailed(msg.Data.Name, err), nil)
+		if brodcastErr != nil {
+			log.Error(ctx, "brodcast:", log.Err(brodcastErr))
+		}
+
+		return session, errors.Wrap(err, "%s", msgType)
+	}
+
+	return session, nil
+}
+
+// fileOpFailed returns reason file operation failed safe to send to clients,
+// errors of workspace and io can tell about source so they are only logged
+func fileOpFailed(name string, err error) string {
+	switch {
+	case errors.IsNotFound(err):
+		return fmt.Sprintf("file '%s' not found", name)
+	case errors.IsConflict(err):
+		return fmt.Sprintf("file '%s' changed on source", name)
+	case errors.IsBadRequest(err):
+		return fmt.Sprintf("file '%s' not valid, already exist or open by other clients", name)
+	default:
+		return fmt.Sprintf("file '%s' failed", name)
+	}
+}
+
+// renameFile renames file, client who has file open as only client
+// leaves it so edits are written and joins it again under new name
+func (s *service) renameFile(ctx context.Context, current *app.Session, op app.FileOp, client *app.Client) (*app.Session, error) {
+	open, err := s.release(ctx, current, op.Name, client)
+	if err != nil {
+		return current, err
+	}
+
+	session := current
+	name := op.Name
+
+	if open {
+		session = nil
+	}
+
+	err = s.workspace.Rename(ctx, op.Name, op.NewName)
+	if err == nil {
+		s.refreshFiles(ctx)
+
+		name = op.NewName
+	}
+
+	if !open {
+		return session, err
+	}
+
+	session, joinErr := s.switchFile(ctx, nil, name, client)
+	if joinErr != nil {
+		log.Error(ctx, "switch file:", log.Err(joinErr))
+	}
+
+	return session, err
+}
+
+// deleteFile deletes file, client who has file open as only client leaves it
+func (s *service) deleteFile(ctx context.Context, current *app.Session, op app.FileOp, client *app.Client) (*app.Session, error) {
+	open, err := s.release(ctx, current, op.Name, client)
+	if err != nil {
+		return current, err
+	}
+
+	err = s.workspace.Delete(ctx, op.Name)
+	if err != nil {
+		if !open {
+			return current, err
+		}
+
+		session, joinErr := s.switchFile(ctx, nil, op.Name, client)
+		if joinErr != nil {
+			log.Error(ctx, "switch file:", log.Err(joinErr))
+		}
+
+		return session, err
+	}
+
+	s.refreshFiles(ctx)
+
+	if !open {
+		return current, nil
+	}
+
+	// client has no file open
+	err = s.users.Brodcast(ctx, app.MsgServerFileNotOpened, client.ID, client.Username, "", nil)
+	if err != nil {
+		log.Error(ctx, "brodcast:", log.Err(err))
+	}
+
+	return nil, nil
+}
+
+// release leaves current session if it is session of file, file can be
+// released only when client is its only editor, returns if file was open
+func (s *service) release(ctx context.Context, current *app.Session, name string, client *app.Client) (bool, error) {
+	if current == nil || current.Name != path.Clean(name) {
+		return false, nil
+	}
+
+	if current.Hub.CountRegistered() > 1 {
+		return false, errors.BadRequest("file '%s' is open by other clients", current.Name)
+	}
+
+	err := s.leave(ctx, current, client)
+	if err != nil {
+		return false, errors.Wrap(err, "leave")
+	}
+
+	return true, nil
+}
+
+// refreshFiles sends files to all clients without waiting for next poll
+func (s *service) refreshFiles(ctx context.Context) {
+	files, err := s.workspace.Files(ctx)
+	if err != nil {
+		log.Error(ctx, "workspace files:", log.Err(err))
+
+		return
+	}
+
+	err = s.brodcastFiles(ctx, files)
+	if err != nil {
+		log.Error(ctx, "brodcast files:", log.Err(err))
+	}
+}
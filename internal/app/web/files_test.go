@@ -0,0 +1,113 @@
+package web_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/mocks"
+	"github.com/fakovacic/editor/internal/app/web"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileOpFailed(t *testing.T) {
+	type brodcast struct {
+		msgType  app.MsgType
+		clientID string
+		msg      string
+	}
+
+	cases := []struct {
+		it string
+
+		msgType app.MsgType
+		message string
+		opErr   error
+
+		expectedBrodcast brodcast
+		expectedError    string
+	}{
+		{
+			it:      "create of existing file",
+			msgType: app.MsgConnCreateFile,
+			message: `{"type":"conn-create-file","data":{"name":"main.css"}}`,
+			opErr:   errors.BadRequest("file 'main.css' already exist"),
+
+			expectedBrodcast: brodcast{
+				msgType:  app.MsgServerFileNotCreated,
+				clientID: "mock-id",
+				msg:      "file 'main.css' not valid, already exist or open by other clients",
+			},
+			expectedError: "conn-create-file: file 'main.css' already exist",
+		},
+		{
+			it:      "rename of missing file",
+			msgType: app.MsgConnRenameFile,
+			message: `{"type":"conn-rename-file","data":{"name":"main.css","newName":"site.css"}}`,
+			opErr:   errors.NotFound("file 'main.css' not found"),
+
+			expectedBrodcast: brodcast{
+				msgType:  app.MsgServerFileNotRenamed,
+				clientID: "mock-id",
+				msg:      "file 'main.css' not found",
+			},
+			expectedError: "conn-rename-file: file 'main.css' not found",
+		},
+		{
+			it:      "delete failed on source",
+			msgType: app.MsgConnDeleteFile,
+			message: `{"type":"conn-delete-file","data":{"name":"main.css"}}`,
+			opErr:   errors.New("io delete: DELETE https://source.local/files/main.css: 500"),
+
+			expectedBrodcast: brodcast{
+				msgType:  app.MsgServerFileNotDeleted,
+				clientID: "mock-id",
+				msg:      "file 'main.css' failed",
+			},
+			expectedError: "conn-delete-file: io delete: DELETE https://source.local/files/main.css: 500",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			brodcasts := make([]brodcast, 0)
+
+			users := &mocks.HubMock{
+				BrodcastFunc: func(_ context.Context, msgType app.MsgType, clientID, _, msg string, _ *app.FileMeta) error {
+					brodcasts = append(brodcasts, brodcast{
+						msgType:  msgType,
+						clientID: clientID,
+						msg:      msg,
+					})
+
+					return nil
+				},
+			}
+
+			workspace := &mocks.WorkspaceMock{
+				CreateFunc: func(_ context.Context, _ string) error {
+					return tc.opErr
+				},
+				RenameFunc: func(_ context.Context, _, _ string) error {
+					return tc.opErr
+				},
+				DeleteFunc: func(_ context.Context, _ string) error {
+					return tc.opErr
+				},
+			}
+
+			service := web.New(users, workspace, nil, nil)
+
+			_, err := web.FileOp(context.Background(), service, nil, tc.msgType, []byte(tc.message), &app.Client{
+				ID:       "mock-id",
+				Username: "mock-username",
+			})
+			if assert.NotNil(t, err) {
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+
+			assert.Equal(t, []brodcast{tc.expectedBrodcast}, brodcasts)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	goErrors "errors"
+	"net/http"
+
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/fakovacic/editor/internal/log"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func (h *Handler) Files() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		files, err := h.service.Files(c.Context(), c.Query("id"))
+		if err != nil {
+			return errorResponse(c, err)
+		}
+
+		return c.JSON(files)
+	}
+}
+
+// errorResponse logs error and returns it with http status of service error
+func errorResponse(c *fiber.Ctx, err error) error {
+	log.Error(c.Context(), err.Error())
+
+	return c.Status(statusCode(err)).JSON(ErrorResponse{
+		Error: err.Error(),
+	})
+}
+
+// statusCode returns http status of service error
+func statusCode(err error) int {
+	var e errors.Error
+
+	if goErrors.As(err, &e) {
+		return e.HTTPStatusCode()
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) Versions() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		versions, err := h.service.Versions(c.Context(), c.Query("id"), c.Query("file"))
+		if err != nil {
+			return errorResponse(c, err)
+		}
+
+		return c.JSON(versions)
+	}
+}
+
+// Version returns contents of version as they were saved
+func (h *Handler) Version() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		contents, err := h.service.Version(c.Context(), c.Query("id"), c.Query("file"), c.Params("version"))
+		if err != nil {
+			return errorResponse(c, err)
+		}
+
+		c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+
+		return c.SendString(contents)
+	}
+}
+
+// VersionDiff returns unified diff from version to version in to query
+func (h *Handler) VersionDiff() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		diff, err := h.service.VersionDiff(c.Context(), c.Query("id"), c.Query("file"), c.Params("version"), c.Query("to"))
+		if err != nil {
+			return errorResponse(c, err)
+		}
+
+		c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+
+		return c.SendString(diff)
+	}
+}
+
+func (h *Handler) RestoreVersion() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		err := h.service.RestoreVersion(c.Context(), c.Query("id"), c.Query("file"), c.Params("version"))
+		if err != nil {
+			return errorResponse(c, err)
+		}
+
+		return c.SendStatus(http.StatusNoContent)
+	}
+}
@@ -27,7 +27,7 @@ func (h *Handler) WS() fiber.Handler {
 			return
 		}
 
-		err := h.service.Connection(ctx, id, c)
+		err := h.service.Connection(ctx, id, c.Query("file"), c)
 		if err != nil {
 			log.Error(ctx, "ws connection", log.Err(err))
 
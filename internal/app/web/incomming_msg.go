@@ -3,27 +3,40 @@ package web
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
 	"github.com/fakovacic/editor/internal/app"
 	"github.com/fakovacic/editor/internal/errors"
 )
 
-func (s *service) IncommingMsg(ctx context.Context, msgType app.MsgType, message []byte, clientID string) (app.MsgType, string, bool, error) {
+func (s *service) IncommingMsg(ctx context.Context, session *app.Session, msgType app.MsgType, message []byte, clientID string) (app.MsgType, string, bool, error) {
+	if session == nil && msgType != app.MsgConnDisconnect {
+		return app.MsgNil, "", false, errors.BadRequest("file not opened")
+	}
+
+	// edits applied after files were saved on shutdown would be lost,
+	// connection of client still editing is closed instead
+	if s.closing.Load() && (msgType == app.MsgConnTextChange || msgType == app.MsgConnVersionRestore) {
+		return app.MsgNil, "", true, errors.BadRequest("server shutting down, %s rejected", msgType)
+	}
+
 	switch msgType {
 	case app.MsgConnDisconnect:
 		return app.MsgNil, "", true, nil
 	case app.MsgConnSave:
-		ok := s.writeValidator.IsReady(ctx)
+		ok := session.WriteValidator.IsReady(ctx)
 		if !ok {
 			return app.MsgServerFileNotReady, "", false, errors.New("validator not ready")
 		}
 
-		err := s.editor.Write(ctx)
+		var msg app.WSMsgSave
+
+		err := json.Unmarshal(message, &msg)
 		if err != nil {
-			return app.MsgServerFileNotSaved, "", false, errors.Wrap(err, "editor write")
+			return app.MsgNil, "", true, errors.Wrap(err, "unmarshall save msg")
 		}
 
-		return app.MsgServerFileSaved, "", false, nil
+		return s.write(ctx, session, app.SaveManual, msg.Data.Message)
 	case app.MsgConnTextChange:
 		var msg app.WSMsgTextChange
 
@@ -32,12 +45,35 @@ func (s *service) IncommingMsg(ctx context.Context, msgType app.MsgType, message
 			return app.MsgNil, "", true, errors.Wrap(err, "unmarshall text change msg")
 		}
 
-		err = s.editor.Change(ctx, &msg.Data)
+		change, err := session.Editor.Change(ctx, &msg.Data)
 		if err != nil {
-			return app.MsgServerFileNotSaved, "", false, errors.Wrap(err, "editor write")
+			if !errors.IsBadRequest(err) {
+				return app.MsgServerFileNotSaved, "", false, errors.Wrap(err, "editor change")
+			}
+
+			// client contents diverged, send whole document so it can continue
+			resyncErr := s.resync(ctx, session, clientID)
+			if resyncErr != nil {
+				return app.MsgNil, "", false, errors.Wrap(resyncErr, "resync")
+			}
+
+			return app.MsgNil, "", false, errors.Wrap(err, "editor change")
 		}
 
-		return app.MsgClientsTextChange, string(message), false, nil
+		changeMsg, err := json.Marshal(app.WSMsgTextChange{
+			Data: *change,
+		})
+		if err != nil {
+			return app.MsgNil, "", false, errors.Wrap(err, "marshal text change msg")
+		}
+
+		// sender needs revision of its change before sending next one
+		err = session.Hub.Brodcast(ctx, app.MsgServerTextChangeAck, clientID, "", string(changeMsg), nil)
+		if err != nil {
+			return app.MsgNil, "", false, errors.Wrap(err, "brodcast %s", app.MsgServerTextChangeAck)
+		}
+
+		return app.MsgClientsTextChange, string(changeMsg), false, nil
 	case app.MsgConnCursorChange:
 		var msg app.WSMsgCursorChange
 
@@ -46,45 +82,134 @@ func (s *service) IncommingMsg(ctx context.Context, msgType app.MsgType, message
 			return app.MsgNil, "", true, errors.Wrap(err, "unmarshall cursor change msg")
 		}
 
-		s.hub.SetPosition(clientID, app.Position{
+		session.Hub.SetPosition(clientID, app.Position{
 			Index:  msg.Data.Index,
 			Length: msg.Data.Length,
 		})
 
 		return app.MsgClientsCursorChange, "", false, nil
 	case app.MsgConnReady:
-		err := s.writeValidator.ReadyClient(ctx, clientID)
+		err := session.WriteValidator.ReadyClient(ctx, clientID)
 		if err != nil {
 			return app.MsgConnNotReady, "", false, errors.Wrap(err, "validator ready client")
 		}
 
-		s.hub.SetReady(clientID, true)
+		session.Hub.SetReady(clientID, true)
 
-		// all clients are ready, save file
-		ok := s.writeValidator.IsReady(ctx)
+		// save approved by ready votes, save file
+		ok := session.WriteValidator.IsReady(ctx)
 		if ok {
-			err = s.editor.Write(ctx)
-			if err != nil {
-				return app.MsgServerFileNotSaved, "", false, errors.Wrap(err, "editor write")
+			msgType, msg, closeConn, writeErr := s.write(ctx, session, app.SaveManual, "")
+			if writeErr != nil {
+				return msgType, msg, closeConn, writeErr
 			}
 
-			s.writeValidator.Clear(ctx)
-			s.hub.SetReadyAll(false)
+			session.WriteValidator.Clear(ctx)
+			session.Hub.SetReadyAll(false)
 
-			return app.MsgServerFileSaved, "", false, nil
+			return msgType, msg, closeConn, nil
 		}
 
 		return app.MsgClientsReady, "", false, nil
 	case app.MsgConnUnready:
-		err := s.writeValidator.UnreadyClient(ctx, clientID)
+		err := session.WriteValidator.UnreadyClient(ctx, clientID)
 		if err != nil {
 			return app.MsgConnNotUnready, "", false, errors.Wrap(err, "validator unready client")
 		}
 
-		s.hub.SetReady(clientID, false)
+		session.Hub.SetReady(clientID, false)
 
 		return app.MsgClientsUnready, "", false, nil
+	case app.MsgConnVersions, app.MsgConnVersion, app.MsgConnVersionDiff, app.MsgConnVersionRestore:
+		return s.versionMsg(ctx, session, msgType, message, clientID)
 	default:
 		return app.MsgNil, "", true, errors.New("unknown message type")
 	}
 }
+
+// write saves file, source changes merged on save are sent to all
+// clients as changes none of them made, clients get reason if not saved
+func (s *service) write(ctx context.Context, session *app.Session, trigger app.SaveTrigger, message string) (app.MsgType, string, bool, error) {
+	// versioning and io as git record clients editing file as authors
+	ctx = app.WithSaveInfo(ctx, app.SaveInfo{
+		Authors: session.Hub.Usernames(),
+		Ready:   session.Hub.Ready(),
+		Trigger: trigger,
+		Message: message,
+	})
+
+	changes, err := session.Editor.Write(ctx)
+	if err != nil {
+		return app.MsgServerFileNotSaved, notSaved(session.Name, err), false, errors.Wrap(err, "editor write")
+	}
+
+	if len(changes) == 0 {
+		return app.MsgServerFileSaved, "", false, nil
+	}
+
+	err = brodcastChanges(ctx, session, changes)
+	if err != nil {
+		return app.MsgNil, "", false, err
+	}
+
+	return app.MsgServerFileMerged, "", false, nil
+}
+
+// notSaved returns reason file is not saved safe to send to clients,
+// errors of io or versioning can tell about source so they are only logged
+func notSaved(name string, err error) string {
+	if errors.IsConflict(err) {
+		return fmt.Sprintf("file '%s' changed on source, edits conflict", name)
+	}
+
+	return fmt.Sprintf("file '%s' not saved", name)
+}
+
+// brodcastChanges sends changes made on server to all clients of session
+func brodcastChanges(ctx context.Context, session *app.Session, changes []*app.ChangeMsg) error {
+	for _, change := range changes {
+		changeMsg, err := json.Marshal(app.WSMsgTextChange{
+			Data: *change,
+		})
+		if err != nil {
+			return errors.Wrap(err, "marshal text change msg")
+		}
+
+		err = session.Hub.Brodcast(ctx, app.MsgClientsTextChange, "", "", string(changeMsg), nil)
+		if err != nil {
+			return errors.Wrap(err, "brodcast %s", app.MsgClientsTextChange)
+		}
+	}
+
+	return nil
+}
+
+// resync sends current contents and revision to client,
+// editor state is sent too if editor mode needs it
+func (s *service) resync(ctx context.Context, session *app.Session, clientID string) error {
+	contents, meta, err := session.Editor.Read(ctx)
+	if err != nil {
+		return errors.Wrap(err, "editor read content")
+	}
+
+	err = session.Hub.Brodcast(ctx, app.MsgServerResync, clientID, "", contents, meta)
+	if err != nil {
+		return errors.Wrap(err, "brodcast %s", app.MsgServerResync)
+	}
+
+	state, err := session.Editor.State(ctx)
+	if err != nil {
+		return errors.Wrap(err, "editor state")
+	}
+
+	if state == "" {
+		return nil
+	}
+
+	err = session.Hub.Brodcast(ctx, app.MsgServerCRDTState, clientID, "", state, nil)
+	if err != nil {
+		return errors.Wrap(err, "brodcast %s", app.MsgServerCRDTState)
+	}
+
+	return nil
+}
@@ -0,0 +1,315 @@
+package web_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/mocks"
+	"github.com/fakovacic/editor/internal/app/web"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncommingMsgTextChange(t *testing.T) {
+	type brodcast struct {
+		msgType  app.MsgType
+		clientID string
+		msg      string
+	}
+
+	cases := []struct {
+		it string
+
+		state     string
+		changeErr error
+
+		expectedMsgType   app.MsgType
+		expectedMsg       string
+		expectedBrodcasts []brodcast
+		expectedError     string
+	}{
+		{
+			it: "change applied",
+
+			expectedMsgType: app.MsgClientsTextChange,
+			expectedMsg:     `{"data":{"action":"insert","start":{"row":0,"column":0},"end":{"row":0,"column":1},"lines":["a"],"revision":1}}`,
+			expectedBrodcasts: []brodcast{
+				{
+					msgType:  app.MsgServerTextChangeAck,
+					clientID: "mock-id",
+					msg:      `{"data":{"action":"insert","start":{"row":0,"column":0},"end":{"row":0,"column":1},"lines":["a"],"revision":1}}`,
+				},
+			},
+		},
+		{
+			it: "invalid change resync",
+
+			changeErr: errors.BadRequest("row 5 out of range"),
+
+			expectedMsgType: app.MsgNil,
+			expectedBrodcasts: []brodcast{
+				{
+					msgType:  app.MsgServerResync,
+					clientID: "mock-id",
+					msg:      "mock-contents",
+				},
+			},
+			expectedError: "editor change: row 5 out of range",
+		},
+		{
+			it: "invalid change resync with state",
+
+			state:     "mock-state",
+			changeErr: errors.BadRequest("crdt ops empty"),
+
+			expectedMsgType: app.MsgNil,
+			expectedBrodcasts: []brodcast{
+				{
+					msgType:  app.MsgServerResync,
+					clientID: "mock-id",
+					msg:      "mock-contents",
+				},
+				{
+					msgType:  app.MsgServerCRDTState,
+					clientID: "mock-id",
+					msg:      "mock-state",
+				},
+			},
+			expectedError: "editor change: crdt ops empty",
+		},
+		{
+			it: "change failed",
+
+			changeErr: errors.New("contents not loaded"),
+
+			expectedMsgType:   app.MsgServerFileNotSaved,
+			expectedBrodcasts: []brodcast{},
+			expectedError:     "editor change: contents not loaded",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			editorMock := &mocks.EditorMock{
+				ChangeFunc: func(_ context.Context, msg *app.ChangeMsg) (*app.ChangeMsg, error) {
+					if tc.changeErr != nil {
+						return nil, tc.changeErr
+					}
+
+					change := *msg
+					change.Revision = 1
+
+					return &change, nil
+				},
+				ReadFunc: func(_ context.Context) (string, *app.FileMeta, error) {
+					return "mock-contents", &app.FileMeta{Revision: 1}, nil
+				},
+				StateFunc: func(_ context.Context) (string, error) {
+					return tc.state, nil
+				},
+			}
+
+			brodcasts := make([]brodcast, 0)
+
+			hubMock := &mocks.HubMock{
+				BrodcastFunc: func(_ context.Context, msgType app.MsgType, clientID, _, msg string, _ *app.FileMeta) error {
+					brodcasts = append(brodcasts, brodcast{
+						msgType:  msgType,
+						clientID: clientID,
+						msg:      msg,
+					})
+
+					return nil
+				},
+			}
+
+			session := &app.Session{
+				Name:           "mock-name",
+				Editor:         editorMock,
+				Hub:            hubMock,
+				WriteValidator: &mocks.WriteValidatorMock{},
+			}
+
+			service := web.New(&mocks.HubMock{}, &mocks.WorkspaceMock{}, nil, nil)
+
+			msgType, msg, closeConn, err := web.IncommingMsg(
+				context.Background(),
+				service,
+				session,
+				app.MsgConnTextChange,
+				[]byte(`{"type":"conn-text-change","data":{"action":"insert","start":{"row":0,"column":0},"end":{"row":0,"column":1},"lines":["a"],"revision":0}}`),
+				"mock-id",
+			)
+			if err != nil {
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+
+			assert.False(t, closeConn)
+			assert.Equal(t, tc.expectedMsgType, msgType)
+			assert.Equal(t, tc.expectedMsg, msg)
+			assert.Equal(t, tc.expectedBrodcasts, brodcasts)
+		})
+	}
+}
+
+func TestIncommingMsgFileNotOpened(t *testing.T) {
+	service := web.New(&mocks.HubMock{}, &mocks.WorkspaceMock{}, nil, nil)
+
+	msgType, _, closeConn, err := web.IncommingMsg(
+		context.Background(),
+		service,
+		nil,
+		app.MsgConnSave,
+		[]byte(`{"type":"conn-save"}`),
+		"mock-id",
+	)
+
+	assert.Equal(t, app.MsgNil, msgType)
+	assert.False(t, closeConn)
+	assert.True(t, errors.IsBadRequest(err))
+
+	_, _, closeConn, err = web.IncommingMsg(
+		context.Background(),
+		service,
+		nil,
+		app.MsgConnDisconnect,
+		[]byte(`{"type":"conn-disconnect"}`),
+		"mock-id",
+	)
+
+	assert.True(t, closeConn)
+	assert.Nil(t, err)
+}
+
+func TestIncommingMsgSave(t *testing.T) {
+	type brodcast struct {
+		msgType  app.MsgType
+		clientID string
+		msg      string
+	}
+
+	cases := []struct {
+		it string
+
+		changes  []*app.ChangeMsg
+		writeErr error
+
+		expectedMsgType   app.MsgType
+		expectedMsg       string
+		expectedBrodcasts []brodcast
+		expectedError     string
+	}{
+		{
+			it: "saved",
+
+			expectedMsgType:   app.MsgServerFileSaved,
+			expectedBrodcasts: []brodcast{},
+		},
+		{
+			it: "source changes merged",
+
+			changes: []*app.ChangeMsg{
+				{
+					Action:   "insert",
+					Start:    app.ChangeRow{Row: 3},
+					End:      app.ChangeRow{Row: 4},
+					Lines:    []string{"d", ""},
+					Revision: 2,
+				},
+			},
+
+			expectedMsgType: app.MsgServerFileMerged,
+			expectedBrodcasts: []brodcast{
+				{
+					msgType: app.MsgClientsTextChange,
+					msg:     `{"data":{"action":"insert","start":{"row":3,"column":0},"end":{"row":4,"column":0},"lines":["d",""],"revision":2}}`,
+				},
+			},
+		},
+		{
+			it: "source changes conflict",
+
+			writeErr: errors.Conflict("file 'main.css' changed on source, edits conflict"),
+
+			expectedMsgType:   app.MsgServerFileNotSaved,
+			expectedMsg:       "file 'mock-name' changed on source, edits conflict",
+			expectedBrodcasts: []brodcast{},
+			expectedError:     "editor write: file 'main.css' changed on source, edits conflict",
+		},
+		{
+			it: "write failed",
+
+			writeErr: errors.New("io write: permission denied"),
+
+			expectedMsgType:   app.MsgServerFileNotSaved,
+			expectedMsg:       "file 'mock-name' not saved",
+			expectedBrodcasts: []brodcast{},
+			expectedError:     "editor write: io write: permission denied",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			brodcasts := make([]brodcast, 0)
+
+			session := &app.Session{
+				Name: "mock-name",
+				Editor: &mocks.EditorMock{
+					WriteFunc: func(ctx context.Context) ([]*app.ChangeMsg, error) {
+						// clients of session are authors of write
+						assert.Equal(t, app.SaveInfo{
+							Authors: []string{"mock-username", "other-username"},
+							Ready:   []string{"other-username"},
+							Trigger: app.SaveManual,
+							Message: "mock-message",
+						}, app.GetSaveInfo(ctx))
+
+						return tc.changes, tc.writeErr
+					},
+				},
+				Hub: &mocks.HubMock{
+					UsernamesFunc: func() []string {
+						return []string{"mock-username", "other-username"}
+					},
+					ReadyFunc: func() []string {
+						return []string{"other-username"}
+					},
+					BrodcastFunc: func(_ context.Context, msgType app.MsgType, clientID, _, msg string, _ *app.FileMeta) error {
+						brodcasts = append(brodcasts, brodcast{
+							msgType:  msgType,
+							clientID: clientID,
+							msg:      msg,
+						})
+
+						return nil
+					},
+				},
+				WriteValidator: &mocks.WriteValidatorMock{
+					IsReadyFunc: func(_ context.Context) bool {
+						return true
+					},
+				},
+			}
+
+			service := web.New(&mocks.HubMock{}, &mocks.WorkspaceMock{}, nil, nil)
+
+			msgType, msg, closeConn, err := web.IncommingMsg(
+				context.Background(),
+				service,
+				session,
+				app.MsgConnSave,
+				[]byte(`{"type":"conn-save","data":{"message":"mock-message"}}`),
+				"mock-id",
+			)
+			if err != nil {
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+
+			assert.False(t, closeConn)
+			assert.Equal(t, tc.expectedMsgType, msgType)
+			assert.Equal(t, tc.expectedMsg, msg)
+			assert.Equal(t, tc.expectedBrodcasts, brodcasts)
+		})
+	}
+}
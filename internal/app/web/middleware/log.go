@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"context"
+	"time"
 
+	"github.com/fakovacic/editor/internal/app"
 	"github.com/fakovacic/editor/internal/app/web"
 	"github.com/fakovacic/editor/internal/log"
 	"github.com/gofiber/contrib/websocket"
@@ -63,16 +65,17 @@ func (m *logMiddleware) Index(ctx context.Context, id string) error {
 	return err
 }
 
-func (m *logMiddleware) Connection(ctx context.Context, id string, c *websocket.Conn) error {
+func (m *logMiddleware) Connection(ctx context.Context, id, file string, c *websocket.Conn) error {
 	log.Info(ctx, "service-request",
 		log.String("service", m.service),
 		log.String("method", "Connection"),
 		log.String("layer", "service"),
 		log.Any("req", map[string]any{
-			"id": id,
+			"id":   id,
+			"file": file,
 		}))
 
-	err := m.next.Connection(ctx, id, c)
+	err := m.next.Connection(ctx, id, file, c)
 
 	log.Info(ctx, "service-response",
 		log.String("service", m.service),
@@ -82,3 +85,201 @@ func (m *logMiddleware) Connection(ctx context.Context, id string, c *websocket.
 
 	return err
 }
+
+func (m *logMiddleware) Files(ctx context.Context, id string) ([]app.FileInfo, error) {
+	log.Info(ctx, "service-request",
+		log.String("service", m.service),
+		log.String("method", "Files"),
+		log.String("layer", "service"),
+		log.Any("req", map[string]any{
+			"id": id,
+		}))
+
+	files, err := m.next.Files(ctx, id)
+
+	log.Info(ctx, "service-response",
+		log.String("service", m.service),
+		log.String("method", "Files"),
+		log.String("layer", "service"),
+		log.Any("res", map[string]any{
+			"files": len(files),
+		}),
+		log.Err(err))
+
+	return files, err
+}
+
+func (m *logMiddleware) WatchFiles(ctx context.Context, interval time.Duration) {
+	log.Info(ctx, "service-request",
+		log.String("service", m.service),
+		log.String("method", "WatchFiles"),
+		log.String("layer", "service"),
+		log.Any("req", map[string]any{
+			"interval": interval.String(),
+		}))
+
+	m.next.WatchFiles(ctx, interval)
+
+	log.Info(ctx, "service-response",
+		log.String("service", m.service),
+		log.String("method", "WatchFiles"),
+		log.String("layer", "service"))
+}
+
+func (m *logMiddleware) WatchContents(ctx context.Context, interval time.Duration) {
+	log.Info(ctx, "service-request",
+		log.String("service", m.service),
+		log.String("method", "WatchContents"),
+		log.String("layer", "service"),
+		log.Any("req", map[string]any{
+			"interval": interval.String(),
+		}))
+
+	m.next.WatchContents(ctx, interval)
+
+	log.Info(ctx, "service-response",
+		log.String("service", m.service),
+		log.String("method", "WatchContents"),
+		log.String("layer", "service"))
+}
+
+func (m *logMiddleware) Autosave(ctx context.Context, autosave app.Autosave) {
+	log.Info(ctx, "service-request",
+		log.String("service", m.service),
+		log.String("method", "Autosave"),
+		log.String("layer", "service"),
+		log.Any("req", map[string]any{
+			"interval": autosave.Interval.String(),
+			"changes":  autosave.Changes,
+			"target":   autosave.Target,
+		}))
+
+	m.next.Autosave(ctx, autosave)
+
+	log.Info(ctx, "service-response",
+		log.String("service", m.service),
+		log.String("method", "Autosave"),
+		log.String("layer", "service"))
+}
+
+func (m *logMiddleware) ExpireVotes(ctx context.Context) {
+	log.Info(ctx, "service-request",
+		log.String("service", m.service),
+		log.String("method", "ExpireVotes"),
+		log.String("layer", "service"))
+
+	m.next.ExpireVotes(ctx)
+
+	log.Info(ctx, "service-response",
+		log.String("service", m.service),
+		log.String("method", "ExpireVotes"),
+		log.String("layer", "service"))
+}
+
+func (m *logMiddleware) Versions(ctx context.Context, id, file string) ([]app.Version, error) {
+	log.Info(ctx, "service-request",
+		log.String("service", m.service),
+		log.String("method", "Versions"),
+		log.String("layer", "service"),
+		log.Any("req", map[string]any{
+			"id":   id,
+			"file": file,
+		}))
+
+	versions, err := m.next.Versions(ctx, id, file)
+
+	log.Info(ctx, "service-response",
+		log.String("service", m.service),
+		log.String("method", "Versions"),
+		log.String("layer", "service"),
+		log.Any("res", map[string]any{
+			"versions": len(versions),
+		}),
+		log.Err(err))
+
+	return versions, err
+}
+
+func (m *logMiddleware) Version(ctx context.Context, id, file, version string) (string, error) {
+	log.Info(ctx, "service-request",
+		log.String("service", m.service),
+		log.String("method", "Version"),
+		log.String("layer", "service"),
+		log.Any("req", map[string]any{
+			"id":      id,
+			"file":    file,
+			"version": version,
+		}))
+
+	contents, err := m.next.Version(ctx, id, file, version)
+
+	log.Info(ctx, "service-response",
+		log.String("service", m.service),
+		log.String("method", "Version"),
+		log.String("layer", "service"),
+		log.Err(err))
+
+	return contents, err
+}
+
+func (m *logMiddleware) VersionDiff(ctx context.Context, id, file, from, to string) (string, error) {
+	log.Info(ctx, "service-request",
+		log.String("service", m.service),
+		log.String("method", "VersionDiff"),
+		log.String("layer", "service"),
+		log.Any("req", map[string]any{
+			"id":   id,
+			"file": file,
+			"from": from,
+			"to":   to,
+		}))
+
+	diff, err := m.next.VersionDiff(ctx, id, file, from, to)
+
+	log.Info(ctx, "service-response",
+		log.String("service", m.service),
+		log.String("method", "VersionDiff"),
+		log.String("layer", "service"),
+		log.Err(err))
+
+	return diff, err
+}
+
+func (m *logMiddleware) RestoreVersion(ctx context.Context, id, file, version string) error {
+	log.Info(ctx, "service-request",
+		log.String("service", m.service),
+		log.String("method", "RestoreVersion"),
+		log.String("layer", "service"),
+		log.Any("req", map[string]any{
+			"id":      id,
+			"file":    file,
+			"version": version,
+		}))
+
+	err := m.next.RestoreVersion(ctx, id, file, version)
+
+	log.Info(ctx, "service-response",
+		log.String("service", m.service),
+		log.String("method", "RestoreVersion"),
+		log.String("layer", "service"),
+		log.Err(err))
+
+	return err
+}
+
+func (m *logMiddleware) Shutdown(ctx context.Context) error {
+	log.Info(ctx, "service-request",
+		log.String("service", m.service),
+		log.String("method", "Shutdown"),
+		log.String("layer", "service"))
+
+	err := m.next.Shutdown(ctx)
+
+	log.Info(ctx, "service-response",
+		log.String("service", m.service),
+		log.String("method", "Shutdown"),
+		log.String("layer", "service"),
+		log.Err(err))
+
+	return err
+}
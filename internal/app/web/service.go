@@ -2,6 +2,7 @@ package web
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/fakovacic/editor/internal/app"
@@ -13,27 +14,49 @@ import (
 type Service interface {
 	Login(context.Context, string) (string, error)
 	Index(context.Context, string) error
-	Connection(context.Context, string, *websocket.Conn) error
+	Connection(context.Context, string, string, *websocket.Conn) error
+	Files(context.Context, string) ([]app.FileInfo, error)
+	WatchFiles(context.Context, time.Duration)
+	WatchContents(context.Context, time.Duration)
+	Autosave(context.Context, app.Autosave)
+	ExpireVotes(context.Context)
+
+	Versions(context.Context, string, string) ([]app.Version, error)
+	Version(context.Context, string, string, string) (string, error)
+	VersionDiff(context.Context, string, string, string, string) (string, error)
+	RestoreVersion(context.Context, string, string, string) error
+
+	Shutdown(context.Context) error
 }
 
-func New(editor app.Editor, hub app.Hub, writeValidator app.WriteValidator, connTTL *time.Duration) Service {
+// New returns web service, users hub keeps logged in clients,
+// clients editing same file are in hub of its workspace session,
+// versions of files are browsed and restored if versioning is set
+func New(users app.Hub, workspace app.Workspace, versioning app.Versioning, connTTL *time.Duration) Service {
 	return &service{
-		editor:         editor,
-		hub:            hub,
-		writeValidator: writeValidator,
-		connTTL:        connTTL,
+		users:      users,
+		workspace:  workspace,
+		versioning: versioning,
+		connTTL:    connTTL,
 	}
 }
 
 type service struct {
-	editor         app.Editor
-	hub            app.Hub
-	writeValidator app.WriteValidator
-	connTTL        *time.Duration
+	users      app.Hub
+	workspace  app.Workspace
+	versioning app.Versioning
+	connTTL    *time.Duration
+
+	// closing is set on shutdown, new connections are rejected
+	closing atomic.Bool
 }
 
 func (s *service) Login(_ context.Context, username string) (string, error) {
-	ok := s.hub.GetByUsername(username)
+	if s.closing.Load() {
+		return "", errors.New("server shutting down")
+	}
+
+	ok := s.users.GetByUsername(username)
 	if ok {
 		return "", errors.New("username already exist")
 	}
@@ -43,7 +66,7 @@ func (s *service) Login(_ context.Context, username string) (string, error) {
 		Username: username,
 	}
 
-	s.hub.Create(client)
+	s.users.Create(client)
 
 	return client.ID, nil
 }
@@ -54,7 +77,7 @@ func (s *service) Index(_ context.Context, id string) error {
 		return errors.New("id not valid")
 	}
 
-	_, ok := s.hub.Get(id)
+	_, ok := s.users.Get(id)
 	if !ok {
 		return errors.New("id not exist")
 	}
@@ -0,0 +1,54 @@
+package web
+
+import (
+	"context"
+	"strings"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/fakovacic/editor/internal/log"
+)
+
+// Shutdown rejects new connections, tells clients server is shutting down
+// and writes files open by clients, clients of each file are told if it
+// was saved. Files are written until ctx is done.
+func (s *service) Shutdown(ctx context.Context) error {
+	s.closing.Store(true)
+
+	err := s.users.Brodcast(ctx, app.MsgServerShutdown, "", "", "", nil)
+	if err != nil {
+		log.Error(ctx, "brodcast:", log.Err(err))
+	}
+
+	failed := make([]string, 0)
+
+	for _, session := range s.workspace.Sessions(ctx) {
+		if ctx.Err() != nil {
+			failed = append(failed, session.Name)
+
+			continue
+		}
+
+		msgType, msg, _, writeErr := s.write(ctx, session, app.SaveShutdown, "")
+		if writeErr != nil {
+			log.Error(ctx, "write file:", log.String("name", session.Name), log.Err(writeErr))
+
+			failed = append(failed, session.Name)
+		}
+
+		if msgType == app.MsgNil {
+			continue
+		}
+
+		brodcastErr := session.Hub.Brodcast(ctx, msgType, "", "", msg, nil)
+		if brodcastErr != nil {
+			log.Error(ctx, "brodcast:", log.Err(brodcastErr))
+		}
+	}
+
+	if len(failed) > 0 {
+		return errors.New("files not saved on shutdown: %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
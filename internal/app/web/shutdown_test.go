@@ -0,0 +1,124 @@
+package web_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/mocks"
+	"github.com/fakovacic/editor/internal/app/web"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdown(t *testing.T) {
+	type brodcast struct {
+		msgType app.MsgType
+		msg     string
+	}
+
+	cases := []struct {
+		it string
+
+		writeErr error
+
+		expectedBrodcasts []brodcast
+		expectedError     string
+	}{
+		{
+			it: "open files saved",
+			expectedBrodcasts: []brodcast{
+				{
+					msgType: app.MsgServerShutdown,
+				},
+				{
+					msgType: app.MsgServerFileSaved,
+				},
+			},
+		},
+		{
+			it:       "open file not saved",
+			writeErr: errors.New("io write: permission denied"),
+			expectedBrodcasts: []brodcast{
+				{
+					msgType: app.MsgServerShutdown,
+				},
+				{
+					msgType: app.MsgServerFileNotSaved,
+					msg:     "file 'main.css' not saved",
+				},
+			},
+			expectedError: "files not saved on shutdown: main.css",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			brodcasts := make([]brodcast, 0)
+
+			brodcastFunc := func(_ context.Context, msgType app.MsgType, _, _, msg string, _ *app.FileMeta) error {
+				brodcasts = append(brodcasts, brodcast{
+					msgType: msgType,
+					msg:     msg,
+				})
+
+				return nil
+			}
+
+			session := &app.Session{
+				Name: "main.css",
+				Editor: &mocks.EditorMock{
+					WriteFunc: func(ctx context.Context) ([]*app.ChangeMsg, error) {
+						// version of flushed file records clients editing it
+						assert.Equal(t, app.SaveInfo{
+							Authors: []string{"mock-username"},
+							Trigger: app.SaveShutdown,
+						}, app.GetSaveInfo(ctx))
+
+						return nil, tc.writeErr
+					},
+				},
+				Hub: &mocks.HubMock{
+					BrodcastFunc: brodcastFunc,
+					UsernamesFunc: func() []string {
+						return []string{"mock-username"}
+					},
+					ReadyFunc: func() []string {
+						return nil
+					},
+				},
+			}
+
+			users := &mocks.HubMock{
+				BrodcastFunc: brodcastFunc,
+			}
+
+			service := web.New(users, &mocks.WorkspaceMock{
+				SessionsFunc: func(_ context.Context) []*app.Session {
+					return []*app.Session{session}
+				},
+			}, nil, nil)
+
+			err := service.Shutdown(context.Background())
+			if err != nil {
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+
+			assert.Equal(t, tc.expectedBrodcasts, brodcasts)
+
+			// no logins while shutting down
+			_, err = service.Login(context.Background(), "new-username")
+			assert.EqualError(t, err, "server shutting down")
+
+			// edits after files were saved are rejected and connection closed
+			for _, msgType := range []app.MsgType{app.MsgConnTextChange, app.MsgConnVersionRestore} {
+				replyType, _, closeConn, msgErr := web.IncommingMsg(context.Background(), service, session, msgType, []byte("{}"), "mock-id")
+				assert.EqualError(t, msgErr, "server shutting down, "+msgType.String()+" rejected")
+				assert.Equal(t, app.MsgNil, replyType)
+				assert.True(t, closeConn)
+			}
+
+			assert.Empty(t, session.Editor.(*mocks.EditorMock).ChangeCalls())
+		})
+	}
+}
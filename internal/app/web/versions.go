@@ -0,0 +1,212 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/errors"
+)
+
+func (s *service) Versions(ctx context.Context, id, file string) ([]app.Version, error) {
+	name, err := s.versionsOf(ctx, id, file)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.versioning.List(ctx, name)
+}
+
+func (s *service) Version(ctx context.Context, id, file, version string) (string, error) {
+	name, err := s.versionsOf(ctx, id, file)
+	if err != nil {
+		return "", err
+	}
+
+	return s.versioning.Get(ctx, name, version)
+}
+
+func (s *service) VersionDiff(ctx context.Context, id, file, from, to string) (string, error) {
+	name, err := s.versionsOf(ctx, id, file)
+	if err != nil {
+		return "", err
+	}
+
+	return s.versioning.Diff(ctx, name, from, to)
+}
+
+// RestoreVersion restores version of file in its session, file open
+// by no client is written when session is closed
+func (s *service) RestoreVersion(ctx context.Context, id, file, version string) error {
+	_, err := s.versionsOf(ctx, id, file)
+	if err != nil {
+		return err
+	}
+
+	username := s.username(id)
+
+	ctx = restoreInfo(ctx, version, username)
+
+	session, err := s.workspace.Open(ctx, file)
+	if err != nil {
+		return errors.Wrap(err, "workspace open")
+	}
+
+	err = s.restore(ctx, session, version, username)
+
+	closeErr := s.workspace.Close(ctx, session.Name)
+	if closeErr != nil && err == nil {
+		err = errors.Wrap(closeErr, "workspace close")
+	}
+
+	return err
+}
+
+// versionsOf returns name versions of file are saved under,
+// versions are kept by path of file relative to workspace
+func (s *service) versionsOf(ctx context.Context, id, file string) (string, error) {
+	err := s.Index(ctx, id)
+	if err != nil {
+		return "", errors.UnauthorizedWrap(err, "client")
+	}
+
+	if s.versioning == nil {
+		return "", errors.BadRequest("versioning not enabled")
+	}
+
+	if file == "" {
+		return "", errors.BadRequest("file name empty")
+	}
+
+	if !filepath.IsLocal(filepath.FromSlash(file)) {
+		return "", errors.BadRequest("invalid file name '%s'", file)
+	}
+
+	return path.Clean(filepath.ToSlash(file)), nil
+}
+
+// username returns username of logged in client, empty if not logged in
+func (s *service) username(id string) string {
+	client, ok := s.users.Get(id)
+	if !ok {
+		return ""
+	}
+
+	return client.Username
+}
+
+// versionMsg handles version messages of client, versions are of
+// session file, replies are sent only to client who asked for them
+func (s *service) versionMsg(ctx context.Context, session *app.Session, msgType app.MsgType, message []byte, clientID string) (app.MsgType, string, bool, error) {
+	var msg app.WSMsgVersion
+
+	err := json.Unmarshal(message, &msg)
+	if err != nil {
+		return app.MsgNil, "", true, errors.Wrap(err, "unmarshall version msg")
+	}
+
+	if s.versioning == nil {
+		return app.MsgServerVersionFailed, "versioning not enabled", false, errors.BadRequest("versioning not enabled")
+	}
+
+	name := session.Name
+
+	var (
+		replyMsg app.MsgType
+		reply    string
+	)
+
+	switch msgType {
+	case app.MsgConnVersions:
+		var versions []app.Version
+
+		versions, err = s.versioning.List(ctx, name)
+		if err == nil {
+			replyMsg = app.MsgServerVersions
+
+			var data []byte
+
+			data, err = json.Marshal(versions)
+			reply = string(data)
+		}
+	case app.MsgConnVersion:
+		replyMsg = app.MsgServerVersion
+
+		reply, err = s.versioning.Get(ctx, name, msg.Data.ID)
+	case app.MsgConnVersionDiff:
+		replyMsg = app.MsgServerVersionDiff
+
+		reply, err = s.versioning.Diff(ctx, name, msg.Data.ID, msg.Data.To)
+	case app.MsgConnVersionRestore:
+		username := s.username(clientID)
+
+		// restored changes and version are sent to all clients
+		replyMsg = app.MsgNil
+
+		err = s.restore(restoreInfo(ctx, msg.Data.ID, username), session, msg.Data.ID, username)
+	default:
+		return app.MsgNil, "", true, errors.BadRequest("invalid version message '%s'", msgType)
+	}
+
+	if err != nil {
+		return app.MsgServerVersionFailed, versionFailed(msg.Data.ID, err), false, errors.Wrap(err, "%s", msgType)
+	}
+
+	return replyMsg, reply, false, nil
+}
+
+// versionFailed returns reason version message failed safe to send to
+// clients, errors of versioning can tell about storage so they are only logged
+func versionFailed(id string, err error) string {
+	switch {
+	case id == "":
+		return "versions not available"
+	case errors.IsNotFound(err):
+		return fmt.Sprintf("version '%s' not found", id)
+	case errors.IsBadRequest(err):
+		return fmt.Sprintf("version '%s' not valid", id)
+	case errors.IsConflict(err):
+		return fmt.Sprintf("version '%s' not restored, file changed on source", id)
+	default:
+		return fmt.Sprintf("version '%s' not available", id)
+	}
+}
+
+// restore saves version as newest and sets it as contents of session,
+// clients get it as change none of them made
+func (s *service) restore(ctx context.Context, session *app.Session, version, username string) error {
+	contents, err := s.versioning.Restore(ctx, session.Name, version)
+	if err != nil {
+		return errors.Wrap(err, "versioning restore")
+	}
+
+	changes, err := session.Editor.Replace(ctx, contents)
+	if err != nil {
+		return errors.Wrap(err, "editor replace")
+	}
+
+	err = brodcastChanges(ctx, session, changes)
+	if err != nil {
+		return err
+	}
+
+	err = session.Hub.Brodcast(ctx, app.MsgServerVersionRestored, "", username, version, nil)
+	if err != nil {
+		return errors.Wrap(err, "brodcast %s", app.MsgServerVersionRestored)
+	}
+
+	return nil
+}
+
+// restoreInfo returns context of restore, versioning and io as git
+// record client who restored version as author
+func restoreInfo(ctx context.Context, version, username string) context.Context {
+	return app.WithSaveInfo(ctx, app.SaveInfo{
+		Authors: []string{username},
+		Trigger: app.SaveManual,
+		Message: fmt.Sprintf("Restore version %s", version),
+	})
+}
@@ -0,0 +1,228 @@
+package web_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/mocks"
+	"github.com/fakovacic/editor/internal/app/web"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncommingMsgVersion(t *testing.T) {
+	type brodcast struct {
+		msgType  app.MsgType
+		clientID string
+		username string
+		msg      string
+	}
+
+	cases := []struct {
+		it string
+
+		msgType    app.MsgType
+		message    string
+		noVersions bool
+		versionErr error
+
+		expectedMsgType   app.MsgType
+		expectedMsg       string
+		expectedBrodcasts []brodcast
+		expectedError     string
+	}{
+		{
+			it:      "list versions",
+			msgType: app.MsgConnVersions,
+			message: `{"type":"conn-versions"}`,
+
+			expectedMsgType:   app.MsgServerVersions,
+			expectedMsg:       `[{"id":"1697628153","name":"css/main.css","time":"2023-10-18T11:22:33Z","size":4}]`,
+			expectedBrodcasts: []brodcast{},
+		},
+		{
+			it:      "get version",
+			msgType: app.MsgConnVersion,
+			message: `{"type":"conn-version","data":{"id":"1697628153"}}`,
+
+			expectedMsgType:   app.MsgServerVersion,
+			expectedMsg:       "a {}",
+			expectedBrodcasts: []brodcast{},
+		},
+		{
+			it:      "diff versions",
+			msgType: app.MsgConnVersionDiff,
+			message: `{"type":"conn-version-diff","data":{"id":"1697628153","to":"1697628154"}}`,
+
+			expectedMsgType:   app.MsgServerVersionDiff,
+			expectedMsg:       "1697628153..1697628154",
+			expectedBrodcasts: []brodcast{},
+		},
+		{
+			it:      "restore version",
+			msgType: app.MsgConnVersionRestore,
+			message: `{"type":"conn-version-restore","data":{"id":"1697628153"}}`,
+
+			expectedMsgType: app.MsgNil,
+			expectedBrodcasts: []brodcast{
+				{
+					msgType: app.MsgClientsTextChange,
+					msg:     `{"data":{"action":"insert","start":{"row":0,"column":0},"end":{"row":0,"column":4},"lines":["a {}"],"revision":1}}`,
+				},
+				{
+					msgType:  app.MsgServerVersionRestored,
+					username: "mock-username",
+					msg:      "1697628153",
+				},
+			},
+		},
+		{
+			it:         "version not found",
+			msgType:    app.MsgConnVersion,
+			message:    `{"type":"conn-version","data":{"id":"1"}}`,
+			versionErr: errors.NotFound("version '1' of file 'main.css' not found"),
+
+			expectedMsgType:   app.MsgServerVersionFailed,
+			expectedMsg:       "version '1' not found",
+			expectedBrodcasts: []brodcast{},
+			expectedError:     "conn-version: version '1' of file 'main.css' not found",
+		},
+		{
+			it:         "version storage failed",
+			msgType:    app.MsgConnVersion,
+			message:    `{"type":"conn-version","data":{"id":"1"}}`,
+			versionErr: errors.New("get object: open /var/versions/main.css/1: permission denied"),
+
+			expectedMsgType:   app.MsgServerVersionFailed,
+			expectedMsg:       "version '1' not available",
+			expectedBrodcasts: []brodcast{},
+			expectedError:     "conn-version: get object: open /var/versions/main.css/1: permission denied",
+		},
+		{
+			it:         "versioning not enabled",
+			msgType:    app.MsgConnVersions,
+			message:    `{"type":"conn-versions"}`,
+			noVersions: true,
+
+			expectedMsgType:   app.MsgServerVersionFailed,
+			expectedMsg:       "versioning not enabled",
+			expectedBrodcasts: []brodcast{},
+			expectedError:     "versioning not enabled",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			versioningMock := &mocks.VersioningMock{
+				ListFunc: func(_ context.Context, filename string) ([]app.Version, error) {
+					return []app.Version{
+						{
+							ID:   "1697628153",
+							Name: filename,
+							Time: time.Date(2023, 10, 18, 11, 22, 33, 0, time.UTC),
+							Size: 4,
+						},
+					}, nil
+				},
+				GetFunc: func(_ context.Context, _, _ string) (string, error) {
+					return "a {}", tc.versionErr
+				},
+				DiffFunc: func(_ context.Context, _, from, to string) (string, error) {
+					return from + ".." + to, nil
+				},
+				RestoreFunc: func(ctx context.Context, _, _ string) (string, error) {
+					// restoring client is author of restored version
+					assert.Equal(t, app.SaveInfo{
+						Authors: []string{"mock-username"},
+						Trigger: app.SaveManual,
+						Message: "Restore version 1697628153",
+					}, app.GetSaveInfo(ctx))
+
+					return "a {}", nil
+				},
+			}
+
+			var versioning app.Versioning = versioningMock
+			if tc.noVersions {
+				versioning = nil
+			}
+
+			editorMock := &mocks.EditorMock{
+				ReplaceFunc: func(_ context.Context, contents string) ([]*app.ChangeMsg, error) {
+					return []*app.ChangeMsg{
+						{
+							Action:   "insert",
+							End:      app.ChangeRow{Column: len(contents)},
+							Lines:    []string{contents},
+							Revision: 1,
+						},
+					}, nil
+				},
+			}
+
+			brodcasts := make([]brodcast, 0)
+
+			hubMock := &mocks.HubMock{
+				BrodcastFunc: func(_ context.Context, msgType app.MsgType, clientID, username, msg string, _ *app.FileMeta) error {
+					brodcasts = append(brodcasts, brodcast{
+						msgType:  msgType,
+						clientID: clientID,
+						username: username,
+						msg:      msg,
+					})
+
+					return nil
+				},
+			}
+
+			users := &mocks.HubMock{
+				GetFunc: func(id string) (*app.Client, bool) {
+					return &app.Client{
+						ID:       id,
+						Username: "mock-username",
+					}, true
+				},
+			}
+
+			session := &app.Session{
+				Name:   "css/main.css",
+				Editor: editorMock,
+				Hub:    hubMock,
+			}
+
+			service := web.New(users, &mocks.WorkspaceMock{}, versioning, nil)
+
+			msgType, msg, closeConn, err := web.IncommingMsg(
+				context.Background(),
+				service,
+				session,
+				tc.msgType,
+				[]byte(tc.message),
+				"mock-id",
+			)
+			if err != nil {
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+
+			assert.Equal(t, tc.expectedMsgType, msgType)
+			assert.Equal(t, tc.expectedMsg, msg)
+			assert.False(t, closeConn)
+			assert.Equal(t, tc.expectedBrodcasts, brodcasts)
+
+			// versions are kept by path of file in workspace
+			for _, call := range versioningMock.ListCalls() {
+				assert.Equal(t, "css/main.css", call.S)
+			}
+
+			for _, call := range versioningMock.GetCalls() {
+				assert.Equal(t, "css/main.css", call.S1)
+			}
+
+			for _, call := range versioningMock.RestoreCalls() {
+				assert.Equal(t, "css/main.css", call.S1)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package web
+
+import (
+	"context"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/log"
+)
+
+// votesCheck is how often ready votes of clients are checked for expiry
+const votesCheck = time.Second
+
+// ExpireVotes unsets expired ready votes of clients until ctx is done,
+// clients of file are told voters are not ready so they vote again
+func (s *service) ExpireVotes(ctx context.Context) {
+	ticker := time.NewTicker(votesCheck)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.expireVotes(ctx)
+	}
+}
+
+func (s *service) expireVotes(ctx context.Context) {
+	for _, session := range s.workspace.Sessions(ctx) {
+		for _, id := range session.WriteValidator.Expire(ctx) {
+			session.Hub.SetReady(id, false)
+
+			var username string
+
+			client, ok := session.Hub.Get(id)
+			if ok {
+				username = client.Username
+			}
+
+			err := session.Hub.Brodcast(ctx, app.MsgClientsUnready, id, username, "", nil)
+			if err != nil {
+				log.Error(ctx, "brodcast:", log.Err(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package web_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/mocks"
+	"github.com/fakovacic/editor/internal/app/web"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpireVotes(t *testing.T) {
+	type brodcast struct {
+		msgType  app.MsgType
+		clientID string
+		username string
+	}
+
+	cases := []struct {
+		it string
+
+		expired []string
+
+		expectedReady     map[string]bool
+		expectedBrodcasts []brodcast
+	}{
+		{
+			it:                "no votes expired",
+			expired:           []string{},
+			expectedReady:     map[string]bool{},
+			expectedBrodcasts: []brodcast{},
+		},
+		{
+			it:      "expired votes unset",
+			expired: []string{"mock-id-1", "mock-id-2"},
+			expectedReady: map[string]bool{
+				"mock-id-1": false,
+				"mock-id-2": false,
+			},
+			expectedBrodcasts: []brodcast{
+				{
+					msgType:  app.MsgClientsUnready,
+					clientID: "mock-id-1",
+					username: "mock-username-1",
+				},
+				{
+					msgType:  app.MsgClientsUnready,
+					clientID: "mock-id-2",
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			ready := make(map[string]bool)
+			brodcasts := make([]brodcast, 0)
+
+			session := &app.Session{
+				Name: "main.css",
+				WriteValidator: &mocks.WriteValidatorMock{
+					ExpireFunc: func(_ context.Context) []string {
+						return tc.expired
+					},
+				},
+				Hub: &mocks.HubMock{
+					SetReadyFunc: func(id string, r bool) {
+						ready[id] = r
+					},
+					GetFunc: func(id string) (*app.Client, bool) {
+						// client left before its vote expired
+						if id != "mock-id-1" {
+							return nil, false
+						}
+
+						return &app.Client{
+							ID:       id,
+							Username: "mock-username-1",
+						}, true
+					},
+					BrodcastFunc: func(_ context.Context, msgType app.MsgType, clientID, username, _ string, _ *app.FileMeta) error {
+						brodcasts = append(brodcasts, brodcast{
+							msgType:  msgType,
+							clientID: clientID,
+							username: username,
+						})
+
+						return nil
+					},
+				},
+			}
+
+			service := web.New(&mocks.HubMock{}, &mocks.WorkspaceMock{
+				SessionsFunc: func(_ context.Context) []*app.Session {
+					return []*app.Session{session}
+				},
+			}, nil, nil)
+
+			web.ExpireVotes(context.Background(), service)
+
+			assert.Equal(t, tc.expectedReady, ready)
+			assert.Equal(t, tc.expectedBrodcasts, brodcasts)
+		})
+	}
+}
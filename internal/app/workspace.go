@@ -0,0 +1,35 @@
+package app
+
+import "context"
+
+//go:generate moq -out ./mocks/workspace.go -pkg mocks  . Workspace
+type Workspace interface {
+	// Open returns session of file, file is loaded when first client opens it
+	Open(context.Context, string) (*Session, error)
+
+	// Close releases session, file is written and unloaded when last client closes it
+	Close(context.Context, string) error
+
+	// Sessions returns sessions of files open by clients, sorted by name
+	Sessions(context.Context) []*Session
+
+	// Files returns files which can be opened, sorted by name
+	Files(context.Context) ([]FileInfo, error)
+
+	// Create creates empty file
+	Create(context.Context, string) error
+
+	// Rename renames file, file open by clients can not be renamed
+	Rename(context.Context, string, string) error
+
+	// Delete deletes file, file open by clients can not be deleted
+	Delete(context.Context, string) error
+}
+
+// Session is set of services for one open file
+type Session struct {
+	Name           string
+	Editor         Editor
+	Hub            Hub
+	WriteValidator WriteValidator
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/log"
+)
+
+func NewLogMiddleware(next app.Workspace) app.Workspace {
+	return &logMiddleware{
+		next:    next,
+		service: "workspace",
+	}
+}
+
+type logMiddleware struct {
+	next    app.Workspace
+	service string
+}
+
+func (m *logMiddleware) Open(ctx context.Context, name string) (*app.Session, error) {
+	log.Info(ctx, "part-request",
+		log.String("service", m.service),
+		log.String("method", "Open"),
+		log.String("layer", "part"),
+		log.Any("req", map[string]any{
+			"name": name,
+		}))
+
+	session, err := m.next.Open(ctx, name)
+
+	log.Info(ctx, "part-response",
+		log.String("service", m.service),
+		log.String("method", "Open"),
+		log.String("layer", "part"),
+		log.Err(err))
+
+	return session, err
+}
+
+func (m *logMiddleware) Close(ctx context.Context, name string) error {
+	log.Info(ctx, "part-request",
+		log.String("service", m.service),
+		log.String("method", "Close"),
+		log.String("layer", "part"),
+		log.Any("req", map[string]any{
+			"name": name,
+		}))
+
+	err := m.next.Close(ctx, name)
+
+	log.Info(ctx, "part-response",
+		log.String("service", m.service),
+		log.String("method", "Close"),
+		log.String("layer", "part"),
+		log.Err(err))
+
+	return err
+}
+
+// Sessions is called every watch interval, it is not logged
+func (m *logMiddleware) Sessions(ctx context.Context) []*app.Session {
+	return m.next.Sessions(ctx)
+}
+
+func (m *logMiddleware) Files(ctx context.Context) ([]app.FileInfo, error) {
+	log.Info(ctx, "part-request",
+		log.String("service", m.service),
+		log.String("method", "Files"),
+		log.String("layer", "part"))
+
+	files, err := m.next.Files(ctx)
+
+	log.Info(ctx, "part-response",
+		log.String("service", m.service),
+		log.String("method", "Files"),
+		log.String("layer", "part"),
+		log.Err(err))
+
+	return files, err
+}
+
+func (m *logMiddleware) Create(ctx context.Context, name string) error {
+	log.Info(ctx, "part-request",
+		log.String("service", m.service),
+		log.String("method", "Create"),
+		log.String("layer", "part"),
+		log.Any("req", map[string]any{
+			"name": name,
+		}))
+
+	err := m.next.Create(ctx, name)
+
+	log.Info(ctx, "part-response",
+		log.String("service", m.service),
+		log.String("method", "Create"),
+		log.String("layer", "part"),
+		log.Err(err))
+
+	return err
+}
+
+func (m *logMiddleware) Rename(ctx context.Context, name, newName string) error {
+	log.Info(ctx, "part-request",
+		log.String("service", m.service),
+		log.String("method", "Rename"),
+		log.String("layer", "part"),
+		log.Any("req", map[string]any{
+			"name":    name,
+			"newName": newName,
+		}))
+
+	err := m.next.Rename(ctx, name, newName)
+
+	log.Info(ctx, "part-response",
+		log.String("service", m.service),
+		log.String("method", "Rename"),
+		log.String("layer", "part"),
+		log.Err(err))
+
+	return err
+}
+
+func (m *logMiddleware) Delete(ctx context.Context, name string) error {
+	log.Info(ctx, "part-request",
+		log.String("service", m.service),
+		log.String("method", "Delete"),
+		log.String("layer", "part"),
+		log.Any("req", map[string]any{
+			"name": name,
+		}))
+
+	err := m.next.Delete(ctx, name)
+
+	log.Info(ctx, "part-response",
+		log.String("service", m.service),
+		log.String("method", "Delete"),
+		log.String("layer", "part"),
+		log.Err(err))
+
+	return err
+}
@@ -0,0 +1,430 @@
+package workspace
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor"
+	"github.com/fakovacic/editor/internal/errors"
+)
+
+// Factory creates services for file, name is path relative to workspace root
+type Factory func(name string) (*app.Session, error)
+
+// New returns workspace creating sessions with factory, defaultFile is
+// opened when name is empty, in single file mode it is only file and
+// dir is nil.
+func New(factory Factory, dir editor.Dir, defaultFile string) app.Workspace {
+	return &workspace{
+		factory:     factory,
+		dir:         dir,
+		defaultFile: defaultFile,
+		sessions:    make(map[string]*session),
+	}
+}
+
+type workspace struct {
+	factory     Factory
+	dir         editor.Dir
+	defaultFile string
+	sessions    map[string]*session
+	sync.Mutex
+}
+
+// session is removed once last client leaves and file is unloaded,
+// it is kept loaded if file could not be written so edits are not lost
+type session struct {
+	*app.Session
+	clients int
+
+	// busy is set while file is loaded, written and unloaded, renamed or
+	// deleted without workspace lock held, it is closed once that is done
+	busy chan struct{}
+}
+
+func (s *workspace) Open(ctx context.Context, name string) (*app.Session, error) {
+	name, err := s.name(name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Lock()
+
+	sess, err := s.session(ctx, name)
+	if err != nil {
+		s.Unlock()
+
+		return nil, err
+	}
+
+	if sess == nil {
+		newSession, factoryErr := s.factory(name)
+		if factoryErr != nil {
+			s.Unlock()
+
+			return nil, errors.Wrap(factoryErr, "create session '%s'", name)
+		}
+
+		newSession.Name = name
+
+		sess = &session{
+			Session: newSession,
+		}
+
+		s.sessions[name] = sess
+	}
+
+	if sess.clients > 0 {
+		sess.clients++
+
+		s.Unlock()
+
+		return sess.Session, nil
+	}
+
+	// slow io must not block other files, clients opening file
+	// meanwhile wait until it is loaded
+	busy := make(chan struct{})
+	sess.busy = busy
+
+	s.Unlock()
+
+	err = sess.Editor.Load(ctx)
+
+	s.Lock()
+	defer s.Unlock()
+
+	sess.busy = nil
+	close(busy)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "editor load content")
+	}
+
+	sess.clients++
+
+	return sess.Session, nil
+}
+
+func (s *workspace) Close(ctx context.Context, name string) error {
+	name, err := s.name(name)
+	if err != nil {
+		return err
+	}
+
+	s.Lock()
+
+	sess, ok := s.sessions[name]
+	if !ok || sess.clients == 0 || sess.busy != nil {
+		s.Unlock()
+
+		return errors.NotFound("file '%s' not open", name)
+	}
+
+	sess.clients--
+
+	if sess.clients > 0 {
+		s.Unlock()
+
+		return nil
+	}
+
+	// slow io must not block other files, file is opened again
+	// only once it is written and unloaded
+	busy := make(chan struct{})
+	sess.busy = busy
+
+	s.Unlock()
+
+	err = s.unload(ctx, sess)
+
+	s.Lock()
+	defer s.Unlock()
+
+	sess.busy = nil
+	close(busy)
+
+	if err != nil {
+		return err
+	}
+
+	if s.sessions[name] == sess {
+		delete(s.sessions, name)
+	}
+
+	return nil
+}
+
+// unload writes file of session no client edits and unloads it, no
+// clients are left to send merged changes to, file stays loaded if
+// write fails so edits are not lost
+func (s *workspace) unload(ctx context.Context, sess *session) error {
+	_, err := sess.Editor.Write(ctx)
+	if err != nil {
+		return errors.Wrap(err, "editor write")
+	}
+
+	err = sess.Editor.Unload(ctx)
+	if err != nil {
+		return errors.Wrap(err, "editor unload")
+	}
+
+	return nil
+}
+
+// session returns session of file, nil if there is none, busy file is
+// waited for, lock is released while waiting and held again on return
+func (s *workspace) session(ctx context.Context, name string) (*session, error) {
+	for {
+		sess, ok := s.sessions[name]
+		if !ok {
+			return nil, nil
+		}
+
+		if sess.busy == nil {
+			return sess, nil
+		}
+
+		busy := sess.busy
+
+		s.Unlock()
+
+		select {
+		case <-busy:
+		case <-ctx.Done():
+			s.Lock()
+
+			return nil, errors.Wrap(ctx.Err(), "file '%s' busy", name)
+		}
+
+		s.Lock()
+	}
+}
+
+func (s *workspace) Sessions(_ context.Context) []*app.Session {
+	s.Lock()
+	defer s.Unlock()
+
+	sessions := make([]*app.Session, 0, len(s.sessions))
+
+	for _, sess := range s.sessions {
+		if sess.clients > 0 {
+			sessions = append(sessions, sess.Session)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Name < sessions[j].Name
+	})
+
+	return sessions
+}
+
+func (s *workspace) Files(ctx context.Context) ([]app.FileInfo, error) {
+	if s.dir == nil {
+		file := app.FileInfo{
+			Name: s.defaultFile,
+		}
+
+		err := file.Type.Parse(path.Ext(s.defaultFile))
+		if err != nil {
+			return nil, errors.Wrap(err, "file type")
+		}
+
+		return []app.FileInfo{file}, nil
+	}
+
+	files, err := s.dir.List(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "list files")
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+
+	return files, nil
+}
+
+func (s *workspace) Create(ctx context.Context, name string) error {
+	if s.dir == nil {
+		return errors.MethodNotAllowed("files can be created only in workspace mode")
+	}
+
+	name, err := s.fileName(name)
+	if err != nil {
+		return err
+	}
+
+	err = s.dir.Create(ctx, name)
+	if err != nil {
+		return errors.Wrap(err, "create file")
+	}
+
+	return nil
+}
+
+func (s *workspace) Rename(ctx context.Context, name, newName string) error {
+	if s.dir == nil {
+		return errors.MethodNotAllowed("files can be renamed only in workspace mode")
+	}
+
+	name, err := s.name(name)
+	if err != nil {
+		return err
+	}
+
+	newName, err = s.fileName(newName)
+	if err != nil {
+		return err
+	}
+
+	s.Lock()
+	free, err := s.reserve(ctx, name, newName)
+	s.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	err = s.dir.Rename(ctx, name, newName)
+
+	s.Lock()
+	free()
+	s.Unlock()
+
+	if err != nil {
+		return errors.Wrap(err, "rename file")
+	}
+
+	return nil
+}
+
+func (s *workspace) Delete(ctx context.Context, name string) error {
+	if s.dir == nil {
+		return errors.MethodNotAllowed("files can be deleted only in workspace mode")
+	}
+
+	name, err := s.name(name)
+	if err != nil {
+		return err
+	}
+
+	s.Lock()
+	free, err := s.reserve(ctx, name)
+	s.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	err = s.dir.Delete(ctx, name)
+
+	s.Lock()
+	free()
+	s.Unlock()
+
+	if err != nil {
+		return errors.Wrap(err, "delete file")
+	}
+
+	return nil
+}
+
+// reserve releases sessions of files and keeps files busy while they are
+// renamed or deleted without workspace lock held, files are opened again
+// only once returned func frees them, lock must be held for both
+func (s *workspace) reserve(ctx context.Context, names ...string) (func(), error) {
+	busy := make(chan struct{})
+	reserved := make([]string, 0, len(names))
+
+	free := func() {
+		for _, name := range reserved {
+			delete(s.sessions, name)
+		}
+
+		close(busy)
+	}
+
+	for _, name := range names {
+		// file renamed to itself is reserved once
+		if s.sessions[name] != nil && s.sessions[name].busy == busy {
+			continue
+		}
+
+		err := s.release(ctx, name)
+		if err != nil {
+			free()
+
+			return nil, err
+		}
+
+		s.sessions[name] = &session{
+			busy: busy,
+		}
+
+		reserved = append(reserved, name)
+	}
+
+	return free, nil
+}
+
+// release removes session of file which is not open, its editor state
+// would not match contents after file is renamed or deleted
+func (s *workspace) release(ctx context.Context, name string) error {
+	sess, err := s.session(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if sess == nil {
+		return nil
+	}
+
+	if sess.clients > 0 {
+		return errors.BadRequest("file '%s' is open by %d clients", name, sess.clients)
+	}
+
+	delete(s.sessions, name)
+
+	return nil
+}
+
+// fileName returns cleaned name of new file, file type must be supported
+func (s *workspace) fileName(name string) (string, error) {
+	name, err := s.name(name)
+	if err != nil {
+		return "", err
+	}
+
+	var fileType app.FileType
+
+	err = fileType.Parse(path.Ext(name))
+	if err != nil {
+		return "", errors.Wrap(err, "file type")
+	}
+
+	return name, nil
+}
+
+// name returns cleaned file name, names must stay inside workspace
+func (s *workspace) name(name string) (string, error) {
+	if name == "" {
+		name = s.defaultFile
+	}
+
+	if name == "" {
+		return "", errors.BadRequest("file name empty")
+	}
+
+	cleaned := path.Clean(filepath.ToSlash(name))
+	if !filepath.IsLocal(cleaned) {
+		return "", errors.BadRequest("invalid file name '%s'", name)
+	}
+
+	return cleaned, nil
+}
@@ -0,0 +1,550 @@
+package workspace_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/editor"
+	editorMocks "github.com/fakovacic/editor/internal/app/editor/mocks"
+	"github.com/fakovacic/editor/internal/app/mocks"
+	"github.com/fakovacic/editor/internal/app/workspace"
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspace(t *testing.T) {
+	ctx := context.Background()
+
+	editors := make(map[string]*mocks.EditorMock)
+
+	ws := workspace.New(func(name string) (*app.Session, error) {
+		if name == "missing.css" {
+			return nil, errors.NotFound("file not found")
+		}
+
+		editor := &mocks.EditorMock{
+			LoadFunc: func(ctx context.Context) error {
+				return nil
+			},
+			WriteFunc: func(ctx context.Context) ([]*app.ChangeMsg, error) {
+				return nil, nil
+			},
+			UnloadFunc: func(ctx context.Context) error {
+				return nil
+			},
+		}
+
+		editors[name] = editor
+
+		return &app.Session{
+			Editor: editor,
+		}, nil
+	}, nil, "")
+
+	// first client loads file
+	session, err := ws.Open(ctx, "css/main.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "css/main.css", session.Name)
+	assert.Len(t, editors["css/main.css"].LoadCalls(), 1)
+
+	// second client gets same session
+	next, err := ws.Open(ctx, "./css/../css/main.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Same(t, session, next)
+	assert.Len(t, editors["css/main.css"].LoadCalls(), 1)
+
+	// other file has own session
+	other, err := ws.Open(ctx, "index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NotSame(t, session, other)
+
+	// open sessions sorted by name
+	assert.Equal(t, []*app.Session{session, other}, ws.Sessions(ctx))
+
+	// file is written and unloaded when last client leaves
+	err = ws.Close(ctx, "css/main.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, editors["css/main.css"].WriteCalls(), 0)
+
+	err = ws.Close(ctx, "css/main.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, editors["css/main.css"].WriteCalls(), 1)
+	assert.Len(t, editors["css/main.css"].UnloadCalls(), 1)
+	assert.Equal(t, []*app.Session{other}, ws.Sessions(ctx))
+
+	err = ws.Close(ctx, "css/main.css")
+	assert.Equal(t, "file 'css/main.css' not open", err.Error())
+
+	// session is removed, file opened again has new one
+	reopened, err := ws.Open(ctx, "css/main.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NotSame(t, session, reopened)
+	assert.Len(t, editors["css/main.css"].LoadCalls(), 1)
+}
+
+func TestWorkspaceClose(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		writeErr error
+		sessions int
+	)
+
+	writing := make(chan struct{})
+	written := make(chan struct{})
+
+	ws := workspace.New(func(name string) (*app.Session, error) {
+		sessions++
+
+		return &app.Session{
+			Editor: &mocks.EditorMock{
+				LoadFunc: func(ctx context.Context) error {
+					return nil
+				},
+				WriteFunc: func(ctx context.Context) ([]*app.ChangeMsg, error) {
+					if name == "main.css" {
+						close(writing)
+						<-written
+					}
+
+					return nil, writeErr
+				},
+				UnloadFunc: func(ctx context.Context) error {
+					return nil
+				},
+			},
+		}, nil
+	}, nil, "")
+
+	_, err := ws.Open(ctx, "main.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closed := make(chan error)
+
+	go func() {
+		closed <- ws.Close(ctx, "main.css")
+	}()
+
+	<-writing
+
+	// other files are opened while file is written
+	_, err = ws.Open(ctx, "index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// file is opened again once it is written and unloaded
+	opened := make(chan *app.Session)
+
+	go func() {
+		session, openErr := ws.Open(ctx, "main.css")
+		assert.NoError(t, openErr)
+
+		opened <- session
+	}()
+
+	select {
+	case <-opened:
+		t.Fatal("file opened while it is written")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(written)
+
+	assert.NoError(t, <-closed)
+	assert.Equal(t, "main.css", (<-opened).Name)
+	assert.Equal(t, 3, sessions)
+
+	// file not written stays loaded in its session
+	writeErr = errors.New("io write: permission denied")
+
+	err = ws.Close(ctx, "index.html")
+	assert.EqualError(t, err, "editor write: io write: permission denied")
+
+	_, err = ws.Open(ctx, "index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 3, sessions)
+}
+
+func TestWorkspaceLoad(t *testing.T) {
+	ctx := context.Background()
+
+	loading := make(chan struct{})
+	loaded := make(chan struct{})
+
+	editors := make(map[string]*mocks.EditorMock)
+
+	ws := workspace.New(func(name string) (*app.Session, error) {
+		editors[name] = &mocks.EditorMock{
+			LoadFunc: func(ctx context.Context) error {
+				if name == "main.css" {
+					close(loading)
+					<-loaded
+				}
+
+				return nil
+			},
+		}
+
+		return &app.Session{
+			Editor: editors[name],
+		}, nil
+	}, &editorMocks.DirMock{
+		RenameFunc: func(ctx context.Context, name, newName string) error {
+			return nil
+		},
+	}, "")
+
+	opened := make(chan *app.Session, 2)
+
+	for i := 0; i < 2; i++ {
+		go func() {
+			session, openErr := ws.Open(ctx, "main.css")
+			assert.NoError(t, openErr)
+
+			opened <- session
+		}()
+	}
+
+	<-loading
+
+	// other files are opened, listed and renamed while file is loaded
+	_, err := ws.Open(ctx, "index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, ws.Sessions(ctx), 1)
+
+	err = ws.Rename(ctx, "old.js", "new.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-opened:
+		t.Fatal("file opened while it is loaded")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(loaded)
+
+	// clients opening file meanwhile share its session
+	assert.Equal(t, <-opened, <-opened)
+	assert.Len(t, editors["main.css"].LoadCalls(), 1)
+	assert.Len(t, ws.Sessions(ctx), 2)
+}
+
+func TestWorkspaceName(t *testing.T) {
+	cases := []struct {
+		it string
+
+		defaultFile string
+		name        string
+
+		expectedResponse string
+		expectedError    string
+	}{
+		{
+			it:               "default file",
+			defaultFile:      "custom.css",
+			name:             "",
+			expectedResponse: "custom.css",
+		},
+		{
+			it:            "empty name",
+			name:          "",
+			expectedError: "file name empty",
+		},
+		{
+			it:            "parent dir",
+			name:          "../secret.css",
+			expectedError: "invalid file name '../secret.css'",
+		},
+		{
+			it:            "absolute path",
+			name:          "/etc/passwd",
+			expectedError: "invalid file name '/etc/passwd'",
+		},
+		{
+			it:            "factory error",
+			name:          "missing.css",
+			expectedError: "create session 'missing.css': file not found",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			ws := workspace.New(func(name string) (*app.Session, error) {
+				if name != tc.expectedResponse {
+					return nil, errors.NotFound("file not found")
+				}
+
+				return &app.Session{
+					Editor: &mocks.EditorMock{
+						LoadFunc: func(ctx context.Context) error {
+							return nil
+						},
+					},
+				}, nil
+			}, nil, tc.defaultFile)
+
+			session, err := ws.Open(context.Background(), tc.name)
+			if err != nil {
+				assert.Equal(t, tc.expectedError, err.Error())
+
+				return
+			}
+
+			assert.Equal(t, tc.expectedResponse, session.Name)
+		})
+	}
+}
+
+func TestWorkspaceFiles(t *testing.T) {
+	cases := []struct {
+		it string
+
+		defaultFile string
+		files       []app.FileInfo
+		listErr     error
+
+		expectedResponse []app.FileInfo
+		expectedError    string
+	}{
+		{
+			it:          "single file",
+			defaultFile: "custom.css",
+			expectedResponse: []app.FileInfo{
+				{
+					Name: "custom.css",
+					Type: app.CSS,
+				},
+			},
+		},
+		{
+			it: "sorted by name",
+			files: []app.FileInfo{
+				{
+					Name: "js/main.js",
+					Type: app.Javascript,
+				},
+				{
+					Name: "index.html",
+					Type: app.HTML,
+				},
+				{
+					Name: "css/main.css",
+					Type: app.CSS,
+				},
+			},
+			expectedResponse: []app.FileInfo{
+				{
+					Name: "css/main.css",
+					Type: app.CSS,
+				},
+				{
+					Name: "index.html",
+					Type: app.HTML,
+				},
+				{
+					Name: "js/main.js",
+					Type: app.Javascript,
+				},
+			},
+		},
+		{
+			it:            "list error",
+			files:         []app.FileInfo{},
+			listErr:       errors.New("walk dir: permission denied"),
+			expectedError: "list files: walk dir: permission denied",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			var dir editor.Dir
+
+			if tc.files != nil {
+				dir = &editorMocks.DirMock{
+					ListFunc: func(ctx context.Context) ([]app.FileInfo, error) {
+						return tc.files, tc.listErr
+					},
+				}
+			}
+
+			ws := workspace.New(nil, dir, tc.defaultFile)
+
+			files, err := ws.Files(context.Background())
+			if err != nil {
+				assert.Equal(t, tc.expectedError, err.Error())
+
+				return
+			}
+
+			assert.Equal(t, tc.expectedResponse, files)
+		})
+	}
+}
+
+func TestWorkspaceFileOps(t *testing.T) {
+	cases := []struct {
+		it string
+
+		dir  bool
+		open string
+		op   func(ws app.Workspace) error
+
+		expectedCalls int
+		expectedError string
+	}{
+		{
+			it:  "create",
+			dir: true,
+			op: func(ws app.Workspace) error {
+				return ws.Create(context.Background(), "css/./landing.css")
+			},
+			expectedCalls: 1,
+		},
+		{
+			it:  "create unsupported type",
+			dir: true,
+			op: func(ws app.Workspace) error {
+				return ws.Create(context.Background(), "notes.txt")
+			},
+			expectedError: "file type: invalid file type '.txt'",
+		},
+		{
+			it:  "create outside workspace",
+			dir: true,
+			op: func(ws app.Workspace) error {
+				return ws.Create(context.Background(), "../landing.css")
+			},
+			expectedError: "invalid file name '../landing.css'",
+		},
+		{
+			it: "create in single file mode",
+			op: func(ws app.Workspace) error {
+				return ws.Create(context.Background(), "landing.css")
+			},
+			expectedError: "files can be created only in workspace mode",
+		},
+		{
+			it:  "rename",
+			dir: true,
+			op: func(ws app.Workspace) error {
+				return ws.Rename(context.Background(), "main.css", "landing.css")
+			},
+			expectedCalls: 1,
+		},
+		{
+			it:   "rename open file",
+			dir:  true,
+			open: "main.css",
+			op: func(ws app.Workspace) error {
+				return ws.Rename(context.Background(), "main.css", "landing.css")
+			},
+			expectedError: "file 'main.css' is open by 1 clients",
+		},
+		{
+			it:   "rename to open file",
+			dir:  true,
+			open: "landing.css",
+			op: func(ws app.Workspace) error {
+				return ws.Rename(context.Background(), "main.css", "landing.css")
+			},
+			expectedError: "file 'landing.css' is open by 1 clients",
+		},
+		{
+			it:  "delete",
+			dir: true,
+			op: func(ws app.Workspace) error {
+				return ws.Delete(context.Background(), "old.js")
+			},
+			expectedCalls: 1,
+		},
+		{
+			it:   "delete open file",
+			dir:  true,
+			open: "old.js",
+			op: func(ws app.Workspace) error {
+				return ws.Delete(context.Background(), "old.js")
+			},
+			expectedError: "file 'old.js' is open by 1 clients",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			var (
+				dir     editor.Dir
+				dirMock = &editorMocks.DirMock{
+					CreateFunc: func(ctx context.Context, name string) error {
+						return nil
+					},
+					RenameFunc: func(ctx context.Context, name, newName string) error {
+						return nil
+					},
+					DeleteFunc: func(ctx context.Context, name string) error {
+						return nil
+					},
+				}
+			)
+
+			if tc.dir {
+				dir = dirMock
+			}
+
+			ws := workspace.New(func(name string) (*app.Session, error) {
+				return &app.Session{
+					Editor: &mocks.EditorMock{
+						LoadFunc: func(ctx context.Context) error {
+							return nil
+						},
+					},
+				}, nil
+			}, dir, "")
+
+			if tc.open != "" {
+				_, err := ws.Open(context.Background(), tc.open)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := tc.op(ws)
+			if err != nil {
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+
+			calls := len(dirMock.CreateCalls()) + len(dirMock.RenameCalls()) + len(dirMock.DeleteCalls())
+
+			assert.Equal(t, tc.expectedCalls, calls)
+		})
+	}
+}
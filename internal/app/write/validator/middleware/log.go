@@ -23,16 +23,17 @@ func (m *logMiddleware) Clear(ctx context.Context) {
 	m.next.Clear(ctx)
 }
 
-func (m *logMiddleware) AddClient(ctx context.Context, id string) error {
+func (m *logMiddleware) AddClient(ctx context.Context, id, username string) error {
 	log.Info(ctx, "part-request",
 		log.String("service", m.service),
 		log.String("method", "AddClient"),
 		log.String("layer", "part"),
 		log.Any("req", map[string]any{
-			"id": id,
+			"id":       id,
+			"username": username,
 		}))
 
-	err := m.next.AddClient(ctx, id)
+	err := m.next.AddClient(ctx, id, username)
 
 	log.Info(ctx, "part-response",
 		log.String("service", m.service),
@@ -116,3 +117,19 @@ func (m *logMiddleware) IsReady(ctx context.Context) bool {
 
 	return ok
 }
+
+func (m *logMiddleware) Expire(ctx context.Context) []string {
+	expired := m.next.Expire(ctx)
+
+	if len(expired) > 0 {
+		log.Info(ctx, "part-response",
+			log.String("service", m.service),
+			log.String("method", "Expire"),
+			log.String("layer", "part"),
+			log.Any("res", map[string]any{
+				"expired": expired,
+			}))
+	}
+
+	return expired
+}
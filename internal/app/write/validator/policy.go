@@ -0,0 +1,88 @@
+package validator
+
+import (
+	"github.com/fakovacic/editor/internal/app"
+)
+
+// Vote is ready vote of client editing file
+type Vote struct {
+	Username string
+	Ready    bool
+}
+
+// Policy decides if file can be saved with votes of all clients editing it
+type Policy func(votes []Vote) bool
+
+// NewPolicy returns policy selected by approval
+func NewPolicy(approval app.Approval) Policy {
+	switch approval.Policy {
+	case app.ApprovalMajority:
+		return Majority()
+	case app.ApprovalOwner:
+		return Owner(approval.Owners)
+	case app.ApprovalApprovals:
+		return Approvals(approval.Approvals)
+	default:
+		return All()
+	}
+}
+
+// All is ready if every client is ready
+func All() Policy {
+	return func(votes []Vote) bool {
+		for _, vote := range votes {
+			if !vote.Ready {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// Majority is ready if more than half of clients are ready
+func Majority() Policy {
+	return func(votes []Vote) bool {
+		return ready(votes)*2 > len(votes)
+	}
+}
+
+// Owner is ready if any of clients with owner username is ready, usernames
+// are chosen on login without authentication so owners are advisory only,
+// anyone can log in as owner
+func Owner(owners []string) Policy {
+	isOwner := make(map[string]bool, len(owners))
+	for _, owner := range owners {
+		isOwner[owner] = true
+	}
+
+	return func(votes []Vote) bool {
+		for _, vote := range votes {
+			if vote.Ready && isOwner[vote.Username] {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Approvals is ready if number of clients are ready, if fewer
+// clients edit file all of them must be ready
+func Approvals(approvals int) Policy {
+	return func(votes []Vote) bool {
+		return ready(votes) >= min(approvals, len(votes))
+	}
+}
+
+func ready(votes []Vote) int {
+	var count int
+
+	for _, vote := range votes {
+		if vote.Ready {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,311 @@
+package validator_test
+
+import (
+	"testing"
+
+	"github.com/fakovacic/editor/internal/app"
+	"github.com/fakovacic/editor/internal/app/write/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAll(t *testing.T) {
+	cases := []struct {
+		it string
+
+		votes []validator.Vote
+
+		expected bool
+	}{
+		{
+			it: "all ready",
+			votes: []validator.Vote{
+				{Username: "alice", Ready: true},
+				{Username: "bob", Ready: true},
+			},
+
+			expected: true,
+		},
+		{
+			it: "one not ready",
+			votes: []validator.Vote{
+				{Username: "alice", Ready: true},
+				{Username: "bob", Ready: false},
+			},
+
+			expected: false,
+		},
+		{
+			it: "none ready",
+			votes: []validator.Vote{
+				{Username: "alice", Ready: false},
+				{Username: "bob", Ready: false},
+			},
+
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			policy := validator.All()
+
+			assert.Equal(t, tc.expected, policy(tc.votes))
+		})
+	}
+}
+
+func TestMajority(t *testing.T) {
+	cases := []struct {
+		it string
+
+		votes []validator.Vote
+
+		expected bool
+	}{
+		{
+			it: "more than half ready",
+			votes: []validator.Vote{
+				{Username: "alice", Ready: true},
+				{Username: "bob", Ready: true},
+				{Username: "carol", Ready: false},
+			},
+
+			expected: true,
+		},
+		{
+			it: "half ready",
+			votes: []validator.Vote{
+				{Username: "alice", Ready: true},
+				{Username: "bob", Ready: false},
+				{Username: "carol", Ready: true},
+				{Username: "dave", Ready: false},
+			},
+
+			expected: false,
+		},
+		{
+			it: "less than half ready",
+			votes: []validator.Vote{
+				{Username: "alice", Ready: true},
+				{Username: "bob", Ready: false},
+				{Username: "carol", Ready: false},
+			},
+
+			expected: false,
+		},
+		{
+			it: "all ready",
+			votes: []validator.Vote{
+				{Username: "alice", Ready: true},
+				{Username: "bob", Ready: true},
+			},
+
+			expected: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			policy := validator.Majority()
+
+			assert.Equal(t, tc.expected, policy(tc.votes))
+		})
+	}
+}
+
+func TestOwner(t *testing.T) {
+	cases := []struct {
+		it string
+
+		votes []validator.Vote
+
+		expected bool
+	}{
+		{
+			it: "owner ready",
+			votes: []validator.Vote{
+				{Username: "alice", Ready: true},
+				{Username: "bob", Ready: false},
+				{Username: "carol", Ready: false},
+			},
+
+			expected: true,
+		},
+		{
+			it: "other owner ready",
+			votes: []validator.Vote{
+				{Username: "alice", Ready: false},
+				{Username: "bob", Ready: false},
+				{Username: "carol", Ready: true},
+			},
+
+			expected: true,
+		},
+		{
+			it: "only not owner ready",
+			votes: []validator.Vote{
+				{Username: "alice", Ready: false},
+				{Username: "bob", Ready: true},
+				{Username: "carol", Ready: false},
+			},
+
+			expected: false,
+		},
+		{
+			it: "no owner editing",
+			votes: []validator.Vote{
+				{Username: "bob", Ready: true},
+				{Username: "dave", Ready: true},
+			},
+
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			policy := validator.Owner([]string{"alice", "carol"})
+
+			assert.Equal(t, tc.expected, policy(tc.votes))
+		})
+	}
+}
+
+func TestApprovals(t *testing.T) {
+	cases := []struct {
+		it string
+
+		votes []validator.Vote
+
+		expected bool
+	}{
+		{
+			it: "enough ready",
+			votes: []validator.Vote{
+				{Username: "alice", Ready: true},
+				{Username: "bob", Ready: true},
+				{Username: "carol", Ready: false},
+			},
+
+			expected: true,
+		},
+		{
+			it: "more than enough ready",
+			votes: []validator.Vote{
+				{Username: "alice", Ready: true},
+				{Username: "bob", Ready: true},
+				{Username: "carol", Ready: true},
+			},
+
+			expected: true,
+		},
+		{
+			it: "not enough ready",
+			votes: []validator.Vote{
+				{Username: "alice", Ready: true},
+				{Username: "bob", Ready: false},
+				{Username: "carol", Ready: false},
+			},
+
+			expected: false,
+		},
+		{
+			it: "fewer clients all ready",
+			votes: []validator.Vote{
+				{Username: "alice", Ready: true},
+			},
+
+			expected: true,
+		},
+		{
+			it: "fewer clients not ready",
+			votes: []validator.Vote{
+				{Username: "alice", Ready: false},
+			},
+
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			policy := validator.Approvals(2)
+
+			assert.Equal(t, tc.expected, policy(tc.votes))
+		})
+	}
+}
+
+func TestNewPolicy(t *testing.T) {
+	cases := []struct {
+		it string
+
+		approval app.Approval
+		votes    []validator.Vote
+
+		expected bool
+	}{
+		{
+			it: "all",
+			approval: app.Approval{
+				Policy: app.ApprovalAll,
+			},
+			votes: []validator.Vote{
+				{Username: "alice", Ready: true},
+				{Username: "bob", Ready: true},
+				{Username: "carol", Ready: false},
+			},
+
+			expected: false,
+		},
+		{
+			it: "majority",
+			approval: app.Approval{
+				Policy: app.ApprovalMajority,
+			},
+			votes: []validator.Vote{
+				{Username: "alice", Ready: true},
+				{Username: "bob", Ready: true},
+				{Username: "carol", Ready: false},
+			},
+
+			expected: true,
+		},
+		{
+			it: "owner",
+			approval: app.Approval{
+				Policy: app.ApprovalOwner,
+				Owners: []string{"carol"},
+			},
+			votes: []validator.Vote{
+				{Username: "alice", Ready: true},
+				{Username: "bob", Ready: true},
+				{Username: "carol", Ready: false},
+			},
+
+			expected: false,
+		},
+		{
+			it: "approvals",
+			approval: app.Approval{
+				Policy:    app.ApprovalApprovals,
+				Approvals: 1,
+			},
+			votes: []validator.Vote{
+				{Username: "alice", Ready: true},
+				{Username: "bob", Ready: false},
+				{Username: "carol", Ready: false},
+			},
+
+			expected: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			policy := validator.NewPolicy(tc.approval)
+
+			assert.Equal(t, tc.expected, policy(tc.votes))
+		})
+	}
+}
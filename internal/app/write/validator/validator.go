@@ -2,33 +2,49 @@ package validator
 
 import (
 	"context"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/fakovacic/editor/internal/app"
 	"github.com/fakovacic/editor/internal/errors"
 )
 
-func New() app.WriteValidator {
+// New returns validator deciding with policy, ready votes
+// older than expire are not counted, never expire if zero
+func New(policy Policy, expire time.Duration, now func() time.Time) app.WriteValidator {
 	return &writeValidator{
-		clients: make(map[string]bool),
+		clients: make(map[string]*client),
+		policy:  policy,
+		expire:  expire,
+		now:     now,
 	}
 }
 
 type writeValidator struct {
-	clients map[string]bool
+	clients map[string]*client
+	policy  Policy
+	expire  time.Duration
+	now     func() time.Time
 	sync.Mutex
 }
 
+type client struct {
+	username string
+	ready    bool
+	readyAt  time.Time
+}
+
 func (s *writeValidator) Clear(context.Context) {
 	s.Lock()
 	defer s.Unlock()
 
-	for id := range s.clients {
-		s.clients[id] = false
+	for _, c := range s.clients {
+		c.ready = false
 	}
 }
 
-func (s *writeValidator) AddClient(_ context.Context, id string) error {
+func (s *writeValidator) AddClient(_ context.Context, id, username string) error {
 	s.Lock()
 	defer s.Unlock()
 
@@ -37,7 +53,9 @@ func (s *writeValidator) AddClient(_ context.Context, id string) error {
 		return nil
 	}
 
-	s.clients[id] = false
+	s.clients[id] = &client{
+		username: username,
+	}
 
 	return nil
 }
@@ -60,9 +78,10 @@ func (s *writeValidator) ReadyClient(_ context.Context, id string) error {
 	s.Lock()
 	defer s.Unlock()
 
-	_, ok := s.clients[id]
+	c, ok := s.clients[id]
 	if ok {
-		s.clients[id] = true
+		c.ready = true
+		c.readyAt = s.now()
 
 		return nil
 	}
@@ -74,9 +93,9 @@ func (s *writeValidator) UnreadyClient(_ context.Context, id string) error {
 	s.Lock()
 	defer s.Unlock()
 
-	_, ok := s.clients[id]
+	c, ok := s.clients[id]
 	if ok {
-		s.clients[id] = false
+		c.ready = false
 
 		return nil
 	}
@@ -84,6 +103,8 @@ func (s *writeValidator) UnreadyClient(_ context.Context, id string) error {
 	return errors.New("client not found")
 }
 
+// IsReady reports if votes are enough by policy, client editing
+// file alone approves its own save with any policy
 func (s *writeValidator) IsReady(_ context.Context) bool {
 	s.Lock()
 	defer s.Unlock()
@@ -96,11 +117,40 @@ func (s *writeValidator) IsReady(_ context.Context) bool {
 		return true
 	}
 
-	for _, ready := range s.clients {
-		if !ready {
-			return false
+	now := s.now()
+	votes := make([]Vote, 0, len(s.clients))
+
+	for _, c := range s.clients {
+		votes = append(votes, Vote{
+			Username: c.username,
+			Ready:    c.ready && !s.expired(c, now),
+		})
+	}
+
+	return s.policy(votes)
+}
+
+func (s *writeValidator) Expire(_ context.Context) []string {
+	s.Lock()
+	defer s.Unlock()
+
+	now := s.now()
+	expired := make([]string, 0)
+
+	for id, c := range s.clients {
+		if c.ready && s.expired(c, now) {
+			c.ready = false
+
+			expired = append(expired, id)
 		}
 	}
 
-	return true
+	sort.Strings(expired)
+
+	return expired
+}
+
+// expired reports if ready vote of client is older than expire
+func (s *writeValidator) expired(c *client, now time.Time) bool {
+	return s.expire > 0 && now.Sub(c.readyAt) >= s.expire
 }
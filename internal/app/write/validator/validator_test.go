@@ -3,16 +3,18 @@ package validator_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/fakovacic/editor/internal/app/write/validator"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestValidator(t *testing.T) {
 	ctx := context.Background()
 
-	v := validator.New()
+	v := validator.New(validator.All(), 0, time.Now)
 
-	err := v.AddClient(ctx, "1")
+	err := v.AddClient(ctx, "1", "alice")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -43,14 +45,14 @@ func TestValidator(t *testing.T) {
 func TestValidatorMultipleUsers(t *testing.T) {
 	ctx := context.Background()
 
-	v := validator.New()
+	v := validator.New(validator.All(), 0, time.Now)
 
-	err := v.AddClient(ctx, "1")
+	err := v.AddClient(ctx, "1", "alice")
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = v.AddClient(ctx, "2")
+	err = v.AddClient(ctx, "2", "bob")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -106,7 +108,7 @@ func TestValidatorMultipleUsers(t *testing.T) {
 func TestValidatorError(t *testing.T) {
 	ctx := context.Background()
 
-	v := validator.New()
+	v := validator.New(validator.All(), 0, time.Now)
 
 	err := v.RemoveClient(ctx, "1")
 	if err == nil {
@@ -123,3 +125,108 @@ func TestValidatorError(t *testing.T) {
 		t.Fatal("expected error")
 	}
 }
+
+func TestValidatorExpire(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	v := validator.New(validator.All(), 10*time.Minute, func() time.Time {
+		return now
+	})
+
+	err := v.AddClient(ctx, "1", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = v.AddClient(ctx, "2", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = v.ReadyClient(ctx, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(9 * time.Minute)
+
+	err = v.ReadyClient(ctx, "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isReady := v.IsReady(ctx)
+	if !isReady {
+		t.Fatal("expected ready")
+	}
+
+	now = now.Add(time.Minute)
+
+	isReady = v.IsReady(ctx)
+	if isReady {
+		t.Fatal("expected not ready, vote expired")
+	}
+
+	// expired votes are unset once, so clients see them as not ready
+	assert.Equal(t, []string{"1"}, v.Expire(ctx))
+	assert.Empty(t, v.Expire(ctx))
+
+	err = v.ReadyClient(ctx, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isReady = v.IsReady(ctx)
+	if !isReady {
+		t.Fatal("expected ready")
+	}
+}
+
+func TestValidatorPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	v := validator.New(validator.Owner([]string{"alice"}), 0, time.Now)
+
+	err := v.AddClient(ctx, "1", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// client editing file alone approves its own save with any policy
+	isReady := v.IsReady(ctx)
+	if !isReady {
+		t.Fatal("expected ready, client alone")
+	}
+
+	err = v.AddClient(ctx, "2", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isReady = v.IsReady(ctx)
+	if isReady {
+		t.Fatal("expected not ready, owner not ready")
+	}
+
+	err = v.ReadyClient(ctx, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isReady = v.IsReady(ctx)
+	if isReady {
+		t.Fatal("expected not ready, only not owner ready")
+	}
+
+	err = v.ReadyClient(ctx, "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isReady = v.IsReady(ctx)
+	if !isReady {
+		t.Fatal("expected ready")
+	}
+}
@@ -0,0 +1,100 @@
+// Package atomicfile writes files so readers see either old or new
+// contents, never partly written file
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/fakovacic/editor/internal/errors"
+)
+
+// WriteFile writes data to temp file in same directory, syncs it and
+// renames it over name. Mode and ownership of existing file are kept,
+// new file is created with perm. Symlink is followed and its target
+// replaced.
+func WriteFile(name string, data []byte, perm os.FileMode) error {
+	target, err := filepath.EvalSymlinks(name)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return errors.Wrap(err, "resolve file")
+		}
+
+		target = name
+	}
+
+	info, err := os.Stat(target)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "stat file")
+	}
+
+	if info != nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(target)
+
+	// temp file is hidden so it is not listed while written
+	f, err := os.CreateTemp(dir, "."+filepath.Base(target)+".tmp-*")
+	if err != nil {
+		return errors.Wrap(err, "create temp file")
+	}
+
+	tmpName := f.Name()
+
+	err = write(f, data, perm, info)
+	if err != nil {
+		_ = os.Remove(tmpName)
+
+		return err
+	}
+
+	err = os.Rename(tmpName, target)
+	if err != nil {
+		_ = os.Remove(tmpName)
+
+		return errors.Wrap(err, "rename temp file")
+	}
+
+	return syncDir(dir)
+}
+
+// write writes and syncs temp file, file is closed in any case
+func write(f *os.File, data []byte, perm os.FileMode, info os.FileInfo) error {
+	_, err := f.Write(data)
+	if err != nil {
+		_ = f.Close()
+
+		return errors.Wrap(err, "write temp file")
+	}
+
+	err = f.Chmod(perm)
+	if err != nil {
+		_ = f.Close()
+
+		return errors.Wrap(err, "chmod temp file")
+	}
+
+	if info != nil {
+		err = chown(f, info)
+		if err != nil {
+			_ = f.Close()
+
+			return errors.Wrap(err, "chown temp file")
+		}
+	}
+
+	err = f.Sync()
+	if err != nil {
+		_ = f.Close()
+
+		return errors.Wrap(err, "sync temp file")
+	}
+
+	err = f.Close()
+	if err != nil {
+		return errors.Wrap(err, "close temp file")
+	}
+
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package atomicfile
+
+import "os"
+
+// chown is not supported, file keeps owner of process
+func chown(_ *os.File, _ os.FileInfo) error {
+	return nil
+}
+
+// syncDir is not supported, directories can not be synced
+func syncDir(_ string) error {
+	return nil
+}
@@ -0,0 +1,128 @@
+package atomicfile_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fakovacic/editor/internal/atomicfile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteFile(t *testing.T) {
+	cases := []struct {
+		it string
+
+		setup func(dir string) error
+		name  string
+
+		expectedFiles   map[string]string
+		expectedMode    os.FileMode
+		expectedSymlink bool
+		expectedError   string
+	}{
+		{
+			it:   "new file",
+			name: "main.css",
+
+			expectedFiles: map[string]string{
+				"main.css": "b {}",
+			},
+			expectedMode: 0644,
+		},
+		{
+			it: "existing file mode kept",
+			setup: func(dir string) error {
+				return os.WriteFile(filepath.Join(dir, "main.css"), []byte("a {} a {}"), 0600)
+			},
+			name: "main.css",
+
+			expectedFiles: map[string]string{
+				"main.css": "b {}",
+			},
+			expectedMode: 0600,
+		},
+		{
+			it: "symlink target replaced",
+			setup: func(dir string) error {
+				err := os.WriteFile(filepath.Join(dir, "target.css"), []byte("a {}"), 0640)
+				if err != nil {
+					return err
+				}
+
+				return os.Symlink("target.css", filepath.Join(dir, "main.css"))
+			},
+			name: "main.css",
+
+			expectedFiles: map[string]string{
+				"main.css":   "b {}",
+				"target.css": "b {}",
+			},
+			expectedMode:    0640,
+			expectedSymlink: true,
+		},
+		{
+			it:   "missing dir",
+			name: "css/main.css",
+
+			expectedFiles: map[string]string{},
+			expectedError: "create temp file",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			dir := t.TempDir()
+
+			if tc.setup != nil {
+				err := tc.setup(dir)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := atomicfile.WriteFile(filepath.Join(dir, tc.name), []byte("b {}"), 0644)
+			if err != nil {
+				assert.Contains(t, err.Error(), tc.expectedError)
+			}
+
+			// temp file is not left behind
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			files := make(map[string]string)
+
+			for _, entry := range entries {
+				contents, readErr := os.ReadFile(filepath.Join(dir, entry.Name()))
+				if readErr != nil {
+					t.Fatal(readErr)
+				}
+
+				files[entry.Name()] = string(contents)
+			}
+
+			assert.Equal(t, tc.expectedFiles, files)
+
+			if tc.expectedError != "" {
+				return
+			}
+
+			info, err := os.Stat(filepath.Join(dir, tc.name))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.expectedMode, info.Mode().Perm())
+
+			// symlink is kept
+			linkInfo, err := os.Lstat(filepath.Join(dir, tc.name))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.expectedSymlink, linkInfo.Mode()&os.ModeSymlink != 0)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+//go:build unix
+
+package atomicfile
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/fakovacic/editor/internal/errors"
+)
+
+// chown sets owner of file to owner of info, process not allowed
+// to give file away keeps its own ownership
+func chown(f *os.File, info os.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+
+	err := f.Chown(int(stat.Uid), int(stat.Gid))
+	if err != nil && !os.IsPermission(err) {
+		return err
+	}
+
+	return nil
+}
+
+// syncDir syncs directory so rename survives crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return errors.Wrap(err, "open dir")
+	}
+
+	err = d.Sync()
+	if err != nil {
+		_ = d.Close()
+
+		return errors.Wrap(err, "sync dir")
+	}
+
+	err = d.Close()
+	if err != nil {
+		return errors.Wrap(err, "close dir")
+	}
+
+	return nil
+}
@@ -13,6 +13,7 @@ const (
 	UnauthorizedCode     = 102
 	MethodNotAllowedCode = 103
 	InternalCode         = 104
+	ConflictCode         = 105
 )
 
 type Error struct {
@@ -40,6 +41,8 @@ func (t Error) HTTPStatusCode() int {
 		return http.StatusMethodNotAllowed
 	case InternalCode:
 		return http.StatusInternalServerError
+	case ConflictCode:
+		return http.StatusConflict
 	default:
 		return http.StatusInternalServerError
 	}
@@ -136,3 +139,31 @@ func MethodNotAllowedWrap(err error, format string, args ...interface{}) error {
 		Err:  errors.Wrapf(err, format, args...),
 	}
 }
+
+func Conflict(format string, args ...interface{}) error {
+	return Error{
+		Code: ConflictCode,
+		Err:  errors.Errorf(format, args...),
+	}
+}
+
+// IsBadRequest reports if err or error it wraps is bad request
+func IsBadRequest(err error) bool {
+	var e Error
+
+	return goErrors.As(err, &e) && e.GetCode() == BadRequestCode
+}
+
+// IsConflict reports if err or error it wraps is conflict
+func IsConflict(err error) bool {
+	var e Error
+
+	return goErrors.As(err, &e) && e.GetCode() == ConflictCode
+}
+
+// IsNotFound reports if err or error it wraps is not found
+func IsNotFound(err error) bool {
+	var e Error
+
+	return goErrors.As(err, &e) && e.GetCode() == NotFoundCode
+}
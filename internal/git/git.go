@@ -0,0 +1,226 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fakovacic/editor/internal/errors"
+	"github.com/fakovacic/editor/internal/log"
+)
+
+// Config of repository, commits are pushed to Remote if set, Committer
+// is name of editor in commits and Domain is host of author emails
+type Config struct {
+	Remote    string
+	Committer string
+	Domain    string
+}
+
+const (
+	defaultCommitter = "editor"
+	defaultDomain    = "editor.local"
+)
+
+// Repo is git working tree, commands are run with git binary one at time
+type Repo struct {
+	dir    string
+	config Config
+	mu     sync.Mutex
+}
+
+// Open returns repository of working tree dir is in
+func Open(ctx context.Context, dir string, config Config) (*Repo, error) {
+	if config.Committer == "" {
+		config.Committer = defaultCommitter
+	}
+
+	if config.Domain == "" {
+		config.Domain = defaultDomain
+	}
+
+	r := &Repo{
+		config: config,
+	}
+
+	top, err := r.run(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, errors.BadRequestWrap(err, "'%s' not in git working tree", dir)
+	}
+
+	r.dir = strings.TrimSpace(top)
+
+	return r, nil
+}
+
+// Commit commits current state of paths with message, first author is
+// author of commit and others are co-authors, committer is author if
+// there are no authors. Nothing is committed if paths are not changed.
+func (r *Repo) Commit(ctx context.Context, message string, authors []string, paths ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	args := append([]string{"add", "--all", "--"}, paths...)
+
+	_, err := r.run(ctx, r.dir, args...)
+	if err != nil {
+		return err
+	}
+
+	args = append([]string{"diff", "--cached", "--quiet", "--"}, paths...)
+
+	_, err = r.run(ctx, r.dir, args...)
+	if err == nil {
+		return nil
+	}
+
+	if len(authors) > 1 {
+		message += "\n"
+
+		for _, author := range authors[1:] {
+			message += "\nCo-authored-by: " + r.signature(author)
+		}
+	}
+
+	args = []string{"commit", "--quiet", "--message", message}
+
+	if len(authors) > 0 {
+		args = append(args, "--author", r.signature(authors[0]))
+	}
+
+	args = append(args, "--")
+	args = append(args, paths...)
+
+	_, err = r.run(ctx, r.dir, args...)
+	if err != nil {
+		return err
+	}
+
+	if r.config.Remote == "" {
+		return nil
+	}
+
+	// commit is kept if push fails, it is pushed with next one
+	_, err = r.run(ctx, r.dir, "push", "--quiet", r.config.Remote, "HEAD")
+	if err != nil {
+		log.Error(ctx, "git push", log.Err(err))
+	}
+
+	return nil
+}
+
+// Revision is commit which changed file, Size is size of file in it
+type Revision struct {
+	Hash string
+	Time time.Time
+	Size int64
+}
+
+// Log returns revisions of file at path, newest first, revisions in
+// which file was deleted are skipped
+func (r *Repo) Log(ctx context.Context, path string) ([]Revision, error) {
+	dir, name := filepath.Split(path)
+
+	out, err := r.run(ctx, dir, "log", "--format=%H %ct", "--", name)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]Revision, 0)
+
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		hash, ct, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+
+		unix, parseErr := strconv.ParseInt(ct, 10, 64)
+		if parseErr != nil {
+			continue
+		}
+
+		size, sizeErr := r.run(ctx, dir, "cat-file", "-s", object(hash, name))
+		if sizeErr != nil {
+			continue
+		}
+
+		revision := Revision{
+			Hash: hash,
+			Time: time.Unix(unix, 0).UTC(),
+		}
+
+		revision.Size, _ = strconv.ParseInt(strings.TrimSpace(size), 10, 64)
+
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, nil
+}
+
+// Show returns contents of file at path in revision
+func (r *Repo) Show(ctx context.Context, revision, path string) (string, error) {
+	dir, name := filepath.Split(path)
+
+	_, err := r.run(ctx, dir, "cat-file", "-e", object(revision, name))
+	if err != nil {
+		return "", errors.NotFound("revision '%s' of '%s' not found", revision, name)
+	}
+
+	return r.run(ctx, dir, "cat-file", "-p", object(revision, name))
+}
+
+// object returns name of file in revision, relative to dir command is run in
+func object(revision, name string) string {
+	return revision + ":./" + name
+}
+
+// signature returns name and email of username,
+// email is username with characters not allowed replaced
+func (r *Repo) signature(username string) string {
+	local := strings.Map(func(c rune) rune {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '.', c == '_', c == '-':
+			return c
+		default:
+			return '-'
+		}
+	}, username)
+
+	name := strings.NewReplacer("<", "", ">", "").Replace(username)
+
+	return fmt.Sprintf("%s <%s@%s>", name, local, r.config.Domain)
+}
+
+func (r *Repo) run(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// commits do not depend on git config of host
+	email := fmt.Sprintf("%s@%s", r.config.Committer, r.config.Domain)
+
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+r.config.Committer,
+		"GIT_AUTHOR_EMAIL="+email,
+		"GIT_COMMITTER_NAME="+r.config.Committer,
+		"GIT_COMMITTER_EMAIL="+email,
+		"GIT_TERMINAL_PROMPT=0",
+	)
+
+	err := cmd.Run()
+	if err != nil {
+		return "", errors.Wrap(err, "git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
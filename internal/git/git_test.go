@@ -0,0 +1,150 @@
+package git_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fakovacic/editor/internal/git"
+	"github.com/stretchr/testify/assert"
+)
+
+// run runs git command in dir and returns trimmed output
+func run(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v %s", args, err, out)
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+func TestCommit(t *testing.T) {
+	_, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not installed")
+	}
+
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	remote := t.TempDir()
+
+	run(t, dir, "init", "--quiet")
+	run(t, remote, "init", "--quiet", "--bare")
+
+	repo, err := git.Open(ctx, dir, git.Config{
+		Remote: remote,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "main.css")
+
+	err = os.WriteFile(path, []byte("a {}"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "other.css"), []byte("b {}"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// authors and co-authors
+	err = repo.Commit(ctx, "Update main.css", []string{"alice", "bob <smith>"}, path)
+	assert.NoError(t, err)
+
+	assert.Equal(t,
+		"alice <alice@editor.local>|editor <editor@editor.local>|Update main.css\n\nCo-authored-by: bob smith <bob--smith-@editor.local>",
+		run(t, dir, "log", "-1", "--format=%an <%ae>|%cn <%ce>|%B"),
+	)
+
+	// only paths are committed
+	assert.Equal(t, "main.css", run(t, dir, "show", "--name-only", "--format=", "HEAD"))
+
+	// pushed to remote
+	assert.Equal(t, run(t, dir, "rev-parse", "HEAD"), run(t, remote, "rev-parse", "HEAD"))
+
+	// unchanged paths are not committed
+	err = repo.Commit(ctx, "Update main.css", []string{"alice"}, path)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "1", run(t, dir, "rev-list", "--count", "HEAD"))
+
+	// committer is author without authors
+	err = os.Remove(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.Commit(ctx, "Delete main.css", nil, path)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "editor|Delete main.css", run(t, dir, "log", "-1", "--format=%an|%s"))
+	assert.Equal(t, "2", run(t, remote, "rev-list", "--count", "HEAD"))
+}
+
+func TestLog(t *testing.T) {
+	_, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not installed")
+	}
+
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	run(t, dir, "init", "--quiet")
+
+	repo, err := git.Open(ctx, dir, git.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "main.css")
+
+	for _, contents := range []string{"a {}", "b {} b {}"} {
+		err = os.WriteFile(path, []byte(contents), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = repo.Commit(ctx, "Update main.css", nil, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	revisions, err := repo.Log(ctx, path)
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 2)
+
+	assert.Equal(t, run(t, dir, "rev-parse", "HEAD"), revisions[0].Hash)
+	assert.Equal(t, int64(9), revisions[0].Size)
+	assert.Equal(t, int64(4), revisions[1].Size)
+
+	contents, err := repo.Show(ctx, revisions[1].Hash, path)
+	assert.NoError(t, err)
+	assert.Equal(t, "a {}", contents)
+
+	_, err = repo.Show(ctx, revisions[1].Hash, filepath.Join(dir, "other.css"))
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestOpenNotRepository(t *testing.T) {
+	_, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+
+	_, err = git.Open(context.Background(), dir, git.Config{})
+	assert.Contains(t, err.Error(), "not in git working tree")
+}
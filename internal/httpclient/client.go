@@ -0,0 +1,197 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/fakovacic/editor/internal/errors"
+)
+
+// Client sends requests with configured auth, idempotent requests are
+// retried with backoff when server is unavailable
+type Client interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Config of client, Token is sent as bearer token, Username and Password
+// as basic auth, CertFile and KeyFile are client certificate and CAFile
+// is bundle server certificate is verified with
+type Config struct {
+	Token    string
+	Username string
+	Password string
+
+	CertFile string
+	KeyFile  string
+	CAFile   string
+
+	Timeout time.Duration
+	Retries int
+	Backoff time.Duration // delay before first retry, doubled on every next
+}
+
+func New(config Config) (Client, error) {
+	if config.Token != "" && config.Username != "" {
+		return nil, errors.BadRequest("token and basic auth both set")
+	}
+
+	if (config.CertFile == "") != (config.KeyFile == "") {
+		return nil, errors.BadRequest("client certificate and key must be set together")
+	}
+
+	if config.Retries < 0 {
+		return nil, errors.BadRequest("retries %d not valid", config.Retries)
+	}
+
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("default transport not supported")
+	}
+
+	transport = transport.Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &client{
+		http: &http.Client{
+			Transport: transport,
+			Timeout:   config.Timeout,
+		},
+		config: config,
+	}, nil
+}
+
+func newTLSConfig(config Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if config.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "load client certificate")
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if config.CAFile != "" {
+		bundle, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "read ca bundle")
+		}
+
+		pool := x509.NewCertPool()
+
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, errors.BadRequest("ca bundle '%s' has no certificates", config.CAFile)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+type client struct {
+	http   *http.Client
+	config Config
+}
+
+func (c *client) Do(req *http.Request) (*http.Response, error) {
+	// auth set on request, as from configured headers, is kept
+	if req.Header.Get("Authorization") == "" {
+		switch {
+		case c.config.Token != "":
+			req.Header.Set("Authorization", "Bearer "+c.config.Token)
+		case c.config.Username != "":
+			req.SetBasicAuth(c.config.Username, c.config.Password)
+		}
+	}
+
+	retries := 0
+	if retryable(req) {
+		retries = c.config.Retries
+	}
+
+	delay := c.config.Backoff
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.http.Do(req)
+		if attempt == retries || req.Context().Err() != nil || !temporary(resp, err) {
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		err = wait(req.Context(), delay)
+		if err != nil {
+			return nil, err
+		}
+
+		delay *= 2
+
+		if req.GetBody != nil {
+			req.Body, err = req.GetBody()
+			if err != nil {
+				return nil, errors.Wrap(err, "request body")
+			}
+		}
+	}
+}
+
+// retryable reports if request is idempotent and its body can be sent again,
+// conditional write could be applied before its response was lost and its
+// retry would fail precondition, so it is not retried
+func retryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	case http.MethodPut, http.MethodDelete:
+		if req.Header.Get("If-Match") != "" || req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Unmodified-Since") != "" {
+			return false
+		}
+	default:
+		return false
+	}
+
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// temporary reports if request failed and may succeed later,
+// client timeout of attempt included
+func temporary(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,363 @@
+package httpclient_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fakovacic/editor/internal/httpclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient(t *testing.T) {
+	var (
+		requests []string
+		mu       sync.Mutex
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.Header.Get("Authorization")+" "+string(body))
+		count := len(requests)
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/flaky":
+			if count < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+			}
+		case "/slow":
+			time.Sleep(100 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		it string
+
+		config  httpclient.Config
+		method  string
+		path    string
+		body    string
+		header  string
+		ifMatch string
+
+		expectedStatus   int
+		expectedRequests []string
+		expectedError    string
+	}{
+		{
+			it:     "bearer token",
+			config: httpclient.Config{Token: "secret"},
+			method: http.MethodGet,
+
+			expectedStatus:   http.StatusOK,
+			expectedRequests: []string{"GET Bearer secret "},
+		},
+		{
+			it:     "basic auth",
+			config: httpclient.Config{Username: "user", Password: "pass"},
+			method: http.MethodGet,
+
+			expectedStatus:   http.StatusOK,
+			expectedRequests: []string{"GET Basic dXNlcjpwYXNz "},
+		},
+		{
+			it:     "request auth kept",
+			config: httpclient.Config{Token: "secret"},
+			method: http.MethodGet,
+			header: "Bearer other",
+
+			expectedStatus:   http.StatusOK,
+			expectedRequests: []string{"GET Bearer other "},
+		},
+		{
+			it:     "idempotent request retried with body",
+			config: httpclient.Config{Retries: 3, Backoff: time.Millisecond},
+			method: http.MethodPut,
+			path:   "/flaky",
+			body:   "a {}",
+
+			expectedStatus:   http.StatusOK,
+			expectedRequests: []string{"PUT  a {}", "PUT  a {}", "PUT  a {}"},
+		},
+		{
+			it:     "retries exhausted",
+			config: httpclient.Config{Retries: 1, Backoff: time.Millisecond},
+			method: http.MethodGet,
+			path:   "/unavailable",
+
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedRequests: []string{"GET  ", "GET  "},
+		},
+		{
+			it:     "post not retried",
+			config: httpclient.Config{Retries: 3, Backoff: time.Millisecond},
+			method: http.MethodPost,
+			path:   "/unavailable",
+			body:   "a {}",
+
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedRequests: []string{"POST  a {}"},
+		},
+		{
+			it:      "conditional write not retried",
+			config:  httpclient.Config{Retries: 3, Backoff: time.Millisecond},
+			method:  http.MethodPut,
+			path:    "/unavailable",
+			body:    "a {}",
+			ifMatch: `"v1"`,
+
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedRequests: []string{"PUT  a {}"},
+		},
+		{
+			it:     "timeout",
+			config: httpclient.Config{Timeout: 20 * time.Millisecond},
+			method: http.MethodGet,
+			path:   "/slow",
+
+			expectedRequests: []string{"GET  "},
+			expectedError:    "Client.Timeout exceeded",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			mu.Lock()
+			requests = nil
+			mu.Unlock()
+
+			client, err := httpclient.New(tc.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := http.NewRequest(tc.method, srv.URL+tc.path, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				assert.Contains(t, err.Error(), tc.expectedError)
+			} else {
+				resp.Body.Close()
+
+				assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			assert.Equal(t, tc.expectedRequests, requests)
+		})
+	}
+}
+
+func TestClientTLS(t *testing.T) {
+	dir := t.TempDir()
+
+	caCert, caKey := newCertificate(t, nil, nil)
+	clientCert, clientKey := newCertificate(t, caCert, caKey)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(caCert)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	srv.TLS = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	writePEM(t, filepath.Join(dir, "ca.pem"), "CERTIFICATE", srv.Certificate().Raw)
+	writePEM(t, filepath.Join(dir, "client.pem"), "CERTIFICATE", clientCert.Raw)
+
+	keyDER, err := x509.MarshalECPrivateKey(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writePEM(t, filepath.Join(dir, "client-key.pem"), "EC PRIVATE KEY", keyDER)
+
+	cases := []struct {
+		it string
+
+		config httpclient.Config
+
+		expectedResponse string
+		expectedError    string
+	}{
+		{
+			it: "client certificate",
+			config: httpclient.Config{
+				CertFile: filepath.Join(dir, "client.pem"),
+				KeyFile:  filepath.Join(dir, "client-key.pem"),
+				CAFile:   filepath.Join(dir, "ca.pem"),
+			},
+			expectedResponse: "editor",
+		},
+		{
+			it: "without client certificate",
+			config: httpclient.Config{
+				CAFile: filepath.Join(dir, "ca.pem"),
+			},
+			expectedError: "certificate required",
+		},
+		{
+			it:            "unknown server",
+			config:        httpclient.Config{},
+			expectedError: "certificate signed by unknown authority",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			client, err := httpclient.New(tc.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL, http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				assert.Contains(t, err.Error(), tc.expectedError)
+
+				return
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				// tls 1.3 reports missing client certificate on read
+				assert.Contains(t, err.Error(), tc.expectedError)
+
+				return
+			}
+
+			assert.Equal(t, tc.expectedResponse, string(body))
+		})
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	cases := []struct {
+		it string
+
+		config httpclient.Config
+
+		expectedError string
+	}{
+		{
+			it:            "token and basic auth",
+			config:        httpclient.Config{Token: "secret", Username: "user"},
+			expectedError: "token and basic auth both set",
+		},
+		{
+			it:            "certificate without key",
+			config:        httpclient.Config{CertFile: "client.pem"},
+			expectedError: "client certificate and key must be set together",
+		},
+		{
+			it:            "missing ca bundle",
+			config:        httpclient.Config{CAFile: "missing.pem"},
+			expectedError: "read ca bundle: open missing.pem: no such file or directory",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			_, err := httpclient.New(tc.config)
+
+			assert.Equal(t, tc.expectedError, err.Error())
+		})
+	}
+}
+
+// newCertificate returns ca certificate if parent is nil,
+// otherwise client certificate signed by parent
+func newCertificate(t *testing.T, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ca"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	if parent == nil {
+		parent, parentKey = template, key
+	} else {
+		template.SerialNumber = big.NewInt(2)
+		template.Subject = pkix.Name{CommonName: "editor"}
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		template.IsCA = false
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert, key
+}
+
+func writePEM(t *testing.T, name, blockType string, der []byte) {
+	t.Helper()
+
+	err := os.WriteFile(name, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
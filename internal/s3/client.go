@@ -0,0 +1,353 @@
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fakovacic/editor/internal/errors"
+)
+
+type httpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client reads and writes objects of one bucket
+type Client interface {
+	// Get returns object with body
+	Get(context.Context, string) (*Object, error)
+
+	// Head returns object without body
+	Head(context.Context, string) (*Object, error)
+
+	// Put writes object, conflict is returned if condition is not met
+	Put(context.Context, string, string, Condition) (*Object, error)
+
+	// Copy copies object to new key
+	Copy(context.Context, string, string) error
+
+	// Delete deletes object, missing object is not an error
+	Delete(context.Context, string) error
+
+	// List returns objects with key prefix, sorted by key
+	List(context.Context, string) ([]Object, error)
+}
+
+// Object stored in bucket, Body is set only when object is read
+type Object struct {
+	Key          string
+	ETag         string
+	Size         int64
+	LastModified time.Time
+	Body         string
+}
+
+// Condition of write, IfMatch is etag object must have,
+// IfNoneMatch "*" writes only if object does not exist
+type Condition struct {
+	IfMatch     string
+	IfNoneMatch string
+}
+
+// Config of bucket, Endpoint defaults to aws endpoint of region,
+// objects are addressed by path as endpoint/bucket/key
+type Config struct {
+	Endpoint     string
+	Region       string
+	Bucket       string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+}
+
+const defaultRegion = "us-east-1"
+
+// New returns client of config bucket, requests are signed with
+// config credentials and sent with client
+func New(config Config, client httpClient, timeFunc func() time.Time) (Client, error) {
+	if config.Bucket == "" {
+		return nil, errors.BadRequest("bucket not set")
+	}
+
+	if config.AccessKey == "" || config.SecretKey == "" {
+		return nil, errors.BadRequest("access key and secret key must be set")
+	}
+
+	if config.Region == "" {
+		config.Region = defaultRegion
+	}
+
+	if config.Endpoint == "" {
+		config.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", config.Region)
+	}
+
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, errors.BadRequest("endpoint '%s' not valid", config.Endpoint)
+	}
+
+	return &s3Client{
+		endpoint: endpoint,
+		bucket:   config.Bucket,
+		signer: Signer{
+			AccessKey:    config.AccessKey,
+			SecretKey:    config.SecretKey,
+			SessionToken: config.SessionToken,
+			Region:       config.Region,
+			Service:      "s3",
+		},
+		client:   client,
+		timeFunc: timeFunc,
+	}, nil
+}
+
+// ParseURL returns bucket and key of url as s3://bucket/key
+func ParseURL(rawURL string) (string, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "s3" || u.Host == "" {
+		return "", "", errors.BadRequest("s3 url '%s' not valid", rawURL)
+	}
+
+	return u.Host, strings.TrimPrefix(u.Path, "/"), nil
+}
+
+type s3Client struct {
+	endpoint *url.URL
+	bucket   string
+	signer   Signer
+	client   httpClient
+	timeFunc func() time.Time
+}
+
+// objectURL returns url of key, bucket url if key is empty
+func (c *s3Client) objectURL(key string) *url.URL {
+	u := *c.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + c.bucket
+
+	if key != "" {
+		u.Path += "/" + key
+	}
+
+	u.RawPath = ""
+
+	return &u
+}
+
+// do sends signed request, error response is returned as error
+func (c *s3Client) do(ctx context.Context, method string, target *url.URL, header http.Header, body string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), strings.NewReader(body))
+	if err != nil {
+		return nil, errors.New("create http request: %v", err)
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	c.signer.Sign(req, PayloadHash([]byte(body)), c.timeFunc())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, errors.New("http request: %v", err)
+	}
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+
+	var respErr responseError
+
+	// head responses have no body
+	_ = xml.NewDecoder(resp.Body).Decode(&respErr)
+
+	key := strings.TrimPrefix(target.Path, c.objectURL("").Path+"/")
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return nil, errors.NotFound("object '%s' not found", key)
+	case http.StatusPreconditionFailed:
+		return nil, errors.Conflict("object '%s' precondition failed", key)
+	default:
+		return nil, errors.New("status code not success: %v %s", resp.StatusCode, respErr.Code)
+	}
+}
+
+// responseError is error response body
+type responseError struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string   `xml:"Code"`
+	Message string   `xml:"Message"`
+}
+
+// object returns object of response headers
+func object(key string, header http.Header) *Object {
+	obj := &Object{
+		Key:  key,
+		ETag: header.Get("ETag"),
+	}
+
+	modTime, err := http.ParseTime(header.Get("Last-Modified"))
+	if err == nil {
+		obj.LastModified = modTime
+	}
+
+	return obj
+}
+
+func (c *s3Client) Get(ctx context.Context, key string) (*Object, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.objectURL(key), nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.New("read body response: %v", err)
+	}
+
+	obj := object(key, resp.Header)
+	obj.Body = string(body)
+	obj.Size = int64(len(body))
+
+	return obj, nil
+}
+
+func (c *s3Client) Head(ctx context.Context, key string) (*Object, error) {
+	resp, err := c.do(ctx, http.MethodHead, c.objectURL(key), nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	obj := object(key, resp.Header)
+	obj.Size = resp.ContentLength
+
+	return obj, nil
+}
+
+func (c *s3Client) Put(ctx context.Context, key, body string, condition Condition) (*Object, error) {
+	header := make(http.Header)
+
+	if condition.IfMatch != "" {
+		header.Set("If-Match", condition.IfMatch)
+	}
+
+	if condition.IfNoneMatch != "" {
+		header.Set("If-None-Match", condition.IfNoneMatch)
+	}
+
+	resp, err := c.do(ctx, http.MethodPut, c.objectURL(key), header, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	obj := object(key, resp.Header)
+	obj.Size = int64(len(body))
+
+	return obj, nil
+}
+
+func (c *s3Client) Copy(ctx context.Context, key, newKey string) error {
+	header := make(http.Header)
+	header.Set("X-Amz-Copy-Source", c.objectURL(key).EscapedPath())
+
+	resp, err := c.do(ctx, http.MethodPut, c.objectURL(newKey), header, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// copy can fail after 200 is sent, error is in body
+	var respErr responseError
+
+	decodeErr := xml.NewDecoder(resp.Body).Decode(&respErr)
+	if decodeErr == nil {
+		return errors.New("copy object '%s': %s %s", key, respErr.Code, respErr.Message)
+	}
+
+	return nil
+}
+
+func (c *s3Client) Delete(ctx context.Context, key string) error {
+	resp, err := c.do(ctx, http.MethodDelete, c.objectURL(key), nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return nil
+}
+
+// listResult is response of list objects v2
+type listResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		ETag         string    `xml:"ETag"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (c *s3Client) List(ctx context.Context, prefix string) ([]Object, error) {
+	objects := make([]Object, 0)
+
+	var token string
+
+	for {
+		target := c.objectURL("")
+
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		target.RawQuery = query.Encode()
+
+		resp, err := c.do(ctx, http.MethodGet, target, nil, "")
+		if err != nil {
+			return nil, err
+		}
+
+		var result listResult
+
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+
+		if err != nil {
+			return nil, errors.New("decode list objects: %v", err)
+		}
+
+		for _, content := range result.Contents {
+			objects = append(objects, Object{
+				Key:          content.Key,
+				ETag:         content.ETag,
+				Size:         content.Size,
+				LastModified: content.LastModified,
+			})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+
+		token = result.NextContinuationToken
+	}
+}
@@ -0,0 +1,147 @@
+package s3_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fakovacic/editor/internal/s3"
+	"github.com/fakovacic/editor/internal/s3/s3test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient(t *testing.T) {
+	srv := s3test.NewServer()
+	defer srv.Close()
+
+	srv.PageSize = 2
+
+	client, err := s3.New(srv.Config(), http.DefaultClient, time.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	// put, get and head
+	obj, err := client.Put(ctx, "css/main style.css", "a {}", s3.Condition{IfNoneMatch: "*"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, obj.ETag)
+
+	_, err = client.Put(ctx, "css/main style.css", "b {}", s3.Condition{IfNoneMatch: "*"})
+	assert.Equal(t, "object 'css/main style.css' precondition failed", err.Error())
+
+	read, err := client.Get(ctx, "css/main style.css")
+	assert.NoError(t, err)
+	assert.Equal(t, "a {}", read.Body)
+	assert.Equal(t, obj.ETag, read.ETag)
+
+	head, err := client.Head(ctx, "css/main style.css")
+	assert.NoError(t, err)
+	assert.Equal(t, obj.ETag, head.ETag)
+	assert.Equal(t, int64(4), head.Size)
+
+	_, err = client.Get(ctx, "missing.css")
+	assert.Equal(t, "object 'missing.css' not found", err.Error())
+
+	// conditional write
+	srv.SetObject("css/main style.css", "c {}")
+
+	_, err = client.Put(ctx, "css/main style.css", "d {}", s3.Condition{IfMatch: obj.ETag})
+	assert.Equal(t, "object 'css/main style.css' precondition failed", err.Error())
+
+	body, _ := srv.Object("css/main style.css")
+	assert.Equal(t, "c {}", body)
+
+	// copy and delete
+	err = client.Copy(ctx, "css/main style.css", "css/copy.css")
+	assert.NoError(t, err)
+
+	body, _ = srv.Object("css/copy.css")
+	assert.Equal(t, "c {}", body)
+
+	err = client.Copy(ctx, "missing.css", "css/other.css")
+	assert.Equal(t, "object 'css/other.css' not found", err.Error())
+
+	err = client.Delete(ctx, "css/copy.css")
+	assert.NoError(t, err)
+
+	_, ok := srv.Object("css/copy.css")
+	assert.False(t, ok)
+
+	// list pages
+	srv.SetObject("css/b.css", "")
+	srv.SetObject("css/c.css", "")
+	srv.SetObject("js/app.js", "")
+
+	objects, err := client.List(ctx, "css/")
+	assert.NoError(t, err)
+
+	keys := make([]string, 0, len(objects))
+	for _, o := range objects {
+		keys = append(keys, o.Key)
+	}
+
+	assert.Equal(t, []string{"css/b.css", "css/c.css", "css/main style.css"}, keys)
+}
+
+func TestClientSignature(t *testing.T) {
+	srv := s3test.NewServer()
+	defer srv.Close()
+
+	config := srv.Config()
+	config.SecretKey = "wrong"
+
+	client, err := s3.New(config, http.DefaultClient, time.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.Get(context.Background(), "main.css")
+	assert.Equal(t, "status code not success: 403 SignatureDoesNotMatch", err.Error())
+}
+
+func TestParseURL(t *testing.T) {
+	cases := []struct {
+		it string
+
+		url string
+
+		expectedBucket string
+		expectedKey    string
+		expectedError  string
+	}{
+		{
+			it:             "object",
+			url:            "s3://assets/css/main.css",
+			expectedBucket: "assets",
+			expectedKey:    "css/main.css",
+		},
+		{
+			it:             "prefix",
+			url:            "s3://assets/css/",
+			expectedBucket: "assets",
+			expectedKey:    "css/",
+		},
+		{
+			it:            "not s3",
+			url:           "https://assets/main.css",
+			expectedError: "s3 url 'https://assets/main.css' not valid",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.it, func(t *testing.T) {
+			bucket, key, err := s3.ParseURL(tc.url)
+			if err != nil {
+				assert.Equal(t, tc.expectedError, err.Error())
+
+				return
+			}
+
+			assert.Equal(t, tc.expectedBucket, bucket)
+			assert.Equal(t, tc.expectedKey, key)
+		})
+	}
+}
@@ -0,0 +1,323 @@
+// Package s3test provides in-process fake of s3 compatible server
+package s3test
+
+import (
+	"crypto/md5" //nolint:gosec // etag of object as s3 computes it
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fakovacic/editor/internal/s3"
+)
+
+const (
+	Bucket    = "editor"
+	Region    = "us-east-1"
+	AccessKey = "access"
+	SecretKey = "secret"
+)
+
+// Server is fake of one bucket, supports get, head, put with
+// conditions, copy, delete and list objects v2, every request
+// signature is verified
+type Server struct {
+	*httptest.Server
+
+	// PageSize is max keys of list page, defaults to 1000
+	PageSize int
+
+	objects map[string]object
+	mu      sync.Mutex
+}
+
+type object struct {
+	body         string
+	etag         string
+	lastModified time.Time
+}
+
+// NewServer returns started server, it should be closed after use
+func NewServer() *Server {
+	s := &Server{
+		PageSize: 1000,
+		objects:  make(map[string]object),
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+
+	return s
+}
+
+// Config returns client config of server bucket
+func (s *Server) Config() s3.Config {
+	return s3.Config{
+		Endpoint:  s.URL,
+		Region:    Region,
+		Bucket:    Bucket,
+		AccessKey: AccessKey,
+		SecretKey: SecretKey,
+	}
+}
+
+// Object returns body of object
+func (s *Server) Object(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj, ok := s.objects[key]
+
+	return obj.body, ok
+}
+
+// Keys returns keys of objects, sorted
+func (s *Server) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// SetObject writes object as other client would
+func (s *Server) SetObject(key, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.put(key, body)
+}
+
+func (s *Server) put(key, body string) object {
+	hash := md5.Sum([]byte(body)) //nolint:gosec // etag of object as s3 computes it
+
+	obj := object{
+		body:         body,
+		etag:         fmt.Sprintf("%q", hex.EncodeToString(hash[:])),
+		lastModified: time.Now().UTC().Truncate(time.Second),
+	}
+
+	s.objects[key] = obj
+
+	return obj
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "IncompleteBody")
+
+		return
+	}
+
+	if !verify(r, body) {
+		writeError(w, http.StatusForbidden, "SignatureDoesNotMatch")
+
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != Bucket {
+		writeError(w, http.StatusNotFound, "NoSuchBucket")
+
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		s.list(w, r)
+	case r.Method == http.MethodGet, r.Method == http.MethodHead:
+		obj, ok := s.objects[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey")
+
+			return
+		}
+
+		writeHeader(w, obj)
+		w.Header().Set("Content-Length", strconv.Itoa(len(obj.body)))
+
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(obj.body))
+		}
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		source, _ := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+
+		obj, ok := s.objects[strings.TrimPrefix(source, "/"+Bucket+"/")]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey")
+
+			return
+		}
+
+		obj = s.put(key, obj.body)
+
+		_ = xml.NewEncoder(w).Encode(struct {
+			XMLName xml.Name `xml:"CopyObjectResult"`
+			ETag    string   `xml:"ETag"`
+		}{ETag: obj.etag})
+	case r.Method == http.MethodPut:
+		current, ok := s.objects[key]
+
+		ifMatch := r.Header.Get("If-Match")
+		if ifMatch != "" && (!ok || current.etag != ifMatch) {
+			writeError(w, http.StatusPreconditionFailed, "PreconditionFailed")
+
+			return
+		}
+
+		if r.Header.Get("If-None-Match") == "*" && ok {
+			writeError(w, http.StatusPreconditionFailed, "PreconditionFailed")
+
+			return
+		}
+
+		writeHeader(w, s.put(key, string(body)))
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+type listContent struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int    `xml:"Size"`
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("list-type") != "2" {
+		writeError(w, http.StatusBadRequest, "InvalidArgument")
+
+		return
+	}
+
+	prefix := query.Get("prefix")
+	token := query.Get("continuation-token")
+
+	keys := make([]string, 0, len(s.objects))
+
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) && key > token {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	result := struct {
+		XMLName               xml.Name      `xml:"ListBucketResult"`
+		Name                  string        `xml:"Name"`
+		Prefix                string        `xml:"Prefix"`
+		KeyCount              int           `xml:"KeyCount"`
+		IsTruncated           bool          `xml:"IsTruncated"`
+		Contents              []listContent `xml:"Contents"`
+		NextContinuationToken string        `xml:"NextContinuationToken,omitempty"`
+	}{
+		Name:   Bucket,
+		Prefix: prefix,
+	}
+
+	if len(keys) > s.PageSize {
+		keys = keys[:s.PageSize]
+		result.IsTruncated = true
+		result.NextContinuationToken = keys[len(keys)-1]
+	}
+
+	for _, key := range keys {
+		obj := s.objects[key]
+
+		result.Contents = append(result.Contents, listContent{
+			Key:          key,
+			LastModified: obj.lastModified.Format(time.RFC3339),
+			ETag:         obj.etag,
+			Size:         len(obj.body),
+		})
+	}
+
+	result.KeyCount = len(result.Contents)
+
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+func writeHeader(w http.ResponseWriter, obj object) {
+	w.Header().Set("ETag", obj.etag)
+	w.Header().Set("Last-Modified", obj.lastModified.Format(http.TimeFormat))
+}
+
+func writeError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string   `xml:"Code"`
+	}{Code: code})
+}
+
+var authorizationRegexp = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=([^/]+)/\d{8}/([^/]+)/s3/aws4_request, SignedHeaders=([^,]+), Signature=[0-9a-f]{64}$`)
+
+// verify signs copy of request with only signed headers and compares
+// authorization, payload hash must match body
+func verify(r *http.Request, body []byte) bool {
+	authorization := r.Header.Get("Authorization")
+
+	match := authorizationRegexp.FindStringSubmatch(authorization)
+	if match == nil || match[1] != AccessKey || match[2] != Region {
+		return false
+	}
+
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash != s3.PayloadHash(body) {
+		return false
+	}
+
+	date, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return false
+	}
+
+	signed := r.Clone(r.Context())
+	signed.Header = make(http.Header)
+
+	for _, name := range strings.Split(match[3], ";") {
+		if name == "host" {
+			continue
+		}
+
+		signed.Header[http.CanonicalHeaderKey(name)] = r.Header.Values(name)
+	}
+
+	s3.Signer{
+		AccessKey: AccessKey,
+		SecretKey: SecretKey,
+		Region:    Region,
+		Service:   "s3",
+	}.Sign(signed, payloadHash, date)
+
+	return signed.Header.Get("Authorization") == authorization
+}